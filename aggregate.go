@@ -0,0 +1,143 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+// aggregateFunctions are the functions supported by Aggregate.
+var aggregateFunctions = map[string]bool{
+	"sum": true,
+	"avg": true,
+	"min": true,
+	"max": true,
+}
+
+// Aggregate applies the aggregate function fn, i.e. one of sum, avg, min or max, to the column of the rows of the type
+// that match the clauses, and returns the result. The column can be given by field or column name, and must be numeric.
+// Like Count, soft deleted rows are left out unless the database is unscoped. If no row matches, 0 is returned.
+func (db *Database) Aggregate(t reflect.Type, fn string, column string, clauses string, args ...any) (float64, error) {
+	return db.AggregateCtx(context.Background(), t, fn, column, clauses, args...)
+}
+
+func (db *Database) AggregateCtx(ctx context.Context, t reflect.Type, fn string, column string, clauses string,
+	args ...any) (float64, error) {
+	return retryResult(ctx, db, false, func() (float64, error) {
+		return aggregate(ctx, db.readSession(), t, fn, column, clauses, args...)
+	})
+}
+
+// Sum returns the sum of the column over the rows matching the clauses, see Aggregate.
+func (db *Database) Sum(t reflect.Type, column string, clauses string, args ...any) (float64, error) {
+	return db.AggregateCtx(context.Background(), t, "sum", column, clauses, args...)
+}
+
+func (db *Database) SumCtx(ctx context.Context, t reflect.Type, column string, clauses string,
+	args ...any) (float64, error) {
+	return db.AggregateCtx(ctx, t, "sum", column, clauses, args...)
+}
+
+// Avg returns the average of the column over the rows matching the clauses, see Aggregate.
+func (db *Database) Avg(t reflect.Type, column string, clauses string, args ...any) (float64, error) {
+	return db.AggregateCtx(context.Background(), t, "avg", column, clauses, args...)
+}
+
+func (db *Database) AvgCtx(ctx context.Context, t reflect.Type, column string, clauses string,
+	args ...any) (float64, error) {
+	return db.AggregateCtx(ctx, t, "avg", column, clauses, args...)
+}
+
+// Min returns the minimum of the column over the rows matching the clauses, see Aggregate.
+func (db *Database) Min(t reflect.Type, column string, clauses string, args ...any) (float64, error) {
+	return db.AggregateCtx(context.Background(), t, "min", column, clauses, args...)
+}
+
+func (db *Database) MinCtx(ctx context.Context, t reflect.Type, column string, clauses string,
+	args ...any) (float64, error) {
+	return db.AggregateCtx(ctx, t, "min", column, clauses, args...)
+}
+
+// Max returns the maximum of the column over the rows matching the clauses, see Aggregate.
+func (db *Database) Max(t reflect.Type, column string, clauses string, args ...any) (float64, error) {
+	return db.AggregateCtx(context.Background(), t, "max", column, clauses, args...)
+}
+
+func (db *Database) MaxCtx(ctx context.Context, t reflect.Type, column string, clauses string,
+	args ...any) (float64, error) {
+	return db.AggregateCtx(ctx, t, "max", column, clauses, args...)
+}
+
+func (tx *Tx) Aggregate(t reflect.Type, fn string, column string, clauses string, args ...any) (float64, error) {
+	return tx.AggregateCtx(context.Background(), t, fn, column, clauses, args...)
+}
+
+func (tx *Tx) AggregateCtx(ctx context.Context, t reflect.Type, fn string, column string, clauses string,
+	args ...any) (float64, error) {
+	return aggregate(ctx, tx.session(), t, fn, column, clauses, args...)
+}
+
+func (tx *Tx) Sum(t reflect.Type, column string, clauses string, args ...any) (float64, error) {
+	return tx.AggregateCtx(context.Background(), t, "sum", column, clauses, args...)
+}
+
+func (tx *Tx) SumCtx(ctx context.Context, t reflect.Type, column string, clauses string,
+	args ...any) (float64, error) {
+	return tx.AggregateCtx(ctx, t, "sum", column, clauses, args...)
+}
+
+func (tx *Tx) Avg(t reflect.Type, column string, clauses string, args ...any) (float64, error) {
+	return tx.AggregateCtx(context.Background(), t, "avg", column, clauses, args...)
+}
+
+func (tx *Tx) AvgCtx(ctx context.Context, t reflect.Type, column string, clauses string,
+	args ...any) (float64, error) {
+	return tx.AggregateCtx(ctx, t, "avg", column, clauses, args...)
+}
+
+func (tx *Tx) Min(t reflect.Type, column string, clauses string, args ...any) (float64, error) {
+	return tx.AggregateCtx(context.Background(), t, "min", column, clauses, args...)
+}
+
+func (tx *Tx) MinCtx(ctx context.Context, t reflect.Type, column string, clauses string,
+	args ...any) (float64, error) {
+	return tx.AggregateCtx(ctx, t, "min", column, clauses, args...)
+}
+
+func (tx *Tx) Max(t reflect.Type, column string, clauses string, args ...any) (float64, error) {
+	return tx.AggregateCtx(context.Background(), t, "max", column, clauses, args...)
+}
+
+func (tx *Tx) MaxCtx(ctx context.Context, t reflect.Type, column string, clauses string,
+	args ...any) (float64, error) {
+	return tx.AggregateCtx(ctx, t, "max", column, clauses, args...)
+}
+
+func aggregate(ctx context.Context, s *session, t reflect.Type, fn string, column string, clauses string,
+	args ...any) (float64, error) {
+	errmsg := fmt.Sprintf("could not aggregate objects of type %s", t.Name())
+	clauses, args = expandClauses(clauses, args)
+
+	fn = strings.ToLower(fn)
+	if !aggregateFunctions[fn] {
+		return 0, errors.New(fmt.Sprintf("%s - unsupported aggregate function %s", errmsg, fn))
+	}
+
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	statement := buildAggregateStatement(t, fn, column, clauses, s.unscoped, tenantIdx, s.namingStrategy())
+
+	// aggregates over no rows are null
+	var result *float64
+	err := s.QueryRow(ctx, statement, args...).Scan(&result)
+	if err != nil {
+		return 0, errors.Wrap(err, errmsg)
+	}
+
+	if result == nil {
+		return 0, nil
+	}
+
+	return *result, nil
+}
@@ -0,0 +1,222 @@
+package liteorm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// AuditLog is a row of the audit_log table, which records the changes made by the inserts, upserts, updates and deletes
+// of a database with audit enabled, see WithAudit. The old and new values are the rows before and after the change, as
+// JSON objects keyed by column name, and are null for inserts and deletes respectively. An upsert is recorded as an
+// update if it updated an existing row.
+type AuditLog struct {
+	ID        int64
+	Table     string `pglen:"63"`
+	RowID     string `pglen:"64"`
+	Operation string `pglen:"10"`
+	OldValues map[string]any
+	NewValues map[string]any
+	Actor     string `pglen:"255"`
+	CreatedAt time.Time
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// The operations recorded in the audit log.
+const (
+	AuditInsert = "insert"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// auditActorKey is the context key of the actor of the audited operations.
+type auditActorKey struct{}
+
+// WithAuditActor returns a copy of the context that records the actor received as argument, e.g. the ID of the user
+// making the request, in the audit log rows written by the operations run with it.
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// auditActor returns the actor of the context, or an empty string if none was set with WithAuditActor.
+func auditActor(ctx context.Context) string {
+	actor, _ := ctx.Value(auditActorKey{}).(string)
+	return actor
+}
+
+// WithAudit returns a copy of the database that writes a row to the audit_log table for each row changed by Insert,
+// InsertMany, Save, Upsert, UpdateOne, UpdateColumns, UpdateMany, UpdateWhere and the deletes, within the same
+// transaction as the change. Operations of the database itself run within a transaction of their own, and those of a
+// transaction started from the copy within that transaction. CopyFrom cannot be audited, since copy does not return
+// the rows it inserts, and fails instead. The table must be created beforehand, e.g. with
+// CreateTable(reflect.TypeOf(AuditLog{}), false). Audit relies on the to_jsonb function of PostgreSQL.
+func (db *Database) WithAudit() *Database {
+	audited := *db
+	audited.audit = true
+	return &audited
+}
+
+// audited runs an operation of the database on a session of its own, within a transaction if audit is enabled so that
+// the audit log rows are only written along with the changes they record.
+func (db *Database) audited(ctx context.Context, operation func(s *session) error) error {
+	_, err := auditedResult(ctx, db, func(s *session) (struct{}, error) {
+		return struct{}{}, operation(s)
+	})
+	return err
+}
+
+// auditedResult runs an operation of the database returning a result, like Database.audited.
+func auditedResult[T any](ctx context.Context, db *Database, operation func(s *session) (T, error)) (T, error) {
+	if !db.audit {
+		return operation(db.session())
+	}
+
+	var result T
+	err := db.RunInTransactionCtx(ctx, func(tx *Tx) error {
+		var err error
+		result, err = operation(tx.session())
+		return err
+	})
+
+	return result, err
+}
+
+// auditRow is a row as recorded in the audit log, i.e. its ID and its values by column name.
+type auditRow struct {
+	id     string
+	values map[string]any
+}
+
+// selectAuditRows returns the rows of the type matching the clauses, as recorded in the audit log.
+func (s *session) selectAuditRows(ctx context.Context, argt reflect.Type, clauses string, unscoped bool,
+	args ...any) ([]auditRow, error) {
+	tenantIdx, args := tenantArgs(argt, s.tenant, args)
+	statement := buildAuditRowsStatement(argt, clauses, unscoped, tenantIdx, s.namingStrategy())
+	rows, err := s.Query(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	auditRows := make([]auditRow, 0)
+	for rows.Next() {
+		var row auditRow
+		var data []byte
+		err = rows.Scan(&row.id, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal(data, &row.values)
+		if err != nil {
+			return nil, err
+		}
+
+		auditRows = append(auditRows, row)
+	}
+
+	return auditRows, rows.Err()
+}
+
+// selectAuditObject returns the row of the object received as argument, as recorded in the audit log.
+func (s *session) selectAuditObject(ctx context.Context, arg any) ([]auditRow, error) {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := getIDValue(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.selectAuditRows(ctx, argt, buildIDClause(argt, s.namingStrategy()), true, id)
+}
+
+// selectAuditRowsByID returns the rows of the type with the IDs of the rows received as argument, e.g. after they
+// were changed, as recorded in the audit log.
+func (s *session) selectAuditRowsByID(ctx context.Context, argt reflect.Type, auditRows []auditRow) ([]auditRow,
+	error) {
+	if len(auditRows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(auditRows))
+	for i, row := range auditRows {
+		ids[i] = row.id
+	}
+
+	return s.selectAuditRows(ctx, argt, buildAuditIDsClause(argt, s.namingStrategy()), true, ids)
+}
+
+// selectAuditConflictRows returns the row that an upsert of the values received as argument, in the order of
+// buildInsertColumnNames, conflicts with on the conflict columns, if any, as recorded in the audit log.
+func (s *session) selectAuditConflictRows(ctx context.Context, argt reflect.Type, conflictColumns []string,
+	values []any) ([]auditRow, error) {
+	columnIndices := make(map[string]int)
+	for i, columnName := range buildInsertColumnNames(argt, s.namingStrategy()) {
+		columnIndices[columnName] = i
+	}
+
+	conditions := make([]string, len(conflictColumns))
+	args := make([]any, len(conflictColumns))
+	for i, conflictColumn := range conflictColumns {
+		columnName := fieldColumnName(argt, conflictColumn, s.namingStrategy())
+		index, ok := columnIndices[columnName]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("conflict column %s is not inserted", conflictColumn))
+		}
+
+		conditions[i] = fmt.Sprintf("%s = $%d", quoteIdentifier(columnName), i+1)
+		args[i] = values[index]
+	}
+
+	return s.selectAuditRows(ctx, argt, "where "+strings.Join(conditions, " and "), true, args...)
+}
+
+// writeAuditLog writes an audit log row for each row changed by an operation, given the rows before the change, if
+// any, and the rows after the change, if any.
+func (s *session) writeAuditLog(ctx context.Context, argt reflect.Type, operation string, oldRows []auditRow,
+	newRows []auditRow) error {
+	// the rows of the audit log are not audited themselves
+	auditSession := &session{executor: s.executor, settings: s.settings}
+	auditSession.audit = false
+
+	oldValues := make(map[string]map[string]any)
+	for _, row := range oldRows {
+		oldValues[row.id] = row.values
+	}
+
+	rows := newRows
+	if len(rows) == 0 {
+		rows = oldRows
+	}
+
+	for _, row := range rows {
+		entry := &AuditLog{
+			Table:     s.namingStrategy().TableName(argt),
+			RowID:     row.id,
+			Operation: operation,
+			OldValues: oldValues[row.id],
+			Actor:     auditActor(ctx),
+		}
+
+		if len(newRows) > 0 {
+			entry.NewValues = row.values
+		}
+
+		err := insert(ctx, auditSession, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
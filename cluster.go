@@ -0,0 +1,69 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"sync/atomic"
+)
+
+// replicaSet holds the connections to the read replicas of a database, which are used in turn.
+type replicaSet struct {
+	conns []*pgx.Conn
+	next  uint32
+}
+
+// conn returns the replica connection to run the next read on.
+func (r *replicaSet) conn() *pgx.Conn {
+	i := atomic.AddUint32(&r.next, 1) - 1
+	return r.conns[i%uint32(len(r.conns))]
+}
+
+// NewDatabaseCluster connects to a primary database and its read replicas. Selects, counts and existence checks run on
+// the replicas in round-robin order, and all other statements on the primary. Transactions run entirely on the primary,
+// and reads that must see the latest writes can be pinned to it with Primary. The database is configured with the
+// options, which apply to the replicas as well.
+func NewDatabaseCluster(primaryConnString string, replicaConnStrings []string, options ...Option) (*Database, error) {
+	db, err := NewDatabase(primaryConnString)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(replicaConnStrings) == 0 {
+		return applyOptions(db, options), nil
+	}
+
+	replicas := &replicaSet{}
+	for _, connString := range replicaConnStrings {
+		conn, err := pgx.Connect(context.Background(), connString)
+		if err != nil {
+			db.Close()
+			for _, replica := range replicas.conns {
+				replica.Close(context.Background())
+			}
+			return nil, errors.Wrap(err, "could not connect to replica")
+		}
+
+		replicas.conns = append(replicas.conns, conn)
+	}
+
+	// the options are applied once the replicas are set, since they return configured copies of the database
+	db.replicas = replicas
+	return applyOptions(db, options), nil
+}
+
+// Primary returns a copy of the database whose reads run on the primary rather than on the replicas.
+func (db *Database) Primary() *Database {
+	primary := *db
+	primary.replicas = nil
+	return &primary
+}
+
+// readSession returns the session of a read, which runs on one of the replicas if the database has any.
+func (db *Database) readSession() *session {
+	if db.replicas == nil {
+		return db.session()
+	}
+
+	return &session{executor: db.wrapExecutor(db.replicas.conn()), settings: db.settings}
+}
@@ -0,0 +1,59 @@
+// Command liteorm-gen generates Go model structs from the tables of an existing database, with the tags that map them
+// to liteorm:
+//
+//	liteorm-gen -dsn "host=localhost database=app" -package models -out models/models.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/lashbits/liteorm"
+	"os"
+	"strings"
+)
+
+func main() {
+	dsn := flag.String("dsn", "", "connection string of the database")
+	packageName := flag.String("package", "models", "name of the generated package")
+	out := flag.String("out", "", "file to write the generated source to, standard output if not set")
+	schema := flag.String("schema", "", "schema of the tables, public if not set")
+	tables := flag.String("tables", "", "comma separated tables to generate structs for, all tables if not set")
+	flag.Parse()
+
+	err := run(*dsn, *packageName, *out, *schema, *tables)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(dsn, packageName, out, schema, tables string) error {
+	db, err := liteorm.NewDatabase(dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if schema != "" {
+		db = db.WithSchema(schema)
+	}
+
+	tableNames := make([]string, 0)
+	for _, tableName := range strings.Split(tables, ",") {
+		if tableName = strings.TrimSpace(tableName); tableName != "" {
+			tableNames = append(tableNames, tableName)
+		}
+	}
+
+	source, err := db.GenerateModels(packageName, tableNames...)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+
+	return os.WriteFile(out, source, 0644)
+}
@@ -0,0 +1,89 @@
+package liteorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition is a where condition built from column names and values rather than a raw string. Like the conditions of
+// Query.Where, it uses ? placeholders, which are numbered when the condition is built, so conditions composed from
+// different places never clash on their parameter indices:
+//
+//	cond := liteorm.And(
+//		liteorm.Where("status", "=", "active"),
+//		liteorm.Or(liteorm.Where("age", ">=", 18), liteorm.In("role", []string{"admin", "owner"})),
+//	)
+//	result, err := db.SelectWhere(t, cond)
+type Condition struct {
+	condition string
+	args      []any
+}
+
+// Where builds a condition comparing a column with a value using the operator received as argument, e.g. "=" or "<".
+func Where(column, operator string, value any) Condition {
+	return Condition{
+		condition: fmt.Sprintf("%s %s ?", quoteIdentifier(column), operator),
+		args:      []any{value},
+	}
+}
+
+// IsNull builds a condition matching the rows where the column is null.
+func IsNull(column string) Condition {
+	return Condition{condition: fmt.Sprintf("%s is null", quoteIdentifier(column))}
+}
+
+// In builds a condition matching the rows where the column holds any of the values, which must be a slice. An empty
+// slice matches no rows.
+func In(column string, values any) Condition {
+	return Condition{
+		condition: fmt.Sprintf("%s = any(?)", quoteIdentifier(column)),
+		args:      []any{values},
+	}
+}
+
+// And combines conditions so that all of them must hold. Without conditions, it matches all rows.
+func And(conditions ...Condition) Condition {
+	if len(conditions) == 0 {
+		return Condition{condition: "true"}
+	}
+
+	return combineConditions("and", conditions)
+}
+
+// Or combines conditions so that at least one of them must hold. Without conditions, it matches no rows.
+func Or(conditions ...Condition) Condition {
+	if len(conditions) == 0 {
+		return Condition{condition: "false"}
+	}
+
+	return combineConditions("or", conditions)
+}
+
+// Not negates a condition.
+func Not(condition Condition) Condition {
+	return Condition{condition: fmt.Sprintf("not (%s)", condition.condition), args: condition.args}
+}
+
+func combineConditions(operator string, conditions []Condition) Condition {
+	parts := make([]string, len(conditions))
+	args := make([]any, 0)
+	for i, condition := range conditions {
+		parts[i] = fmt.Sprintf("(%s)", condition.condition)
+		args = append(args, condition.args...)
+	}
+
+	return Condition{condition: strings.Join(parts, fmt.Sprintf(" %s ", operator)), args: args}
+}
+
+// Build returns the where clause of the condition, with placeholders numbered from $1, and the arguments matching them.
+func (c Condition) Build() (string, []any) {
+	return NewQuery().Filter(c).Build()
+}
+
+// Filter adds structured conditions to the query. Like the conditions of Where, they are combined with "and".
+func (q *Query) Filter(conditions ...Condition) *Query {
+	for _, condition := range conditions {
+		q.Where(condition.condition, condition.args...)
+	}
+	return q
+}
@@ -0,0 +1,32 @@
+package liteorm
+
+import (
+	"testing"
+)
+
+func TestConditionBuild(t *testing.T) {
+	condition := And(
+		Where("status", "=", "active"),
+		Or(Where("age", ">=", 18), In("role", []string{"admin", "owner"})),
+		Not(IsNull("email")),
+	)
+
+	clauses, args := condition.Build()
+	expected := `where (("status" = $1) and (("age" >= $2) or ("role" = any($3))) and (not ("email" is null)))`
+	if clauses != expected {
+		t.Errorf("incorrect clauses built - %s", clauses)
+	}
+
+	if len(args) != 3 || args[0] != "active" || args[1] != 18 {
+		t.Errorf("incorrect arguments - %v", args)
+	}
+
+	clauses, args = NewQuery().Where("id > ?", 10).Filter(Where("name", "<>", "lashbits.tech")).Build()
+	if clauses != `where (id > $1) and ("name" <> $2)` || len(args) != 2 {
+		t.Errorf("conditions not combined with the raw conditions of the query - %s", clauses)
+	}
+
+	if clauses, _ := And().Build(); clauses != "where (true)" {
+		t.Errorf("incorrect clauses for an empty and - %s", clauses)
+	}
+}
@@ -3,60 +3,441 @@ package liteorm
 import (
 	"context"
 	"fmt"
-	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v5"
 	"github.com/pkg/errors"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 )
 
 type Database struct {
 	Conn *pgx.Conn
+	settings
+
+	// conn runs the statements of the database instead of Conn, if set
+	conn Executor
+
+	// replicas run the reads of the database, if set
+	replicas *replicaSet
+
+	// reconnector replaces Conn once it is closed, if set
+	reconnector *reconnector
+}
+
+// settings affect how the statements of a Database or Tx are built and run. A transaction inherits the settings of the
+// database it was started from.
+type settings struct {
+	// unscoped disables the soft delete behaviour of types with a DeletedAt field
+	unscoped bool
+
+	// naming derives table and column names from types and fields, DefaultNaming if not set
+	naming NamingStrategy
+
+	// schema qualifies the table names, if set
+	schema string
+
+	// logger receives the statements run, if set
+	logger Logger
+
+	// tracer starts a span around each operation, if set
+	tracer Tracer
+
+	// metrics observes each operation, if set
+	metrics Metrics
+
+	// retryPolicy retries the operations failing with transient errors, if set
+	retryPolicy *RetryPolicy
+
+	// dialect adapts the statements to the database engine, PostgreSQL if not set
+	dialect Dialect
+
+	// keyProvider provides the keys of the encrypted fields, if set
+	keyProvider KeyProvider
+
+	// databaseTimestamps sets the timestamp fields with the clock of the database rather than that of the client
+	databaseTimestamps bool
+
+	// audit writes the changes made by the operations to the audit log
+	audit bool
+
+	// tenant restricts the operations on tenant types to the rows of a tenant, if set
+	tenant *tenantScope
+
+	// timeout is the deadline of each statement, if set
+	timeout time.Duration
+
+	// statementTimeout is the statement_timeout of the transactions, if set
+	statementTimeout time.Duration
+}
+
+// namingStrategy returns the naming strategy of the settings, which defaults to DefaultNaming. If a schema is set, the
+// table names are qualified with it.
+func (s settings) namingStrategy() NamingStrategy {
+	naming := s.unqualifiedNamingStrategy()
+	if s.schema != "" {
+		naming = schemaNaming{NamingStrategy: naming, schema: s.schema}
+	}
+
+	return naming
+}
+
+// unqualifiedNamingStrategy returns the naming strategy of the settings without the schema, for the statements that
+// refer to the schema and table names separately.
+func (s settings) unqualifiedNamingStrategy() NamingStrategy {
+	if s.naming == nil {
+		return DefaultNaming{}
+	}
+
+	return s.naming
+}
+
+// schemaName returns the schema of the tables, which defaults to public.
+func (s settings) schemaName() string {
+	if s.schema == "" {
+		return "public"
+	}
+
+	return s.schema
+}
+
+// sqlDialect returns the dialect of the settings, which defaults to PostgreSQL.
+func (s settings) sqlDialect() Dialect {
+	if s.dialect == nil {
+		return PostgreSQL{}
+	}
+
+	return s.dialect
+}
+
+// wrapExecutor wraps the executor received as argument according to the settings, e.g. to log its statements.
+func (s settings) wrapExecutor(e executor) executor {
+	// the errors are translated first, so that the logged and traced errors are the ones returned
+	e = &errorTranslatingExecutor{executor: e}
+	e = &timeoutExecutor{executor: e, timeout: s.timeout}
+
+	if s.logger != nil {
+		e = &loggingExecutor{executor: e, logger: s.logger}
+	}
+
+	if s.tracer != nil {
+		e = &tracingExecutor{executor: e}
+	}
+
+	// the placeholders are rewritten last, so that the logged statements are the ones actually run
+	if _, ok := s.sqlDialect().(PostgreSQL); !ok {
+		e = &dialectExecutor{executor: e, dialect: s.dialect}
+	}
+
+	return e
+}
+
+// session is the executor of a Database or Tx together with its settings, and is passed to all operations.
+type session struct {
+	executor
+	settings
+}
+
+func (db *Database) session() *session {
+	return &session{executor: db.wrapExecutor(fullExecutor(db.connection())), settings: db.settings}
+}
+
+// connection returns the executor the statements of the database run on, i.e. Conn, or the connection that replaced it
+// if it was lost, unless the database was created with NewDatabaseFromExecutor.
+func (db *Database) connection() Executor {
+	if db.conn != nil {
+		return db.conn
+	}
+
+	if db.reconnector != nil {
+		return db.reconnector.connection()
+	}
+
+	return db.Conn
 }
 
-func NewDatabase(connString string) (*Database, error) {
+// NewDatabase connects to the database of the connection string received as argument, either in the key/value format
+// or as a postgres:// URL, and configures it with the options. To share a pool of connections between goroutines, use
+// NewDatabaseFromExecutor with a *pgxpool.Pool instead.
+func NewDatabase(connString string, options ...Option) (*Database, error) {
 	conn, err := pgx.Connect(context.Background(), connString)
 	if err != nil {
 		return nil, err
 	}
 
 	db := &Database{
-		Conn: conn,
+		Conn:        conn,
+		reconnector: &reconnector{conn: conn},
 	}
 
-	return db, nil
+	return applyOptions(db, options), nil
+}
+
+// NewDatabaseFromExecutor returns a database whose statements run on the executor received as argument, e.g. a
+// *pgxpool.Pool shared by several goroutines, or a mock such as pgxmock in unit tests. Transactions can only be started
+// if the executor also implements Begin, and the executor is not closed by Close.
+func NewDatabaseFromExecutor(e Executor, options ...Option) *Database {
+	return applyOptions(&Database{conn: e}, options)
 }
 
 func (db *Database) Close() {
-	db.Conn.Close(context.Background())
+	if db.reconnector != nil {
+		db.reconnector.close()
+	} else if db.Conn != nil {
+		db.Conn.Close(context.Background())
+	}
+	if db.replicas != nil {
+		for _, replica := range db.replicas.conns {
+			replica.Close(context.Background())
+		}
+	}
 }
 
 func (db *Database) CreateTable(t reflect.Type, dropExisting bool) error {
-	tableName := BuildTableName(t)
+	return db.CreateTableCtx(context.Background(), t, dropExisting)
+}
+
+func (db *Database) CreateTableCtx(ctx context.Context, t reflect.Type, dropExisting bool) (err error) {
+	tableName := db.namingStrategy().TableName(t)
 	errmsg := fmt.Sprintf("could not create table %s", tableName)
 
+	ctx, span := db.startSpan(ctx, "create_table", tableName)
+	defer func() { span.End(err) }()
+
 	if dropExisting {
-		statement := fmt.Sprintf("drop table if exists %s cascade;", tableName)
-		_, err := db.Conn.Exec(context.Background(), statement)
+		statement := buildDropTableStatement(t, true, true, db.namingStrategy())
+		_, err = db.session().Exec(ctx, statement)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	if query, ok := getViewQuery(t); ok {
+		_, err = db.session().Exec(ctx, buildCreateViewStatement(t, query, db.namingStrategy()))
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+		return nil
+	}
+
+	if query, ok := getMaterializedViewQuery(t); ok {
+		_, err = db.session().Exec(ctx, buildCreateMaterializedViewStatement(t, query, db.namingStrategy()))
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+		return nil
+	}
+
+	err = db.createEnums(ctx, t)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	statement, err := buildCreateStatement(t, db.namingStrategy(), db.sqlDialect())
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	_, err = db.session().Exec(ctx, statement)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	// full-text search columns are only useful with their index, so it is created along with the table
+	if statement, ok := buildCreateSearchIndexStatement(t, db.namingStrategy()); ok {
+		_, err = db.session().Exec(ctx, statement)
 		if err != nil {
 			return errors.Wrap(err, errmsg)
 		}
 	}
 
-	statement, err := buildCreateStatement(t)
+	err = db.createJoinTables(ctx, t, dropExisting)
 	if err != nil {
 		return errors.Wrap(err, errmsg)
 	}
 
-	_, err = db.Conn.Exec(context.Background(), statement)
+	return nil
+}
+
+// createEnums creates the enum types of the fields of the type that do not exist yet. Other dialects than PostgreSQL
+// store enum values as text, so there is nothing to create for them.
+func (db *Database) createEnums(ctx context.Context, t reflect.Type) error {
+	if _, ok := db.sqlDialect().(PostgreSQL); !ok {
+		return nil
+	}
+
+	for _, statement := range buildCreateEnumStatements(t) {
+		_, err := db.session().Exec(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateTables creates the tables of multiple types. The tables referenced by the foreign keys declared with the "fk"
+// tag are created before the tables referencing them, regardless of the order of the types.
+func (db *Database) CreateTables(types []reflect.Type, dropExisting bool) error {
+	return db.CreateTablesCtx(context.Background(), types, dropExisting)
+}
+
+func (db *Database) CreateTablesCtx(ctx context.Context, types []reflect.Type, dropExisting bool) error {
+	sorted, err := sortByReferences(types, db.namingStrategy())
+	if err != nil {
+		return errors.Wrap(err, "could not create tables")
+	}
+
+	for _, t := range sorted {
+		err = db.CreateTableCtx(ctx, t, dropExisting)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateIndexes creates the indexes declared with the "index" and "uniqueIndex" tags of the fields of the type, e.g.
+// `index:"idx_name"`. Fields sharing an index name are combined into a multi-column index. The GIN index of the
+// full-text search column is created as well, if the type has one. Existing indexes are left unchanged.
+func (db *Database) CreateIndexes(t reflect.Type) error {
+	return db.CreateIndexesCtx(context.Background(), t)
+}
+
+func (db *Database) CreateIndexesCtx(ctx context.Context, t reflect.Type) error {
+	errmsg := fmt.Sprintf("could not create indexes of table %s", db.namingStrategy().TableName(t))
+
+	statements, err := buildCreateIndexStatements(t, db.namingStrategy())
 	if err != nil {
 		return errors.Wrap(err, errmsg)
 	}
 
+	for _, statement := range statements {
+		_, err = db.session().Exec(ctx, statement)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	return nil
+}
+
+// DropTable drops the table of the type. If ifExists is set, a missing table is not an error.
+func (db *Database) DropTable(t reflect.Type, ifExists bool) error {
+	return db.DropTableCtx(context.Background(), t, ifExists)
+}
+
+func (db *Database) DropTableCtx(ctx context.Context, t reflect.Type, ifExists bool) error {
+	return dropTable(ctx, db.session(), t, ifExists)
+}
+
+func dropTable(ctx context.Context, s *session, t reflect.Type, ifExists bool) error {
+	statement := buildDropTableStatement(t, ifExists, false, s.namingStrategy())
+	_, err := s.Exec(ctx, statement)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not drop table %s", s.namingStrategy().TableName(t)))
+	}
+
+	return nil
+}
+
+// Truncate removes all rows of the table of the type, including soft deleted ones. If restartIdentity is set, the
+// sequences of the identity columns, such as serial IDs, are reset, and if cascade is set, the tables with foreign keys
+// to the table are truncated as well.
+func (db *Database) Truncate(t reflect.Type, restartIdentity bool, cascade bool) error {
+	return db.TruncateCtx(context.Background(), t, restartIdentity, cascade)
+}
+
+func (db *Database) TruncateCtx(ctx context.Context, t reflect.Type, restartIdentity bool, cascade bool) error {
+	return truncate(ctx, db.session(), t, restartIdentity, cascade)
+}
+
+func truncate(ctx context.Context, s *session, t reflect.Type, restartIdentity bool, cascade bool) error {
+	statement := buildTruncateStatement(t, restartIdentity, cascade, s.namingStrategy())
+	_, err := s.Exec(ctx, statement)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not truncate table %s", s.namingStrategy().TableName(t)))
+	}
+
 	return nil
 }
 
+// Unscoped returns a copy of the database whose operations bypass soft delete: selects include deleted rows, and
+// deletes remove the rows instead of setting their DeletedAt column.
+func (db *Database) Unscoped() *Database {
+	unscoped := *db
+	unscoped.unscoped = true
+	return &unscoped
+}
+
+// WithNaming returns a copy of the database that derives table and column names with the naming strategy received as
+// argument, e.g. DefaultNaming{ColumnNaming: LowerCase} for tables created by earlier versions of liteorm.
+func (db *Database) WithNaming(naming NamingStrategy) *Database {
+	named := *db
+	named.naming = naming
+	return &named
+}
+
+// WithTablePrefix returns a copy of the database whose generated table names are preceded by the prefix received as
+// argument, e.g. "app_". Like the prefix of DefaultNaming, it does not apply to the names set with TableNamer.
+func (db *Database) WithTablePrefix(prefix string) *Database {
+	prefixed := *db
+	if naming, ok := db.unqualifiedNamingStrategy().(DefaultNaming); ok {
+		naming.TablePrefix = prefix
+		prefixed.naming = naming
+	} else {
+		prefixed.naming = prefixNaming{NamingStrategy: db.naming, prefix: prefix}
+	}
+	return &prefixed
+}
+
+// WithSchema returns a copy of the database whose statements qualify table names with the schema received as argument,
+// e.g. to run one schema per tenant. Raw statements, as run by Query, are left as is.
+func (db *Database) WithSchema(schema string) *Database {
+	scoped := *db
+	scoped.schema = schema
+	return &scoped
+}
+
+func (db *Database) Begin() (*Tx, error) {
+	return db.BeginCtx(context.Background())
+}
+
+func (db *Database) BeginCtx(ctx context.Context) (*Tx, error) {
+	errmsg := "could not begin transaction"
+
+	beginner, ok := db.connection().(interface {
+		Begin(ctx context.Context) (pgx.Tx, error)
+	})
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("%s - executor does not support transactions", errmsg))
+	}
+
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	err = setStatementTimeout(ctx, tx, db.statementTimeout)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	// the statements of a transaction are not retried, since a failed statement aborts the transaction
+	txSettings := db.settings
+	txSettings.retryPolicy = nil
+	return &Tx{Tx: tx, settings: txSettings}, nil
+}
+
 func (db *Database) TableExists(t reflect.Type) (bool, error) {
-	statement := buildTableExistsStatement(t, "public")
-	row := db.Conn.QueryRow(context.Background(), statement)
+	return db.TableExistsCtx(context.Background(), t)
+}
+
+func (db *Database) TableExistsCtx(ctx context.Context, t reflect.Type) (bool, error) {
+	statement := db.sqlDialect().TableExistsStatement(db.schemaName(), db.unqualifiedNamingStrategy().TableName(t))
+	row := db.session().QueryRow(ctx, statement)
 
 	var exists bool
 	if err := row.Scan(&exists); err != nil {
@@ -66,27 +447,82 @@ func (db *Database) TableExists(t reflect.Type) (bool, error) {
 	return exists, nil
 }
 
+// Insert inserts the object received as argument and sets its fields to the values of the inserted row, including the
+// ID and the fields generated by the database.
 func (db *Database) Insert(arg any) error {
-	var lastID int64
+	return db.InsertCtx(context.Background(), arg)
+}
+
+func (db *Database) InsertCtx(ctx context.Context, arg any) error {
+	return db.audited(ctx, func(s *session) error {
+		return insert(ctx, s, arg)
+	})
+}
 
+func insert(ctx context.Context, s *session, arg any) (err error) {
 	argt, err := getObjectType(arg)
 	if err != nil {
 		return errors.Wrap(err, "could not insert object")
 	}
 	errmsg := fmt.Sprintf("could not insert object of type %s", argt.Name())
 
-	statement := buildInsertStatement(argt)
+	err = checkWritable(argt)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	ctx, span := s.startSpan(ctx, "insert", s.namingStrategy().TableName(argt))
+	defer func() { span.End(err) }()
+
+	err = beforeInsert(ctx, arg)
+	if err == nil {
+		err = s.setTenant(arg)
+	}
+	if err == nil {
+		err = setGeneratedID(arg)
+	}
+	if err == nil {
+		err = s.setTimestamps(arg, true)
+	}
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	statement := s.cachedInsertStatement(argt)
 	values, err := buildStatementValues(arg)
+	if err == nil {
+		err = s.encryptValues(values)
+	}
 	if err != nil {
 		return errors.Wrap(err, "could not insert object")
 	}
 
-	err = db.Conn.QueryRow(context.Background(), statement, values...).Scan(&lastID)
+	// the returned row holds the values generated by the database, which are set on the object
+	columnValues := buildSliceFromFields(argt)
+	err = s.QueryRow(ctx, statement, values...).Scan(columnValues...)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	err = s.decryptValues(columnValues)
+	if err == nil {
+		err = setObjectFields(arg, columnValues...)
+	}
 	if err != nil {
 		return errors.Wrap(err, errmsg)
 	}
 
-	setIDValue(arg, lastID)
+	if s.audit {
+		newRows, err := s.selectAuditObject(ctx, arg)
+		if err == nil {
+			err = s.writeAuditLog(ctx, argt, AuditInsert, nil, newRows)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	err = afterInsert(ctx, arg)
 	if err != nil {
 		return errors.Wrap(err, errmsg)
 	}
@@ -94,103 +530,1171 @@ func (db *Database) Insert(arg any) error {
 	return nil
 }
 
-func (db *Database) SelectOne(arg any, clauses string, args ...any) error {
+func (db *Database) Upsert(arg any, conflictColumns ...string) error {
+	return db.UpsertCtx(context.Background(), arg, conflictColumns...)
+}
+
+func (db *Database) UpsertCtx(ctx context.Context, arg any, conflictColumns ...string) error {
+	return db.retry(ctx, true, func() error {
+		return db.audited(ctx, func(s *session) error {
+			return upsert(ctx, s, arg, conflictColumns...)
+		})
+	})
+}
+
+// upsert inserts the object received as argument or, if the insert conflicts with an existing row on the conflict
+// columns, updates the remaining columns of that row. In both cases the fields are set to the values of the row.
+func upsert(ctx context.Context, s *session, arg any, conflictColumns ...string) error {
 	argt, err := getObjectType(arg)
 	if err != nil {
-		return errors.Wrap(err, "could not select object")
+		return errors.Wrap(err, "could not upsert object")
 	}
+	errmsg := fmt.Sprintf("could not upsert object of type %s", argt.Name())
 
-	errmsg := fmt.Sprintf("could not select object of type %s", argt.Name())
+	err = checkWritable(argt)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
 
-	statement := buildSelectStatement(argt, clauses)
-	row := db.Conn.QueryRow(context.Background(), statement, args...)
+	if len(conflictColumns) == 0 {
+		return errors.New(fmt.Sprintf("%s - no conflict columns provided", errmsg))
+	}
 
-	columnValues := buildSliceFromFields(argt)
-	err = row.Scan(columnValues...)
+	err = beforeInsert(ctx, arg)
+	if err == nil {
+		err = s.setTenant(arg)
+	}
+	if err == nil {
+		err = setGeneratedID(arg)
+	}
+	if err == nil {
+		err = s.setTimestamps(arg, true)
+	}
 	if err != nil {
 		return errors.Wrap(err, errmsg)
 	}
 
-	err = setObjectFields(arg, columnValues...)
+	values, err := buildStatementValues(arg)
+	if err == nil {
+		err = s.encryptValues(values)
+	}
 	if err != nil {
 		return errors.Wrap(err, errmsg)
 	}
 
-	return nil
-}
+	// the row the upsert conflicts with, if any, is recorded as the old row of an update
+	var oldRows []auditRow
+	if s.audit {
+		oldRows, err = s.selectAuditConflictRows(ctx, argt, conflictColumns, values)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
 
-func (db *Database) Select(t reflect.Type, clauses string, args ...any) (any, error) {
-	errmsg := fmt.Sprintf("could not select objects of type %s", t.Name())
+	tenantIdx, args := tenantArgs(argt, s.tenant, values)
+	statement := buildUpsertStatement(argt, conflictColumns, tenantIdx, s.namingStrategy(), s.sqlDialect())
 
-	statement := buildSelectStatement(t, clauses)
-	rows, err := db.Conn.Query(context.Background(), statement, args...)
-	defer rows.Close()
+	// the returned row holds the values generated by the database, which are set on the object
+	columnValues := buildSliceFromFields(argt)
+	err = s.QueryRow(ctx, statement, args...).Scan(columnValues...)
 	if err != nil {
-		return nil, errors.Wrap(err, errmsg)
+		return errors.Wrap(err, errmsg)
 	}
 
-	result := reflect.MakeSlice(reflect.SliceOf(t), 0, 0)
-	for rows.Next() {
-		columnValues := buildSliceFromFields(t)
-		err = rows.Scan(columnValues...)
-		if err != nil {
-			return nil, errors.Wrap(err, errmsg)
-		}
+	err = s.decryptValues(columnValues)
+	if err == nil {
+		err = setObjectFields(arg, columnValues...)
+	}
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
 
-		newelem := reflect.New(t).Interface()
+	if s.audit {
+		operation := AuditInsert
+		if len(oldRows) > 0 {
+			operation = AuditUpdate
+		}
 
-		err = setObjectFields(newelem, columnValues...)
+		newRows, err := s.selectAuditObject(ctx, arg)
+		if err == nil {
+			err = s.writeAuditLog(ctx, argt, operation, oldRows, newRows)
+		}
 		if err != nil {
-			return nil, errors.Wrap(err, errmsg)
+			return errors.Wrap(err, errmsg)
 		}
+	}
 
-		result = reflect.Append(result, reflect.ValueOf(newelem).Elem())
+	err = afterInsert(ctx, arg)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
 	}
 
-	return result.Interface(), nil
+	return nil
 }
 
-func (db *Database) UpdateOne(arg any) error {
-	argt, err := getObjectType(arg)
+func (db *Database) InsertMany(args any) error {
+	return db.InsertManyCtx(context.Background(), args)
+}
+
+func (db *Database) InsertManyCtx(ctx context.Context, args any) error {
+	return db.audited(ctx, func(s *session) error {
+		return insertMany(ctx, s, args)
+	})
+}
+
+func insertMany(ctx context.Context, s *session, args any) error {
+	objects, err := getSliceObjects(args)
 	if err != nil {
-		return errors.Wrap(err, "could not update object")
+		return errors.Wrap(err, "could not insert objects")
 	}
 
-	errmsg := fmt.Sprintf("could not update object of type %s", argt.Name())
+	if len(objects) == 0 {
+		return nil
+	}
 
-	statement, _ := buildUpdateStatement(argt, "where id = $1", 2)
-	values, err := buildStatementValues(arg)
+	argt, err := getObjectType(objects[0])
 	if err != nil {
-		return errors.Wrap(err, "could not update object")
+		return errors.Wrap(err, "could not insert objects")
 	}
+	errmsg := fmt.Sprintf("could not insert objects of type %s", argt.Name())
 
-	id, err := getIDValue(arg)
+	err = checkWritable(argt)
 	if err != nil {
 		return errors.Wrap(err, errmsg)
 	}
 
-	values = append([]any{id}, values...)
-
-	commandTag, err := db.Conn.Exec(context.Background(), statement, values...)
-	if err != nil {
-		return errors.Wrap(err, "could not update object")
+	statement := s.cachedInsertStatement(argt)
+	batch := &pgx.Batch{}
+	for _, object := range objects {
+		err = beforeInsert(ctx, object)
+		if err == nil {
+			err = s.setTenant(object)
+		}
+		if err == nil {
+			err = setGeneratedID(object)
+		}
+		if err == nil {
+			err = s.setTimestamps(object, true)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		values, err := buildStatementValues(object)
+		if err == nil {
+			err = s.encryptValues(values)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		batch.Queue(statement, values...)
+	}
+
+	results := s.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for _, object := range objects {
+		columnValues := buildSliceFromFields(argt)
+		err = results.QueryRow().Scan(columnValues...)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		err = s.decryptValues(columnValues)
+		if err == nil {
+			err = setObjectFields(object, columnValues...)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	err = results.Close()
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	if s.audit {
+		for _, object := range objects {
+			newRows, err := s.selectAuditObject(ctx, object)
+			if err == nil {
+				err = s.writeAuditLog(ctx, argt, AuditInsert, nil, newRows)
+			}
+			if err != nil {
+				return errors.Wrap(err, errmsg)
+			}
+		}
+	}
+
+	for _, object := range objects {
+		err = afterInsert(ctx, object)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	return nil
+}
+
+// CopyFrom bulk loads the objects of the slice received as argument with the copy protocol of PostgreSQL, and returns
+// the number of copied rows. Since copy does not return the rows it inserts, it cannot be audited, and it fails on a
+// database with audit enabled, see WithAudit; use InsertMany instead.
+func (db *Database) CopyFrom(args any) (int64, error) {
+	return db.CopyFromCtx(context.Background(), args)
+}
+
+func (db *Database) CopyFromCtx(ctx context.Context, args any) (int64, error) {
+	return copyFrom(ctx, db.session(), args)
+}
+
+// copyFrom bulk loads the objects received as argument using the PostgreSQL copy protocol. Unlike insertMany, the ID
+// fields of the objects are not populated, since copy does not return the generated ids.
+func copyFrom(ctx context.Context, s *session, args any) (int64, error) {
+	objects, err := getSliceObjects(args)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not copy objects")
+	}
+
+	if len(objects) == 0 {
+		return 0, nil
+	}
+
+	argt, err := getObjectType(objects[0])
+	if err != nil {
+		return 0, errors.Wrap(err, "could not copy objects")
+	}
+	errmsg := fmt.Sprintf("could not copy objects of type %s", argt.Name())
+
+	err = checkWritable(argt)
+	if err != nil {
+		return 0, errors.Wrap(err, errmsg)
+	}
+
+	if s.audit {
+		return 0, errors.New(fmt.Sprintf("%s - copy cannot be audited, use InsertMany instead", errmsg))
+	}
+
+	rows := make([][]any, len(objects))
+	for i, object := range objects {
+		// copy does not go through the insert statement, so the timestamps are always set by the client
+		err = beforeInsert(ctx, object)
+		if err == nil {
+			err = s.setTenant(object)
+		}
+		if err == nil {
+			err = setGeneratedID(object)
+		}
+		if err == nil {
+			err = setClientTimestamps(object, true)
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, errmsg)
+		}
+
+		rows[i], err = buildStatementValues(object)
+		if err == nil {
+			err = s.encryptValues(rows[i])
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, errmsg)
+		}
+	}
+
+	tableName := pgx.Identifier(strings.Split(s.namingStrategy().TableName(argt), "."))
+	count, err := s.CopyFrom(ctx, tableName, buildInsertColumnNames(argt, s.namingStrategy()), pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, errors.Wrap(err, errmsg)
+	}
+
+	return count, nil
+}
+
+func (db *Database) SelectOne(arg any, clauses string, args ...any) error {
+	return db.SelectOneCtx(context.Background(), arg, clauses, args...)
+}
+
+func (db *Database) SelectOneCtx(ctx context.Context, arg any, clauses string, args ...any) error {
+	return db.retry(ctx, false, func() error {
+		return selectOne(ctx, db.readSession(), arg, clauses, args...)
+	})
+}
+
+func selectOne(ctx context.Context, s *session, arg any, clauses string, args ...any) (err error) {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not select object")
+	}
+
+	errmsg := fmt.Sprintf("could not select object of type %s", argt.Name())
+	clauses, args = expandClauses(clauses, args)
+
+	ctx, span := s.startSpan(ctx, "select_one", s.namingStrategy().TableName(argt))
+	defer func() { span.End(err) }()
+
+	tenantIdx, args := tenantArgs(argt, s.tenant, args)
+	statement := s.cachedSelectStatement(argt, clauses, tenantIdx)
+	row := s.QueryRow(ctx, statement, args...)
+
+	columnValues := buildSliceFromFields(argt)
+	err = row.Scan(columnValues...)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	err = s.decryptValues(columnValues)
+	if err == nil {
+		err = setObjectFields(arg, columnValues...)
+	}
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	err = afterSelect(ctx, arg)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	return nil
+}
+
+// FindByID selects the object whose ID matches the id received as argument into arg, which must be a pointer to a
+// struct with an ID field.
+func (db *Database) FindByID(arg any, id any) error {
+	return db.FindByIDCtx(context.Background(), arg, id)
+}
+
+func (db *Database) FindByIDCtx(ctx context.Context, arg any, id any) error {
+	return db.retry(ctx, false, func() error {
+		return findByID(ctx, db.readSession(), arg, id)
+	})
+}
+
+func findByID(ctx context.Context, s *session, arg any, id any) error {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not select object by id")
+	}
+
+	if _, ok := argt.FieldByName("ID"); !ok {
+		return errors.New(fmt.Sprintf("could not select object of type %s by id - type does not have an ID field",
+			argt.Name()))
+	}
+
+	clauses := buildIDClause(argt, s.namingStrategy())
+	return selectOne(ctx, s, arg, clauses, id)
+}
+
+// First selects into arg the object with the lowest ID among the rows matching the clauses, which may not order or
+// limit the rows. Unlike SelectOne, the first row is selected deterministically, since the rows are ordered by ID.
+func (db *Database) First(arg any, clauses string, args ...any) error {
+	return db.FirstCtx(context.Background(), arg, clauses, args...)
+}
+
+func (db *Database) FirstCtx(ctx context.Context, arg any, clauses string, args ...any) error {
+	return db.retry(ctx, false, func() error {
+		return selectFirst(ctx, db.readSession(), arg, clauses, false, args...)
+	})
+}
+
+// Last selects into arg the object with the highest ID among the rows matching the clauses, like First.
+func (db *Database) Last(arg any, clauses string, args ...any) error {
+	return db.LastCtx(context.Background(), arg, clauses, args...)
+}
+
+func (db *Database) LastCtx(ctx context.Context, arg any, clauses string, args ...any) error {
+	return db.retry(ctx, false, func() error {
+		return selectFirst(ctx, db.readSession(), arg, clauses, true, args...)
+	})
+}
+
+// selectFirst selects into arg the object with the lowest ID among the rows matching the clauses, or with the highest
+// ID if descending is set.
+func selectFirst(ctx context.Context, s *session, arg any, clauses string, descending bool, args ...any) error {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not select object")
+	}
+
+	if _, ok := argt.FieldByName("ID"); !ok {
+		return errors.New(fmt.Sprintf("could not select first object of type %s - type does not have an ID field",
+			argt.Name()))
+	}
+
+	return selectOne(ctx, s, arg, buildFirstClauses(argt, clauses, descending, s.namingStrategy()), args...)
+}
+
+// Select selects the rows of the table of the type matching the clauses, e.g. "where owner_id = $1 order by id", into a
+// slice of objects of the type. Like the other operations taking raw clauses, the clauses may number their placeholders
+// or use ? placeholders, which are numbered with Expand, e.g. "where status in (?)" with a slice argument.
+func (db *Database) Select(t reflect.Type, clauses string, args ...any) (any, error) {
+	return db.SelectCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *Database) SelectCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (any, error) {
+	return retryResult(ctx, db, false, func() (any, error) {
+		return selectAll(ctx, db.readSession(), t, clauses, args...)
+	})
+}
+
+func selectAll(ctx context.Context, s *session, t reflect.Type, clauses string, args ...any) (any, error) {
+	clauses, args = expandClauses(clauses, args)
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	return selectStatement(ctx, s, t, columnScanPlan(t), s.cachedSelectStatement(t, clauses, tenantIdx), args...)
+}
+
+// selectFields selects the columns of the fields received as argument into a slice of objects of the type. The other
+// fields of the objects are left at their zero value.
+func selectFields(ctx context.Context, s *session, t reflect.Type, fields []reflect.StructField, clauses string,
+	args ...any) (any, error) {
+	clauses, args = expandClauses(clauses, args)
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	statement := buildSelectFieldsStatement(t, fields, clauses, s.unscoped, tenantIdx, s.namingStrategy())
+	return selectStatement(ctx, s, t, newScanPlan(fields), statement, args...)
+}
+
+// selectStatement runs a statement selecting the columns of the fields of the scan plan received as argument, and scans
+// its result into a slice of objects of the type.
+func selectStatement(ctx context.Context, s *session, t reflect.Type, plan *scanPlan, statement string,
+	args ...any) (_ any, err error) {
+	errmsg := fmt.Sprintf("could not select objects of type %s", t.Name())
+
+	ctx, span := s.startSpan(ctx, "select", s.namingStrategy().TableName(t))
+	defer func() { span.End(err) }()
+
+	rows, err := s.Query(ctx, statement, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+	defer rows.Close()
+
+	// the rows are collected by pgx, which closes them and reports their error once they are read
+	columnValues := make([]any, len(plan.fields))
+	objects, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (reflect.Value, error) {
+		// the columns are scanned directly into the fields of the new object, see scanPlan
+		newelem := reflect.New(t)
+		plan.destinations(newelem.Elem(), columnValues)
+		err := row.Scan(columnValues...)
+		if err == nil {
+			err = plan.setConverted(s.settings, newelem.Elem(), columnValues)
+		}
+		if err == nil {
+			err = afterSelect(ctx, newelem.Interface())
+		}
+
+		return newelem.Elem(), err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	result := reflect.MakeSlice(reflect.SliceOf(t), 0, len(objects))
+	for _, object := range objects {
+		result = reflect.Append(result, object)
+	}
+
+	return result.Interface(), nil
+}
+
+func (db *Database) SelectQuery(t reflect.Type, q *Query) (any, error) {
+	return db.SelectQueryCtx(context.Background(), t, q)
+}
+
+func (db *Database) SelectQueryCtx(ctx context.Context, t reflect.Type, q *Query) (any, error) {
+	return retryResult(ctx, db, false, func() (any, error) {
+		return selectQuery(ctx, db.readSession(), t, q)
+	})
+}
+
+func selectQuery(ctx context.Context, s *session, t reflect.Type, q *Query) (any, error) {
+	statement, args := q.buildStatement(t, s.tenant, func(clauses string, tenantIdx int) string {
+		return s.cachedSelectStatement(t, clauses, tenantIdx)
+	})
+
+	return selectStatement(ctx, s, t, columnScanPlan(t), statement, args...)
+}
+
+// SelectColumns selects only the columns received as argument, given by field or column name, of the rows matching the
+// clauses. The other fields of the returned objects are left at their zero value.
+func (db *Database) SelectColumns(t reflect.Type, columns []string, clauses string, args ...any) (any, error) {
+	return db.SelectColumnsCtx(context.Background(), t, columns, clauses, args...)
+}
+
+func (db *Database) SelectColumnsCtx(ctx context.Context, t reflect.Type, columns []string, clauses string,
+	args ...any) (any, error) {
+	return retryResult(ctx, db, false, func() (any, error) {
+		return selectColumns(ctx, db.readSession(), t, columns, clauses, args...)
+	})
+}
+
+func selectColumns(ctx context.Context, s *session, t reflect.Type, columns []string, clauses string,
+	args ...any) (any, error) {
+	if len(columns) == 0 {
+		return nil, errors.New(fmt.Sprintf("could not select objects of type %s - no columns provided", t.Name()))
+	}
+
+	fields, err := buildSelectFields(t, columns, s.namingStrategy())
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not select objects of type %s", t.Name()))
+	}
+
+	return selectFields(ctx, s, t, fields, clauses, args...)
+}
+
+// SelectWhere selects the objects matching the condition received as argument.
+func (db *Database) SelectWhere(t reflect.Type, condition Condition) (any, error) {
+	return db.SelectWhereCtx(context.Background(), t, condition)
+}
+
+func (db *Database) SelectWhereCtx(ctx context.Context, t reflect.Type, condition Condition) (any, error) {
+	return db.SelectQueryCtx(ctx, t, NewQuery().Filter(condition))
+}
+
+// SelectInto selects the expressions received as argument, e.g. "status, count(*) as total", from the table of the type
+// with the clauses of the query, and scans the result into dest like Query, i.e. by column name into a struct or a
+// slice of structs. Together with the GroupBy and Having clauses of the query, it runs reporting queries into ad-hoc
+// result structs. Like Select, soft deleted rows are left out unless the database is unscoped.
+func (db *Database) SelectInto(dest any, t reflect.Type, expressions string, q *Query) error {
+	return db.SelectIntoCtx(context.Background(), dest, t, expressions, q)
+}
+
+func (db *Database) SelectIntoCtx(ctx context.Context, dest any, t reflect.Type, expressions string, q *Query) error {
+	return db.retry(ctx, false, func() error {
+		return selectInto(ctx, db.readSession(), dest, t, expressions, q)
+	})
+}
+
+func selectInto(ctx context.Context, s *session, dest any, t reflect.Type, expressions string, q *Query) error {
+	statement, args := q.buildStatement(t, s.tenant, func(clauses string, tenantIdx int) string {
+		return buildSelectIntoStatement(t, expressions, clauses, s.unscoped, tenantIdx, s.namingStrategy())
+	})
+
+	return queryInto(ctx, s, dest, statement, args...)
+}
+
+// Count returns the number of rows of the table of the type that match the clauses.
+func (db *Database) Count(t reflect.Type, clauses string, args ...any) (int64, error) {
+	return db.CountCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *Database) CountCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error) {
+	return retryResult(ctx, db, false, func() (int64, error) {
+		return count(ctx, db.readSession(), t, clauses, args...)
+	})
+}
+
+func count(ctx context.Context, s *session, t reflect.Type, clauses string, args ...any) (int64, error) {
+	clauses, args = expandClauses(clauses, args)
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	statement := buildCountStatement(t, clauses, s.unscoped, tenantIdx, s.namingStrategy())
+	row := s.QueryRow(ctx, statement, args...)
+
+	var count int64
+	if err := row.Scan(&count); err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("could not count objects of type %s", t.Name()))
+	}
+
+	return count, nil
+}
+
+// Exists reports whether the table of the type has any row matching the clauses.
+func (db *Database) Exists(t reflect.Type, clauses string, args ...any) (bool, error) {
+	return db.ExistsCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *Database) ExistsCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (bool, error) {
+	return retryResult(ctx, db, false, func() (bool, error) {
+		return exists(ctx, db.readSession(), t, clauses, args...)
+	})
+}
+
+func exists(ctx context.Context, s *session, t reflect.Type, clauses string, args ...any) (bool, error) {
+	clauses, args = expandClauses(clauses, args)
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	statement := buildExistsStatement(t, clauses, s.unscoped, tenantIdx, s.namingStrategy())
+	row := s.QueryRow(ctx, statement, args...)
+
+	var exists bool
+	if err := row.Scan(&exists); err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("could not check existence of objects of type %s", t.Name()))
+	}
+
+	return exists, nil
+}
+
+// Select is the generic counterpart of Database.Select, returning the selected objects as a []T.
+func Select[T any](db *Database, clauses string, args ...any) ([]T, error) {
+	resultif, err := db.Select(typeOf[T](), clauses, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultif.([]T), nil
+}
+
+// SelectOne is the generic counterpart of Database.SelectOne, returning the selected object as a T.
+func SelectOne[T any](db *Database, clauses string, args ...any) (T, error) {
+	var result T
+	err := db.SelectOne(&result, clauses, args...)
+	return result, err
+}
+
+// SelectByIDs selects the objects of type T whose ID is contained in ids. The result follows the order of ids, and ids
+// without a matching row are left out of it.
+func SelectByIDs[T any](db *Database, ids []int64) ([]T, error) {
+	errmsg := fmt.Sprintf("could not select objects of type %s by id", typeOf[T]().Name())
+
+	clauses := buildIDsClause(typeOf[T](), db.namingStrategy())
+	selected, err := Select[T](db, clauses, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	byID := make(map[int64]T)
+	for _, elem := range selected {
+		id, err := getIntegerIDValue(elem)
+		if err != nil {
+			return nil, errors.Wrap(err, errmsg)
+		}
+
+		byID[id] = elem
+	}
+
+	result := make([]T, 0, len(byID))
+	for _, id := range ids {
+		if elem, ok := byID[id]; ok {
+			result = append(result, elem)
+		}
+	}
+
+	return result, nil
+}
+
+// Explain returns the plan of the select of the rows of the type matching the clauses, as generated by Select, in the
+// text format of explain, for performance tuning. The select is not run, see ExplainAnalyze.
+func (db *Database) Explain(t reflect.Type, clauses string, args ...any) (string, error) {
+	return db.ExplainCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *Database) ExplainCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (string, error) {
+	return explain(ctx, db.session(), t, false, clauses, args...)
+}
+
+// ExplainAnalyze returns the plan of the select like Explain, but runs the select, so that the plan includes the actual
+// run time and buffer usage statistics of each step.
+func (db *Database) ExplainAnalyze(t reflect.Type, clauses string, args ...any) (string, error) {
+	return db.ExplainAnalyzeCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *Database) ExplainAnalyzeCtx(ctx context.Context, t reflect.Type, clauses string,
+	args ...any) (string, error) {
+	return explain(ctx, db.session(), t, true, clauses, args...)
+}
+
+func explain(ctx context.Context, s *session, t reflect.Type, analyze bool, clauses string, args ...any) (string,
+	error) {
+	errmsg := fmt.Sprintf("could not explain select of objects of type %s", t.Name())
+	clauses, args = expandClauses(clauses, args)
+
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	statement := buildExplainStatement(buildSelectStatement(t, clauses, s.unscoped, tenantIdx, s.namingStrategy()),
+		analyze)
+	rows, err := s.Query(ctx, statement, args...)
+	if err != nil {
+		return "", errors.Wrap(err, errmsg)
 	}
+	defer rows.Close()
+
+	plan := make([]string, 0)
+	for rows.Next() {
+		var line string
+		err = rows.Scan(&line)
+		if err != nil {
+			return "", errors.Wrap(err, errmsg)
+		}
+
+		plan = append(plan, line)
+	}
+
+	if rows.Err() != nil {
+		return "", errors.Wrap(rows.Err(), errmsg)
+	}
+
+	return strings.Join(plan, "\n"), nil
+}
+
+// Save inserts the object received as argument if its ID field holds the zero value of its type, and updates the row of
+// the object with UpdateOne otherwise.
+func (db *Database) Save(arg any) error {
+	return db.SaveCtx(context.Background(), arg)
+}
+
+func (db *Database) SaveCtx(ctx context.Context, arg any) error {
+	return db.audited(ctx, func(s *session) error {
+		return save(ctx, s, arg)
+	})
+}
+
+func save(ctx context.Context, s *session, arg any) error {
+	id, err := getIDValue(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not save object")
+	}
+
+	if reflect.ValueOf(id).IsZero() {
+		return insert(ctx, s, arg)
+	}
+
+	return updateOne(ctx, s, arg)
+}
 
+func (db *Database) UpdateOne(arg any) error {
+	return db.UpdateOneCtx(context.Background(), arg)
+}
+
+func (db *Database) UpdateOneCtx(ctx context.Context, arg any) error {
+	return db.retry(ctx, true, func() error {
+		return db.audited(ctx, func(s *session) error {
+			return updateOne(ctx, s, arg)
+		})
+	})
+}
+
+// UpdateColumns updates only the columns received as argument, given by field or column name, of the row of the object.
+// It otherwise behaves like UpdateOne, including the version check of versioned types.
+func (db *Database) UpdateColumns(arg any, columns ...string) error {
+	return db.UpdateColumnsCtx(context.Background(), arg, columns...)
+}
+
+func (db *Database) UpdateColumnsCtx(ctx context.Context, arg any, columns ...string) error {
+	return db.retry(ctx, true, func() error {
+		return db.audited(ctx, func(s *session) error {
+			return updateColumns(ctx, s, arg, columns...)
+		})
+	})
+}
+
+func updateColumns(ctx context.Context, s *session, arg any, columns ...string) error {
+	if len(columns) == 0 {
+		return errors.New("could not update object - no columns provided")
+	}
+
+	return updateOne(ctx, s, arg, columns...)
+}
+
+// UpdateMany updates the rows of the objects of the slice received as argument, like UpdateOne for each of them, but
+// sends all updates to the database in a single batch. An error is returned if any of the rows is missing or, for
+// versioned types, stale, but the other updates of the batch are still applied unless it runs within a transaction.
+func (db *Database) UpdateMany(args any) error {
+	return db.UpdateManyCtx(context.Background(), args)
+}
+
+func (db *Database) UpdateManyCtx(ctx context.Context, args any) error {
+	return db.audited(ctx, func(s *session) error {
+		return updateMany(ctx, s, args)
+	})
+}
+
+func updateMany(ctx context.Context, s *session, args any) error {
+	objects, err := getSliceObjects(args)
+	if err != nil {
+		return errors.Wrap(err, "could not update objects")
+	}
+
+	if len(objects) == 0 {
+		return nil
+	}
+
+	argt, err := getObjectType(objects[0])
+	if err != nil {
+		return errors.Wrap(err, "could not update objects")
+	}
+	errmsg := fmt.Sprintf("could not update objects of type %s", argt.Name())
+
+	err = checkWritable(argt)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	batch := &pgx.Batch{}
+	for _, object := range objects {
+		err = beforeUpdate(ctx, object)
+		if err == nil {
+			err = s.setTimestamps(object, false)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		statement, values, err := buildUpdateOneStatement(object, nil, s.tenant, s.namingStrategy(), s.sqlDialect())
+		if err == nil {
+			err = s.encryptValues(values)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		batch.Queue(statement, values...)
+	}
+
+	oldRows := make([][]auditRow, len(objects))
+	if s.audit {
+		for i, object := range objects {
+			oldRows[i], err = s.selectAuditObject(ctx, object)
+			if err != nil {
+				return errors.Wrap(err, errmsg)
+			}
+		}
+	}
+
+	results := s.SendBatch(ctx, batch)
+	defer results.Close()
+
+	versioned := isVersioned(argt)
+	for range objects {
+		commandTag, err := results.Exec()
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		if commandTag.RowsAffected() != 1 {
+			if versioned {
+				return errors.Wrap(ErrStaleObject, errmsg)
+			}
+			return errors.New(fmt.Sprintf("%s - incorrect number of rows affected", errmsg))
+		}
+	}
+
+	err = results.Close()
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	for i, object := range objects {
+		if versioned {
+			version, err := getVersionValue(object)
+			if err != nil {
+				return errors.Wrap(err, errmsg)
+			}
+
+			err = setVersionValue(object, version+1)
+			if err != nil {
+				return errors.Wrap(err, errmsg)
+			}
+		}
+
+		if s.audit {
+			newRows, err := s.selectAuditObject(ctx, object)
+			if err == nil {
+				err = s.writeAuditLog(ctx, argt, AuditUpdate, oldRows[i], newRows)
+			}
+			if err != nil {
+				return errors.Wrap(err, errmsg)
+			}
+		}
+
+		err = afterUpdate(ctx, object)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	return nil
+}
+
+// updateOne updates the row of the object received as argument. If columns are provided, only these are updated.
+func updateOne(ctx context.Context, s *session, arg any, columns ...string) (err error) {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not update object")
+	}
+
+	errmsg := fmt.Sprintf("could not update object of type %s", argt.Name())
+
+	err = checkWritable(argt)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	ctx, span := s.startSpan(ctx, "update", s.namingStrategy().TableName(argt))
+	defer func() { span.End(err) }()
+
+	err = beforeUpdate(ctx, arg)
+	if err == nil {
+		err = s.setTimestamps(arg, false)
+	}
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	statement, values, err := buildUpdateOneStatement(arg, columns, s.tenant, s.namingStrategy(), s.sqlDialect())
+	if err == nil {
+		err = s.encryptValues(values)
+	}
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	var oldRows []auditRow
+	if s.audit {
+		oldRows, err = s.selectAuditObject(ctx, arg)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	commandTag, err := s.Exec(ctx, statement, values...)
+	if err != nil {
+		return errors.Wrap(err, "could not update object")
+	}
+
+	versioned := isVersioned(argt)
 	if commandTag.RowsAffected() != 1 {
+		if versioned {
+			return errors.Wrap(ErrStaleObject, errmsg)
+		}
 		return errors.New("incorrect number of rows affected after updating the object")
 	}
 
+	if versioned {
+		version, err := getVersionValue(arg)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		err = setVersionValue(arg, version+1)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	if s.audit {
+		newRows, err := s.selectAuditObject(ctx, arg)
+		if err == nil {
+			err = s.writeAuditLog(ctx, argt, AuditUpdate, oldRows, newRows)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	err = afterUpdate(ctx, arg)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
 	return nil
 }
 
+// UpdateWhere sets the columns of the set map, given by field or column name, on all rows of the table of the type that
+// match the clauses, and returns the number of updated rows. The clauses use placeholders from $1 for their arguments,
+// e.g. UpdateWhere(t, map[string]any{"Status": "expired"}, "where expires_at < $1", time.Now()).
+func (db *Database) UpdateWhere(t reflect.Type, set map[string]any, clauses string, args ...any) (int64, error) {
+	return db.UpdateWhereCtx(context.Background(), t, set, clauses, args...)
+}
+
+func (db *Database) UpdateWhereCtx(ctx context.Context, t reflect.Type, set map[string]any, clauses string,
+	args ...any) (int64, error) {
+	return retryResult(ctx, db, true, func() (int64, error) {
+		return auditedResult(ctx, db, func(s *session) (int64, error) {
+			return updateWhere(ctx, s, t, set, clauses, args...)
+		})
+	})
+}
+
+func updateWhere(ctx context.Context, s *session, t reflect.Type, set map[string]any, clauses string,
+	args ...any) (int64, error) {
+	errmsg := fmt.Sprintf("could not update objects of type %s", t.Name())
+	clauses, args = expandClauses(clauses, args)
+
+	err := checkWritable(t)
+	if err != nil {
+		return 0, errors.Wrap(err, errmsg)
+	}
+
+	if len(set) == 0 {
+		return 0, errors.New(fmt.Sprintf("%s - no columns provided", errmsg))
+	}
+
+	// the columns are sorted so that the same map always results in the same statement
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	columns := make([]string, len(keys))
+	values := append([]any{}, args...)
+	for i, key := range keys {
+		columns[i] = fieldColumnName(t, key, s.namingStrategy())
+		values = append(values, set[key])
+	}
+
+	var oldRows []auditRow
+	if s.audit {
+		oldRows, err = s.selectAuditRows(ctx, t, clauses, s.unscoped, args...)
+		if err != nil {
+			return 0, errors.Wrap(err, errmsg)
+		}
+	}
+
+	tenantIdx, values := tenantArgs(t, s.tenant, values)
+	statement := buildUpdateWhereStatement(t, columns, clauses, len(args)+1, s.unscoped, tenantIdx,
+		s.namingStrategy())
+	commandTag, err := s.Exec(ctx, statement, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, errmsg)
+	}
+
+	// the updated rows may no longer match the clauses, so they are selected again by ID
+	if s.audit {
+		newRows, err := s.selectAuditRowsByID(ctx, t, oldRows)
+		if err == nil {
+			err = s.writeAuditLog(ctx, t, AuditUpdate, oldRows, newRows)
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, errmsg)
+		}
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
 func (db *Database) Delete(t reflect.Type, clauses string, args ...any) (int64, error) {
+	return db.DeleteCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *Database) DeleteCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error) {
+	return retryResult(ctx, db, true, func() (int64, error) {
+		return auditedResult(ctx, db, func(s *session) (int64, error) {
+			return deleteAll(ctx, s, t, clauses, args...)
+		})
+	})
+}
+
+func deleteAll(ctx context.Context, s *session, t reflect.Type, clauses string, args ...any) (_ int64, err error) {
 	errmsg := fmt.Sprintf("could not delete objects of type %s", t.Name())
+	clauses, args = expandClauses(clauses, args)
+
+	err = checkWritable(t)
+	if err != nil {
+		return 0, errors.Wrap(err, errmsg)
+	}
+
+	ctx, span := s.startSpan(ctx, "delete", s.namingStrategy().TableName(t))
+	defer func() { span.End(err) }()
+
+	err = beforeDelete(ctx, t, clauses, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, errmsg)
+	}
+
+	var oldRows []auditRow
+	if s.audit {
+		oldRows, err = s.selectAuditRows(ctx, t, clauses, s.unscoped, args...)
+		if err != nil {
+			return 0, errors.Wrap(err, errmsg)
+		}
+	}
 
-	statement := buildDeleteStatement(t, clauses)
-	commandTag, err := db.Conn.Exec(context.Background(), statement, args...)
+	tenantIdx, values := tenantArgs(t, s.tenant, args)
+	statement := buildScopedDeleteStatement(t, clauses, s.unscoped, tenantIdx, s.namingStrategy(), s.sqlDialect())
+	commandTag, err := s.Exec(ctx, statement, values...)
 	if err != nil {
 		return 0, errors.Wrap(err, errmsg)
 	}
 
+	if s.audit {
+		err = s.writeAuditLog(ctx, t, AuditDelete, oldRows, nil)
+		if err != nil {
+			return 0, errors.Wrap(err, errmsg)
+		}
+	}
+
 	return commandTag.RowsAffected(), nil
 }
+
+// HardDelete removes the matching rows even for soft deleted types. It is equivalent to db.Unscoped().Delete(...).
+func (db *Database) HardDelete(t reflect.Type, clauses string, args ...any) (int64, error) {
+	return db.Unscoped().Delete(t, clauses, args...)
+}
+
+func (db *Database) HardDeleteCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error) {
+	return db.Unscoped().DeleteCtx(ctx, t, clauses, args...)
+}
+
+func (db *Database) DeleteQuery(t reflect.Type, q *Query) (int64, error) {
+	return db.DeleteQueryCtx(context.Background(), t, q)
+}
+
+func (db *Database) DeleteQueryCtx(ctx context.Context, t reflect.Type, q *Query) (int64, error) {
+	clauses, args := q.Build()
+	return retryResult(ctx, db, true, func() (int64, error) {
+		return auditedResult(ctx, db, func(s *session) (int64, error) {
+			return deleteAll(ctx, s, t, clauses, args...)
+		})
+	})
+}
+
+// DeleteWhere deletes the rows matching the condition received as argument, like Delete.
+func (db *Database) DeleteWhere(t reflect.Type, condition Condition) (int64, error) {
+	return db.DeleteWhereCtx(context.Background(), t, condition)
+}
+
+func (db *Database) DeleteWhereCtx(ctx context.Context, t reflect.Type, condition Condition) (int64, error) {
+	return db.DeleteQueryCtx(ctx, t, NewQuery().Filter(condition))
+}
+
+// DeleteByIDs deletes the rows of the type whose ID is contained in ids, like Delete, and returns the number of deleted
+// rows. The IDs are passed as a single array argument, so the statement is the same regardless of their number.
+func (db *Database) DeleteByIDs(t reflect.Type, ids []int64) (int64, error) {
+	return db.DeleteByIDsCtx(context.Background(), t, ids)
+}
+
+func (db *Database) DeleteByIDsCtx(ctx context.Context, t reflect.Type, ids []int64) (int64, error) {
+	return retryResult(ctx, db, true, func() (int64, error) {
+		return auditedResult(ctx, db, func(s *session) (int64, error) {
+			return deleteAll(ctx, s, t, buildIDsClause(t, db.namingStrategy()), ids)
+		})
+	})
+}
+
+// DeleteOne deletes the row of the object received as argument, i.e. the row matching its ID. Like Delete, it sets the
+// DeletedAt column of soft deleted types instead of removing the row. If no row matches, ErrNotFound is returned.
+func (db *Database) DeleteOne(arg any) error {
+	return db.DeleteOneCtx(context.Background(), arg)
+}
+
+func (db *Database) DeleteOneCtx(ctx context.Context, arg any) error {
+	return db.retry(ctx, true, func() error {
+		return db.audited(ctx, func(s *session) error {
+			return deleteOne(ctx, s, arg)
+		})
+	})
+}
+
+func deleteOne(ctx context.Context, s *session, arg any) error {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not delete object")
+	}
+
+	errmsg := fmt.Sprintf("could not delete object of type %s", argt.Name())
+
+	id, err := getIDValue(arg)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	clauses := buildIDClause(argt, s.namingStrategy())
+	rows, err := deleteAll(ctx, s, argt, clauses, id)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.Wrap(ErrNotFound, errmsg)
+	}
+
+	return nil
+}
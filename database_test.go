@@ -2,11 +2,21 @@ package liteorm
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
 	"math"
+	"net"
+	"net/netip"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,8 +33,94 @@ type TestItem struct {
 
 var TestItemType reflect.Type = reflect.TypeOf((*TestItem)(nil)).Elem()
 
+type TestUUIDItem struct {
+	ID           pgtype.UUID `pgsql:"primary key"`
+	StringColumn string      `pglen:"25"`
+}
+
+var TestUUIDItemType reflect.Type = reflect.TypeOf((*TestUUIDItem)(nil)).Elem()
+
+// TestUUID has the same layout as github.com/google/uuid.UUID.
+type TestUUID [16]byte
+
+type TestArrayUUIDItem struct {
+	ID           TestUUID `pgsql:"primary key"`
+	StringColumn string   `pglen:"25"`
+}
+
+var TestArrayUUIDItemType reflect.Type = reflect.TypeOf((*TestArrayUUIDItem)(nil)).Elem()
+
+type TestUpsertItem struct {
+	ID          int64  `pgsql:"primary key"`
+	KeyColumn   string `pglen:"25" pgsql:"unique"`
+	ValueColumn int
+}
+
+var TestUpsertItemType reflect.Type = reflect.TypeOf((*TestUpsertItem)(nil)).Elem()
+
+type TestAutoMigrateItem struct {
+	ID           int64  `pgsql:"primary key"`
+	StringColumn string `pglen:"25"`
+	IntColumn    int
+}
+
+var TestAutoMigrateItemType reflect.Type = reflect.TypeOf((*TestAutoMigrateItem)(nil)).Elem()
+
+type TestSoftDeleteItem struct {
+	ID           int64  `pgsql:"primary key"`
+	StringColumn string `pglen:"25"`
+	DeletedAt    *time.Time
+}
+
+var TestSoftDeleteItemType reflect.Type = reflect.TypeOf((*TestSoftDeleteItem)(nil)).Elem()
+
+type TestVersionedItem struct {
+	ID           int64  `pgsql:"primary key"`
+	StringColumn string `pglen:"25"`
+	Version      int64
+}
+
+var TestVersionedItemType reflect.Type = reflect.TypeOf((*TestVersionedItem)(nil)).Elem()
+
+type TestUser struct {
+	ID     int64       `pgsql:"primary key"`
+	Name   string      `pglen:"25"`
+	Orders []TestOrder `liteorm:"hasmany,fk:test_user_id"`
+}
+
+var TestUserType reflect.Type = reflect.TypeOf((*TestUser)(nil)).Elem()
+
+type TestOrder struct {
+	ID         int64 `pgsql:"primary key"`
+	TestUserID int64
+	Amount     int
+	TestUser   *TestUser `liteorm:"belongsto,fk:test_user_id"`
+}
+
+var TestOrderType reflect.Type = reflect.TypeOf((*TestOrder)(nil)).Elem()
+
+type TestHookItem struct {
+	ID            int64 `pgsql:"primary key"`
+	ValueColumn   int
+	DoubledColumn int
+}
+
+var TestHookItemType reflect.Type = reflect.TypeOf((*TestHookItem)(nil)).Elem()
+
+func (item *TestHookItem) BeforeInsert(ctx context.Context) error {
+	if item.ValueColumn < 0 {
+		return fmt.Errorf("negative value %d", item.ValueColumn)
+	}
+	return nil
+}
+
+func (item *TestHookItem) AfterSelect(ctx context.Context) error {
+	item.DoubledColumn = item.ValueColumn * 2
+	return nil
+}
+
 var host = flag.String("host", "", "database host")
-var port = flag.String("port", "", "database port")
+var port = flag.Int("port", 0, "database port")
 var user = flag.String("user", "", "database user")
 var password = flag.String("password", "", "database password")
 var database = flag.String("database", "", "default database")
@@ -36,13 +132,8 @@ func TestMain(m *testing.M) {
 	var err error
 	flag.Parse()
 
-	dsnString := fmt.Sprintf("%s=%s ", "host", *host)
-	dsnString += fmt.Sprintf("%s=%s ", "port", *port)
-	dsnString += fmt.Sprintf("%s=%s ", "user", *user)
-	dsnString += fmt.Sprintf("%s=%s ", "password", *password)
-	dsnString += fmt.Sprintf("%s=%s ", "database", *database)
-
-	db, err = NewDatabase(dsnString)
+	db, err = NewDatabaseFromConfig(Config{Host: *host, Port: *port, User: *user, Password: *password,
+		Database: *database})
 	if err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
@@ -132,6 +223,66 @@ func TestInsert(t *testing.T) {
 	if err != nil {
 		t.Errorf("could not insert second object - %s", err.Error())
 	}
+
+	if testObject.ID == 0 || anotherTestObject.ID != testObject.ID+1 {
+		t.Errorf("ID field not populated after insert")
+	}
+}
+
+func TestInsertUUIDKey(t *testing.T) {
+	// gen_random_uuid is only part of core PostgreSQL from version 13 onwards
+	_, err := db.Conn.Exec(context.Background(), "create extension if not exists pgcrypto;")
+	if err != nil {
+		t.Fatalf("could not create pgcrypto extension - %s", err.Error())
+	}
+
+	err = db.CreateTable(TestUUIDItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	uuidObject := &TestUUIDItem{StringColumn: "lashbits.tech"}
+	err = db.Insert(uuidObject)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	if !uuidObject.ID.Valid {
+		t.Errorf("ID field not populated after insert")
+	}
+}
+
+func TestArrayUUIDKey(t *testing.T) {
+	err := db.CreateTable(TestArrayUUIDItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	object := &TestArrayUUIDItem{StringColumn: "lashbits.tech"}
+	err = db.Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	if object.ID == (TestUUID{}) {
+		t.Errorf("ID field not populated after insert")
+	}
+
+	object.StringColumn = "lashbits.tech updated!"
+	err = db.UpdateOne(object)
+	if err != nil {
+		t.Errorf("could not update object - %s", err.Error())
+	}
+
+	var selectedObject TestArrayUUIDItem
+	err = db.SelectOne(&selectedObject, "where id = $1", object.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	if selectedObject != *object {
+		t.Errorf("mismatch between the updated and selected objects")
+	}
 }
 
 func TestSelectOne(t *testing.T) {
@@ -182,6 +333,115 @@ func TestSelect(t *testing.T) {
 	}
 }
 
+func TestSelectQuery(t *testing.T) {
+	var result []TestItem
+
+	q := NewQuery().Where("int_column = ?", 1337).Where("id >= ?", testObject.ID).OrderBy("id desc").Limit(1)
+	if resultif, err := db.SelectQuery(TestItemType, q); err == nil {
+		result = resultif.([]TestItem)
+	} else {
+		t.Errorf("could not select objects - %s", err.Error())
+	}
+
+	if len(result) != 1 {
+		t.Fatalf("incorrect amount of objects selected - %d instead of 1", len(result))
+	}
+
+	if result[0].ID != testObject.ID+1 {
+		t.Errorf("incorrect object selected - id %d instead of %d", result[0].ID, testObject.ID+1)
+	}
+}
+
+func TestSelectGeneric(t *testing.T) {
+	result, err := Select[TestItem](db, "where id = $1", testObject.ID)
+	if err != nil {
+		t.Errorf("could not select objects - %s", err.Error())
+	}
+
+	if len(result) != 1 {
+		t.Fatalf("incorrect amount of objects selected - %d instead of 1", len(result))
+	}
+
+	testEquality(*testObject, result[0], t)
+
+	selectedTestObject, err := SelectOne[TestItem](db, "where id = $1", testObject.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	testEquality(*testObject, selectedTestObject, t)
+}
+
+func TestSelectCtx(t *testing.T) {
+	var selectedTestObject TestItem
+
+	err := db.SelectOneCtx(context.Background(), &selectedTestObject, "where id = $1", testObject.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = db.SelectCtx(ctx, TestItemType, "")
+	if err == nil {
+		t.Errorf("expected error when selecting with a cancelled context")
+	}
+}
+
+func TestSelectByIDs(t *testing.T) {
+	ids := []int64{testObject.ID + 1, 200, testObject.ID}
+	result, err := SelectByIDs[TestItem](db, ids)
+	if err != nil {
+		t.Errorf("could not select objects - %s", err.Error())
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("incorrect amount of objects selected - %d instead of 2", len(result))
+	}
+
+	if result[0].ID != testObject.ID+1 || result[1].ID != testObject.ID {
+		t.Errorf("objects not returned in the order of the ids - %d, %d", result[0].ID, result[1].ID)
+	}
+
+	testEquality(*testObject, result[1], t)
+}
+
+func TestFindByID(t *testing.T) {
+	var selectedObject TestItem
+	err := db.FindByID(&selectedObject, testObject.ID)
+	if err != nil {
+		t.Errorf("could not select object by id - %s", err.Error())
+	}
+
+	testEquality(*testObject, selectedObject, t)
+
+	err = db.FindByID(&selectedObject, int64(-1))
+	if err == nil {
+		t.Errorf("object selected by a nonexistent id")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	plan, err := db.Explain(TestItemType, "where id = $1", testObject.ID)
+	if err != nil {
+		t.Errorf("could not explain select - %s", err.Error())
+	}
+
+	if !strings.Contains(plan, "testitems") {
+		t.Errorf("plan does not mention the testitems table - %s", plan)
+	}
+
+	plan, err = db.ExplainAnalyze(TestItemType, "")
+	if err != nil {
+		t.Errorf("could not explain analyze select - %s", err.Error())
+	}
+
+	if !strings.Contains(plan, "actual time") {
+		t.Errorf("plan does not include the execution statistics - %s", plan)
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	testObject.StringColumn = "lashbits.tech updated!"
 	err := db.UpdateOne(testObject)
@@ -219,3 +479,2988 @@ func TestDelete(t *testing.T) {
 		t.Errorf("incorrect amount of objects remaining after delete - %d instead of 1", len(result))
 	}
 }
+
+func TestTransaction(t *testing.T) {
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("could not begin transaction - %s", err.Error())
+	}
+
+	rolledBackObject := &TestItem{StringColumn: "rolled back", TimeColumn: time.Now().UTC()}
+	err = tx.Insert(rolledBackObject)
+	if err != nil {
+		t.Errorf("could not insert object within transaction - %s", err.Error())
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Errorf("could not rollback transaction - %s", err.Error())
+	}
+
+	var selectedTestObject TestItem
+	err = db.SelectOne(&selectedTestObject, "where id = $1", rolledBackObject.ID)
+	if err == nil {
+		t.Errorf("object inserted within a rolled back transaction was found")
+	}
+
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatalf("could not begin transaction - %s", err.Error())
+	}
+
+	committedObject := &TestItem{StringColumn: "committed", TimeColumn: time.Now().UTC()}
+	err = tx.Insert(committedObject)
+	if err != nil {
+		t.Errorf("could not insert object within transaction - %s", err.Error())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		t.Errorf("could not commit transaction - %s", err.Error())
+	}
+
+	err = db.SelectOne(&selectedTestObject, "where id = $1", committedObject.ID)
+	if err != nil {
+		t.Errorf("could not select object inserted within a committed transaction - %s", err.Error())
+	}
+
+	_, err = db.Delete(TestItemType, "where id = $1", committedObject.ID)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+}
+
+func TestInsertMany(t *testing.T) {
+	objects := []TestItem{
+		{StringColumn: "first", TimeColumn: time.Now().UTC()},
+		{StringColumn: "second", TimeColumn: time.Now().UTC()},
+		{StringColumn: "third", TimeColumn: time.Now().UTC()},
+	}
+
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	for _, object := range objects {
+		var selectedTestObject TestItem
+		err = db.SelectOne(&selectedTestObject, "where id = $1", object.ID)
+		if err != nil {
+			t.Errorf("could not select inserted object - %s", err.Error())
+		}
+
+		testEquality(object, selectedTestObject, t)
+	}
+
+	_, err = db.Delete(TestItemType, "where id >= $1", objects[0].ID)
+	if err != nil {
+		t.Errorf("could not delete objects - %s", err.Error())
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	objects := []TestItem{
+		{StringColumn: "copied", TimeColumn: time.Now().UTC()},
+		{StringColumn: "copied", TimeColumn: time.Now().UTC()},
+		{StringColumn: "copied", TimeColumn: time.Now().UTC()},
+	}
+
+	count, err := db.CopyFrom(objects)
+	if err != nil {
+		t.Fatalf("could not copy objects - %s", err.Error())
+	}
+
+	if count != 3 {
+		t.Errorf("incorrect amount of objects copied - %d instead of 3", count)
+	}
+
+	rows, err := db.Delete(TestItemType, "where string_column = $1", "copied")
+	if err != nil {
+		t.Errorf("could not delete objects - %s", err.Error())
+	}
+
+	if rows != 3 {
+		t.Errorf("incorrect amount of objects deleted - %d instead of 3", rows)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	err := db.CreateTable(TestUpsertItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	insertedObject := &TestUpsertItem{KeyColumn: "lashbits.tech", ValueColumn: 1}
+	err = db.Upsert(insertedObject, "KeyColumn")
+	if err != nil {
+		t.Errorf("could not upsert object - %s", err.Error())
+	}
+
+	updatedObject := &TestUpsertItem{KeyColumn: "lashbits.tech", ValueColumn: 2}
+	err = db.Upsert(updatedObject, "KeyColumn")
+	if err != nil {
+		t.Errorf("could not upsert object - %s", err.Error())
+	}
+
+	if updatedObject.ID != insertedObject.ID {
+		t.Errorf("conflicting upsert did not update the existing row - id %d instead of %d", updatedObject.ID,
+			insertedObject.ID)
+	}
+
+	var selectedObject TestUpsertItem
+	err = db.SelectOne(&selectedObject, "where id = $1", insertedObject.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	if selectedObject.ValueColumn != 2 {
+		t.Errorf("mismatch in the ValueColumn field of the upserted object")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	migrations := []Migration{
+		{
+			Version: 2,
+			Name:    "add value column",
+			Up:      "alter table testmigrations add column valuecolumn int;",
+			Down:    "alter table testmigrations drop column valuecolumn;",
+		},
+		{
+			Version: 1,
+			Name:    "create table",
+			Up:      "create table testmigrations (id bigserial primary key);",
+			Down:    "drop table testmigrations;",
+		},
+	}
+
+	err := db.Migrate(migrations)
+	if err != nil {
+		t.Fatalf("could not migrate - %s", err.Error())
+	}
+
+	_, err = db.Conn.Exec(context.Background(), "insert into testmigrations (valuecolumn) values (1);")
+	if err != nil {
+		t.Errorf("migrated table is not usable - %s", err.Error())
+	}
+
+	// applying the same migrations again is a no-op
+	err = db.Migrate(migrations)
+	if err != nil {
+		t.Errorf("could not migrate a second time - %s", err.Error())
+	}
+
+	err = db.MigrateDown(migrations, 0)
+	if err != nil {
+		t.Fatalf("could not revert migrations - %s", err.Error())
+	}
+
+	var exists bool
+	row := db.Conn.QueryRow(context.Background(), "select to_regclass('testmigrations') is not null;")
+	if err = row.Scan(&exists); err != nil || exists {
+		t.Errorf("table still exists after reverting migrations")
+	}
+}
+
+func TestAutoMigrate(t *testing.T) {
+	statements := []string{
+		"drop table if exists testautomigrateitems;",
+		"create table testautomigrateitems (id bigserial primary key, int_column varchar(10));",
+	}
+	for _, statement := range statements {
+		_, err := db.Conn.Exec(context.Background(), statement)
+		if err != nil {
+			t.Fatalf("could not prepare table - %s", err.Error())
+		}
+	}
+
+	err := db.AutoMigrate(TestAutoMigrateItemType)
+	if err != nil {
+		t.Fatalf("could not migrate table - %s", err.Error())
+	}
+
+	insertedObject := &TestAutoMigrateItem{StringColumn: "lashbits.tech", IntColumn: 1337}
+	err = db.Insert(insertedObject)
+	if err != nil {
+		t.Errorf("could not insert object into migrated table - %s", err.Error())
+	}
+
+	var selectedObject TestAutoMigrateItem
+	err = db.SelectOne(&selectedObject, "where id = $1", insertedObject.ID)
+	if err != nil {
+		t.Errorf("could not select object from migrated table - %s", err.Error())
+	}
+
+	if selectedObject != *insertedObject {
+		t.Errorf("mismatch between the inserted and selected objects")
+	}
+
+	// migrating an up to date table is a no-op
+	err = db.AutoMigrate(TestAutoMigrateItemType)
+	if err != nil {
+		t.Errorf("could not migrate up to date table - %s", err.Error())
+	}
+}
+
+func TestHooks(t *testing.T) {
+	err := db.CreateTable(TestHookItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	err = db.Insert(&TestHookItem{ValueColumn: -1})
+	if err == nil {
+		t.Errorf("expected error from the BeforeInsert hook")
+	}
+
+	insertedObject := &TestHookItem{ValueColumn: 21}
+	err = db.Insert(insertedObject)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	var selectedObject TestHookItem
+	err = db.SelectOne(&selectedObject, "where id = $1", insertedObject.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	if selectedObject.DoubledColumn != 42 {
+		t.Errorf("AfterSelect hook not called - DoubledColumn is %d instead of 42", selectedObject.DoubledColumn)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	err := db.CreateTable(TestSoftDeleteItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	objects := []TestSoftDeleteItem{{StringColumn: "kept"}, {StringColumn: "deleted"}}
+	err = db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	rows, err := db.Delete(TestSoftDeleteItemType, "where id = $1", objects[1].ID)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+
+	if rows != 1 {
+		t.Errorf("incorrect amount of objects deleted - %d instead of 1", rows)
+	}
+
+	result, err := Select[TestSoftDeleteItem](db, "")
+	if err != nil {
+		t.Errorf("could not select objects - %s", err.Error())
+	}
+
+	if len(result) != 1 || result[0].ID != objects[0].ID {
+		t.Errorf("soft deleted object was selected")
+	}
+
+	var deletedObject TestSoftDeleteItem
+	err = db.Unscoped().SelectOne(&deletedObject, "where id = $1", objects[1].ID)
+	if err != nil {
+		t.Errorf("could not select soft deleted object - %s", err.Error())
+	}
+
+	if deletedObject.DeletedAt == nil {
+		t.Errorf("DeletedAt field not set on soft deleted object")
+	}
+
+	rows, err = db.HardDelete(TestSoftDeleteItemType, "where id = $1", objects[1].ID)
+	if err != nil {
+		t.Errorf("could not hard delete object - %s", err.Error())
+	}
+
+	if rows != 1 {
+		t.Errorf("incorrect amount of objects hard deleted - %d instead of 1", rows)
+	}
+
+	err = db.Unscoped().SelectOne(&deletedObject, "where id = $1", objects[1].ID)
+	if err == nil {
+		t.Errorf("hard deleted object was selected")
+	}
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	err := db.CreateTable(TestVersionedItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	object := &TestVersionedItem{StringColumn: "lashbits.tech"}
+	err = db.Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	var staleObject TestVersionedItem
+	err = db.SelectOne(&staleObject, "where id = $1", object.ID)
+	if err != nil {
+		t.Fatalf("could not select object - %s", err.Error())
+	}
+
+	object.StringColumn = "lashbits.tech updated!"
+	err = db.UpdateOne(object)
+	if err != nil {
+		t.Errorf("could not update object - %s", err.Error())
+	}
+
+	if object.Version != 1 {
+		t.Errorf("version not incremented after update - %d instead of 1", object.Version)
+	}
+
+	staleObject.StringColumn = "stale update"
+	err = db.UpdateOne(&staleObject)
+	if !errors.Is(err, ErrStaleObject) {
+		t.Errorf("expected ErrStaleObject when updating a stale object - %v", err)
+	}
+}
+
+func TestPreload(t *testing.T) {
+	for _, tableType := range []reflect.Type{TestUserType, TestOrderType} {
+		err := db.CreateTable(tableType, true)
+		if err != nil {
+			t.Fatalf("could not create table - %s", err.Error())
+		}
+	}
+
+	user := &TestUser{Name: "lashbits"}
+	err := db.Insert(user)
+	if err != nil {
+		t.Fatalf("could not insert user - %s", err.Error())
+	}
+
+	orders := []TestOrder{{TestUserID: user.ID, Amount: 1}, {TestUserID: user.ID, Amount: 2}}
+	err = db.InsertMany(orders)
+	if err != nil {
+		t.Fatalf("could not insert orders - %s", err.Error())
+	}
+
+	err = db.Preload(user, "Orders")
+	if err != nil {
+		t.Errorf("could not preload orders - %s", err.Error())
+	}
+
+	if len(user.Orders) != 2 {
+		t.Errorf("incorrect amount of orders preloaded - %d instead of 2", len(user.Orders))
+	}
+
+	err = db.Preload(orders, "TestUser")
+	if err != nil {
+		t.Errorf("could not preload users - %s", err.Error())
+	}
+
+	for _, order := range orders {
+		if order.TestUser == nil || order.TestUser.ID != user.ID {
+			t.Errorf("user not preloaded for order %d", order.ID)
+		}
+	}
+}
+
+type TestNullableItem struct {
+	ID            int64
+	StringColumn  *string `pglen:"25"`
+	Int64Column   *int64
+	TimeColumn    *time.Time
+	Float64Column *float64
+}
+
+var TestNullableItemType reflect.Type = reflect.TypeOf((*TestNullableItem)(nil)).Elem()
+
+func TestNullableColumns(t *testing.T) {
+	err := db.CreateTable(TestNullableItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	nullObject := &TestNullableItem{}
+	err = db.Insert(nullObject)
+	if err != nil {
+		t.Errorf("could not insert object with nil fields - %s", err.Error())
+	}
+
+	stringValue, int64Value, timeValue := "lashbits.tech", int64(1337), time.Now().UTC()
+	object := &TestNullableItem{StringColumn: &stringValue, Int64Column: &int64Value, TimeColumn: &timeValue}
+	err = db.Insert(object)
+	if err != nil {
+		t.Errorf("could not insert object with non-nil fields - %s", err.Error())
+	}
+
+	var selectedObject TestNullableItem
+	err = db.SelectOne(&selectedObject, "where id = $1", nullObject.ID)
+	if err != nil {
+		t.Errorf("could not select object with null columns - %s", err.Error())
+	}
+
+	if selectedObject.StringColumn != nil || selectedObject.Int64Column != nil || selectedObject.TimeColumn != nil ||
+		selectedObject.Float64Column != nil {
+		t.Errorf("null columns not selected as nil fields")
+	}
+
+	err = db.SelectOne(&selectedObject, "where id = $1", object.ID)
+	if err != nil {
+		t.Errorf("could not select object with non-null columns - %s", err.Error())
+	}
+
+	if selectedObject.StringColumn == nil || *selectedObject.StringColumn != stringValue ||
+		selectedObject.Int64Column == nil || *selectedObject.Int64Column != int64Value ||
+		selectedObject.TimeColumn == nil || selectedObject.Float64Column != nil {
+		t.Errorf("mismatch in the pointer fields of the selected object")
+	}
+}
+
+func TestNullTypes(t *testing.T) {
+	err := db.CreateTable(TestNullTypesItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	nullObject := &TestNullTypesItem{}
+	err = db.Insert(nullObject)
+	if err != nil {
+		t.Errorf("could not insert object with null values - %s", err.Error())
+	}
+
+	object := &TestNullTypesItem{
+		StringColumn: sql.NullString{String: "lashbits.tech", Valid: true},
+		Int64Column:  sql.NullInt64{Int64: 1337, Valid: true},
+		BoolColumn:   sql.NullBool{Bool: true, Valid: true},
+		PointColumn:  Point{X: 13, Y: 37},
+	}
+	err = db.Insert(object)
+	if err != nil {
+		t.Errorf("could not insert object with non-null values - %s", err.Error())
+	}
+
+	var selectedObject TestNullTypesItem
+	err = db.SelectOne(&selectedObject, "where id = $1", nullObject.ID)
+	if err != nil {
+		t.Errorf("could not select object with null values - %s", err.Error())
+	}
+
+	if selectedObject.StringColumn.Valid || selectedObject.Int64Column.Valid || selectedObject.TimeColumn.Valid {
+		t.Errorf("null columns not selected as invalid values")
+	}
+
+	err = db.SelectOne(&selectedObject, "where id = $1", object.ID)
+	if err != nil {
+		t.Errorf("could not select object with non-null values - %s", err.Error())
+	}
+
+	if selectedObject.StringColumn != object.StringColumn || selectedObject.Int64Column != object.Int64Column ||
+		selectedObject.BoolColumn != object.BoolColumn || selectedObject.PointColumn != object.PointColumn {
+		t.Errorf("mismatch in the fields of the selected object")
+	}
+}
+
+func TestJSONColumns(t *testing.T) {
+	err := db.CreateTable(TestJSONItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	object := &TestJSONItem{
+		MapColumn:      map[string]any{"key": "value", "count": float64(2)},
+		MetadataColumn: TestMetadata{Source: "lashbits.tech", Tags: []string{"a", "b"}},
+	}
+	err = db.Insert(object)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	var selectedObject TestJSONItem
+	err = db.SelectOne(&selectedObject, "where id = $1", object.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(selectedObject, *object) {
+		t.Errorf("mismatch between the inserted and selected objects - %v instead of %v", selectedObject, *object)
+	}
+
+	nullObject := &TestJSONItem{}
+	err = db.Insert(nullObject)
+	if err != nil {
+		t.Errorf("could not insert object with nil map - %s", err.Error())
+	}
+
+	err = db.SelectOne(&selectedObject, "where id = $1 and map_column is null", nullObject.ID)
+	if err != nil {
+		t.Errorf("nil map not stored as null - %s", err.Error())
+	}
+
+	if selectedObject.MapColumn != nil {
+		t.Errorf("null column not selected as nil map")
+	}
+}
+
+func TestTableNamer(t *testing.T) {
+	err := db.CreateTable(TestCategoryType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	exists, err := db.TableExists(TestCategoryType)
+	if err != nil || !exists {
+		t.Errorf("table testcategories does not exist, but it should")
+	}
+
+	category := &TestCategory{Name: "lashbits.tech"}
+	err = db.Insert(category)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	category.Name = "lashbits.tech updated!"
+	err = db.UpdateOne(category)
+	if err != nil {
+		t.Errorf("could not update object - %s", err.Error())
+	}
+
+	selectedCategory, err := SelectOne[TestCategory](db, "where id = $1", category.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	if selectedCategory != *category {
+		t.Errorf("mismatch between the updated and selected objects")
+	}
+
+	rows, err := db.Delete(TestCategoryType, "where id = $1", category.ID)
+	if err != nil || rows != 1 {
+		t.Errorf("could not delete object")
+	}
+}
+
+func TestColumnNames(t *testing.T) {
+	err := db.CreateTable(TestColumnItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	object := &TestColumnItem{Email: "contact@lashbits.tech", Name: "lashbits.tech"}
+	err = db.Insert(object)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	object.Email = "info@lashbits.tech"
+	err = db.UpdateOne(object)
+	if err != nil {
+		t.Errorf("could not update object - %s", err.Error())
+	}
+
+	selected, err := SelectByIDs[TestColumnItem](db, []int64{object.ID})
+	if err != nil || len(selected) != 1 {
+		t.Fatalf("could not select object by id")
+	}
+
+	if selected[0].Email != object.Email || selected[0].Name != object.Name {
+		t.Errorf("mismatch between the updated and selected objects")
+	}
+
+	rows, err := db.Delete(TestColumnItemType, "where email_address = $1", object.Email)
+	if err != nil || rows != 1 {
+		t.Errorf("could not soft delete object")
+	}
+
+	rows, err = db.HardDelete(TestColumnItemType, "where item_id = $1", object.ID)
+	if err != nil || rows != 1 {
+		t.Errorf("could not delete object")
+	}
+}
+
+func TestSkipFields(t *testing.T) {
+	err := db.CreateTable(TestSkipItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	object := &TestSkipItem{Name: "lashbits.tech", Computed: "computed"}
+	err = db.Insert(object)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	selectedObject, err := SelectOne[TestSkipItem](db, "where id = $1", object.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	if selectedObject.Name != object.Name || selectedObject.Computed != "" {
+		t.Errorf("mismatch between the inserted and selected objects - %v", selectedObject)
+	}
+
+	rows, err := db.Delete(TestSkipItemType, "where id = $1", object.ID)
+	if err != nil || rows != 1 {
+		t.Errorf("could not delete object")
+	}
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	err := db.CreateTable(TestEmbeddedItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	object := &TestEmbeddedItem{Name: "lashbits.tech", TestTimestamps: TestTimestamps{CreatedAt: now}}
+	err = db.Insert(object)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	selectedObject, err := SelectOne[TestEmbeddedItem](db, "where id = $1", object.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	if !selectedObject.CreatedAt.Equal(now) || !selectedObject.UpdatedAt.Equal(object.UpdatedAt) {
+		t.Errorf("embedded fields not selected - %v", selectedObject)
+	}
+
+	rows, err := db.Delete(TestEmbeddedItemType, "where id = $1", object.ID)
+	if err != nil || rows != 1 {
+		t.Errorf("could not delete object")
+	}
+}
+
+func TestCreateIndexes(t *testing.T) {
+	err := db.CreateTable(TestIndexedItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	err = db.CreateIndexes(TestIndexedItemType)
+	if err != nil {
+		t.Fatalf("could not create indexes - %s", err.Error())
+	}
+
+	err = db.Insert(&TestIndexedItem{Email: "contact@lashbits.tech"})
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	err = db.Insert(&TestIndexedItem{Email: "contact@lashbits.tech"})
+	if err == nil {
+		t.Errorf("unique index not enforced")
+	}
+
+	_, err = db.Delete(TestIndexedItemType, "")
+	if err != nil {
+		t.Errorf("could not delete objects - %s", err.Error())
+	}
+}
+
+func TestCreateTables(t *testing.T) {
+	err := db.CreateTables([]reflect.Type{TestBookType, TestAuthorType}, true)
+	if err != nil {
+		t.Fatalf("could not create tables - %s", err.Error())
+	}
+
+	err = db.Insert(&TestBook{AuthorID: math.MaxInt32, Title: "lashbits.tech"})
+	if err == nil {
+		t.Errorf("foreign key constraint not enforced")
+	}
+
+	author := &TestAuthor{Name: "lashbits.tech"}
+	err = db.Insert(author)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	err = db.Insert(&TestBook{AuthorID: author.ID, Title: "lashbits.tech"})
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	_, err = db.Delete(TestAuthorType, "where id = $1", author.ID)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+
+	books, err := Select[TestBook](db, "")
+	if err != nil || len(books) != 0 {
+		t.Errorf("delete not cascaded to the referencing rows")
+	}
+}
+
+func TestLowerCaseNaming(t *testing.T) {
+	lowerCaseDB := db.WithNaming(DefaultNaming{ColumnNaming: LowerCase})
+	err := lowerCaseDB.CreateTable(TestEmbeddedItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	object := &TestEmbeddedItem{Name: "lashbits.tech", TestTimestamps: TestTimestamps{CreatedAt: time.Now()}}
+	err = lowerCaseDB.Insert(object)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	_, err = SelectOne[TestEmbeddedItem](lowerCaseDB, "where createdat is not null and id = $1", object.ID)
+	if err != nil {
+		t.Errorf("could not select object by lower case column - %s", err.Error())
+	}
+
+	err = db.CreateTable(TestEmbeddedItemType, true)
+	if err != nil {
+		t.Errorf("could not recreate table - %s", err.Error())
+	}
+}
+
+func TestCountExists(t *testing.T) {
+	objects := []*TestItem{{StringColumn: "counted"}, {StringColumn: "counted"}}
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	count, err := db.Count(TestItemType, "where string_column = $1", "counted")
+	if err != nil || count != 2 {
+		t.Errorf("incorrect count of objects - %d instead of 2", count)
+	}
+
+	exists, err := db.Exists(TestItemType, "where string_column = $1", "counted")
+	if err != nil || !exists {
+		t.Errorf("objects do not exist, but they should")
+	}
+
+	rows, err := db.Delete(TestItemType, "where string_column = $1", "counted")
+	if err != nil || rows != 2 {
+		t.Errorf("could not delete objects")
+	}
+
+	exists, err = db.Exists(TestItemType, "where string_column = $1", "counted")
+	if err != nil || exists {
+		t.Errorf("objects exist after deletion")
+	}
+}
+
+func TestSave(t *testing.T) {
+	object := &TestItem{StringColumn: "saved", TimeColumn: time.Now().UTC()}
+	err := db.Save(object)
+	if err != nil {
+		t.Errorf("could not save new object - %s", err.Error())
+	}
+
+	if object.ID == 0 {
+		t.Errorf("ID field not populated after saving a new object")
+	}
+
+	object.IntColumn = 42
+	err = db.Save(object)
+	if err != nil {
+		t.Errorf("could not save existing object - %s", err.Error())
+	}
+
+	var selectedObject TestItem
+	err = db.FindByID(&selectedObject, object.ID)
+	if err != nil || selectedObject.IntColumn != 42 {
+		t.Errorf("existing object not updated by save")
+	}
+
+	rows, err := db.Delete(TestItemType, "where id = $1", object.ID)
+	if err != nil || rows != 1 {
+		t.Errorf("could not delete object")
+	}
+}
+
+func TestDeleteOne(t *testing.T) {
+	object := &TestItem{StringColumn: "deleted", TimeColumn: time.Now().UTC()}
+	err := db.Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	err = db.DeleteOne(object)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+
+	exists, err := db.Exists(TestItemType, "where id = $1", object.ID)
+	if err != nil || exists {
+		t.Errorf("object exists after deletion")
+	}
+
+	err = db.DeleteOne(object)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound when deleting a deleted object - %v", err)
+	}
+}
+
+func TestUpdateColumns(t *testing.T) {
+	object := &TestItem{StringColumn: "partial", IntColumn: 1, TimeColumn: time.Now().UTC()}
+	err := db.Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	object.StringColumn = "partially updated"
+	object.IntColumn = 2
+	err = db.UpdateColumns(object, "IntColumn")
+	if err != nil {
+		t.Errorf("could not update columns - %s", err.Error())
+	}
+
+	var selectedObject TestItem
+	err = db.FindByID(&selectedObject, object.ID)
+	if err != nil {
+		t.Errorf("could not select object - %s", err.Error())
+	}
+
+	if selectedObject.IntColumn != 2 || selectedObject.StringColumn != "partial" {
+		t.Errorf("incorrect columns updated - %v", selectedObject)
+	}
+
+	err = db.DeleteOne(object)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+}
+
+func TestUpdateWhere(t *testing.T) {
+	objects := []*TestItem{{StringColumn: "bulk", IntColumn: 1}, {StringColumn: "bulk", IntColumn: 2}}
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	rows, err := db.UpdateWhere(TestItemType, map[string]any{"IntColumn": 3, "string_column": "bulk updated"},
+		"where string_column = $1", "bulk")
+	if err != nil || rows != 2 {
+		t.Errorf("could not update objects")
+	}
+
+	count, err := db.Count(TestItemType, "where string_column = $1 and int_column = $2", "bulk updated", 3)
+	if err != nil || count != 2 {
+		t.Errorf("objects not updated - %d instead of 2", count)
+	}
+
+	_, err = db.Delete(TestItemType, "where string_column = $1", "bulk updated")
+	if err != nil {
+		t.Errorf("could not delete objects - %s", err.Error())
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	resultif, err := db.SelectColumns(TestItemType, []string{"ID", "IntColumn"}, "where id = $1", testObject.ID)
+	if err != nil {
+		t.Fatalf("could not select columns - %s", err.Error())
+	}
+
+	result := resultif.([]TestItem)
+	if len(result) != 1 {
+		t.Fatalf("incorrect amount of objects selected - %d instead of 1", len(result))
+	}
+
+	if result[0].ID != testObject.ID || result[0].IntColumn != testObject.IntColumn || result[0].StringColumn != "" {
+		t.Errorf("incorrect fields set by the projection - %v", result[0])
+	}
+}
+
+func TestQuery(t *testing.T) {
+	var objects []TestItem
+	err := db.Query(&objects, "select id, int_column from testitems where id = $1", testObject.ID)
+	if err != nil {
+		t.Fatalf("could not run query - %s", err.Error())
+	}
+
+	if len(objects) != 1 || objects[0].ID != testObject.ID || objects[0].IntColumn != testObject.IntColumn {
+		t.Errorf("incorrect objects scanned - %v", objects)
+	}
+
+	var object TestItem
+	err = db.Query(&object, "select id, int_column + 1 as int_column from testitems where id = $1", testObject.ID)
+	if err != nil {
+		t.Fatalf("could not run query - %s", err.Error())
+	}
+
+	if object.IntColumn != testObject.IntColumn+1 {
+		t.Errorf("incorrect object scanned - %v", object)
+	}
+
+	err = db.Query(&object, "select id, 1 as unknown from testitems")
+	if err == nil {
+		t.Errorf("query with an unknown column did not fail")
+	}
+}
+
+func TestQueryMaps(t *testing.T) {
+	rows, err := db.QueryMaps("select id, string_column, 1 as one from testitems where id = $1", testObject.ID)
+	if err != nil {
+		t.Fatalf("could not run query - %s", err.Error())
+	}
+
+	if len(rows) != 1 {
+		t.Fatalf("incorrect amount of rows - %d instead of 1", len(rows))
+	}
+
+	if rows[0]["id"] != testObject.ID || rows[0]["string_column"] != testObject.StringColumn || rows[0]["one"] != int32(1) {
+		t.Errorf("incorrect row values - %v", rows[0])
+	}
+}
+
+func TestReservedWords(t *testing.T) {
+	err := db.CreateTable(TestReservedItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	object := &TestReservedItem{Group: "lashbits.tech", Order: 1}
+	err = db.Insert(object)
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	object.Order = 2
+	err = db.UpdateOne(object)
+	if err != nil {
+		t.Errorf("could not update object - %s", err.Error())
+	}
+
+	selectedObject, err := SelectOne[TestReservedItem](db, `where "group" = $1`, object.Group)
+	if err != nil || selectedObject != *object {
+		t.Errorf("mismatch between the updated and selected objects")
+	}
+
+	err = db.DeleteOne(object)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+}
+
+func TestConditions(t *testing.T) {
+	objects := []*TestItem{{StringColumn: "conditions", IntColumn: 1}, {StringColumn: "conditions", IntColumn: 2},
+		{StringColumn: "conditions", IntColumn: 3}}
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	condition := And(Where("string_column", "=", "conditions"), Or(Where("int_column", "<", 2), In("int_column", []int{3})))
+	resultif, err := db.SelectWhere(TestItemType, condition)
+	if err != nil {
+		t.Fatalf("could not select objects - %s", err.Error())
+	}
+
+	if result := resultif.([]TestItem); len(result) != 2 {
+		t.Errorf("incorrect amount of objects selected - %d instead of 2", len(result))
+	}
+
+	rows, err := db.DeleteWhere(TestItemType, Where("string_column", "=", "conditions"))
+	if err != nil || rows != 3 {
+		t.Errorf("could not delete objects")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	statements := make([]string, 0)
+	logger := LoggerFunc(func(ctx context.Context, sql string, args []any, duration time.Duration, err error) {
+		statements = append(statements, sql)
+	})
+
+	object := &TestItem{StringColumn: "logger", IntColumn: 1}
+	err := db.WithLogger(logger).Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	count, err := db.WithLogger(logger).Count(TestItemType, "where string_column = $1", "logger")
+	if err != nil || count != 1 {
+		t.Errorf("could not count objects")
+	}
+
+	if len(statements) != 2 || !strings.HasPrefix(statements[0], "insert") ||
+		!strings.HasPrefix(statements[1], "select count") {
+		t.Errorf("incorrect statements logged - %v", statements)
+	}
+
+	err = db.DeleteOne(object)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+}
+
+type testSpan struct {
+	operation    string
+	tableName    string
+	statement    string
+	rowsAffected int64
+	ended        bool
+}
+
+func (s *testSpan) SetStatement(sql string)    { s.statement = sql }
+func (s *testSpan) SetRowsAffected(rows int64) { s.rowsAffected = rows }
+func (s *testSpan) End(err error)              { s.ended = true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) StartSpan(ctx context.Context, operation string, tableName string) (context.Context, Span) {
+	span := &testSpan{operation: operation, tableName: tableName}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &testTracer{}
+	tracedDB := db.WithTracer(tracer)
+
+	object := &TestItem{StringColumn: "tracer", IntColumn: 1}
+	err := tracedDB.Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	err = tracedDB.DeleteOne(object)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("incorrect amount of spans - %d instead of 2", len(tracer.spans))
+	}
+
+	insertSpan, deleteSpan := tracer.spans[0], tracer.spans[1]
+	if insertSpan.operation != "insert" || insertSpan.tableName != "testitems" || !insertSpan.ended ||
+		!strings.HasPrefix(insertSpan.statement, "insert") {
+		t.Errorf("incorrect insert span - %+v", insertSpan)
+	}
+
+	if deleteSpan.operation != "delete" || deleteSpan.rowsAffected != 1 || !deleteSpan.ended {
+		t.Errorf("incorrect delete span - %+v", deleteSpan)
+	}
+}
+
+type testMetrics struct {
+	operations []string
+	errors     int
+}
+
+func (m *testMetrics) ObserveOperation(operation string, tableName string, duration time.Duration, err error) {
+	m.operations = append(m.operations, operation+" "+tableName)
+	if err != nil {
+		m.errors++
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := &testMetrics{}
+	measuredDB := db.WithMetrics(metrics)
+
+	object := &TestItem{StringColumn: "metrics", IntColumn: 1}
+	err := measuredDB.Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	err = measuredDB.DeleteOne(object)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+
+	err = measuredDB.DeleteOne(object)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("object was deleted twice")
+	}
+
+	expected := []string{"insert testitems", "delete testitems", "delete testitems"}
+	if !reflect.DeepEqual(metrics.operations, expected) || metrics.errors != 0 {
+		t.Errorf("incorrect operations observed - %v with %d errors", metrics.operations, metrics.errors)
+	}
+}
+
+func TestDatabaseCluster(t *testing.T) {
+	connString := db.Conn.Config().ConnString()
+	cluster, err := NewDatabaseCluster(connString, []string{connString, connString}, UseTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("could not connect to cluster - %s", err.Error())
+	}
+	defer cluster.Close()
+
+	if cluster.timeout != time.Minute || cluster.replicas == nil {
+		t.Errorf("options not applied to cluster")
+	}
+
+	object := &TestItem{StringColumn: "cluster", IntColumn: 1}
+	err = cluster.Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	for i := 0; i < 2; i++ {
+		count, err := cluster.Count(TestItemType, "where string_column = $1", "cluster")
+		if err != nil || count != 1 {
+			t.Errorf("could not count objects on replica")
+		}
+	}
+
+	selectedObject := &TestItem{}
+	err = cluster.Primary().FindByID(selectedObject, object.ID)
+	if err != nil || selectedObject.ID != object.ID {
+		t.Errorf("could not select object on primary")
+	}
+
+	err = cluster.DeleteOne(object)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+}
+
+func TestWithSchema(t *testing.T) {
+	_, err := db.Conn.Exec(context.Background(), "create schema if not exists tenant_42;")
+	if err != nil {
+		t.Fatalf("could not create schema - %s", err.Error())
+	}
+	defer db.Conn.Exec(context.Background(), "drop schema tenant_42 cascade;")
+
+	tenantDB := db.WithSchema("tenant_42")
+	err = tenantDB.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	exists, err := tenantDB.TableExists(TestItemType)
+	if err != nil || !exists {
+		t.Errorf("table does not exist in schema")
+	}
+
+	err = tenantDB.Insert(&TestItem{StringColumn: "schema", IntColumn: 1})
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	count, err := db.Count(TestItemType, "where string_column = $1", "schema")
+	if err != nil || count != 0 {
+		t.Errorf("object was inserted outside of the schema")
+	}
+}
+
+func TestListen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	payloads := make(chan string, 1)
+	done := make(chan error)
+	go func() {
+		done <- db.ListenCtx(ctx, "liteorm_test", func(payload string) {
+			select {
+			case payloads <- payload:
+			default:
+			}
+		})
+	}()
+
+	// the notification is sent until the listener has subscribed and received it
+	var payload string
+	for payload == "" {
+		err := db.Notify("liteorm_test", "lashbits.tech")
+		if err != nil {
+			t.Fatalf("could not notify channel - %s", err.Error())
+		}
+
+		select {
+		case payload = <-payloads:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	if payload != "lashbits.tech" {
+		t.Errorf("incorrect payload - %s", payload)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("could not listen to channel - %s", err.Error())
+	}
+}
+
+func TestNestedTransaction(t *testing.T) {
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("could not begin transaction - %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	err = tx.Insert(&TestItem{StringColumn: "nested", IntColumn: 1})
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	nested, err := tx.Begin()
+	if err != nil {
+		t.Fatalf("could not begin nested transaction - %s", err.Error())
+	}
+
+	err = nested.Insert(&TestItem{StringColumn: "nested", IntColumn: 2})
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	err = nested.Rollback()
+	if err != nil {
+		t.Fatalf("could not rollback nested transaction - %s", err.Error())
+	}
+
+	count, err := tx.Count(TestItemType, "where string_column = $1", "nested")
+	if err != nil || count != 1 {
+		t.Errorf("nested transaction was not rolled back")
+	}
+}
+
+func TestRunInTransaction(t *testing.T) {
+	errRollback := errors.New("rollback")
+	err := db.RunInTransaction(func(tx *Tx) error {
+		err := tx.Insert(&TestItem{StringColumn: "closure", IntColumn: 1})
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != errRollback {
+		t.Errorf("incorrect error returned - %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("panic was not propagated")
+			}
+		}()
+
+		_ = db.RunInTransaction(func(tx *Tx) error {
+			_ = tx.Insert(&TestItem{StringColumn: "closure", IntColumn: 2})
+			panic("rollback")
+		})
+	}()
+
+	err = db.RunInTransaction(func(tx *Tx) error {
+		return tx.Insert(&TestItem{StringColumn: "closure", IntColumn: 3})
+	})
+	if err != nil {
+		t.Fatalf("could not run transaction - %s", err.Error())
+	}
+
+	rows, err := db.Delete(TestItemType, "where string_column = $1", "closure")
+	if err != nil || rows != 1 {
+		t.Errorf("transactions were not committed or rolled back as expected")
+	}
+}
+
+func TestAdvisoryLock(t *testing.T) {
+	err := db.AdvisoryLock(42)
+	if err != nil {
+		t.Fatalf("could not acquire lock - %s", err.Error())
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("could not begin transaction - %s", err.Error())
+	}
+
+	// advisory locks are reentrant within a session, so the transaction acquires the lock again
+	locked, err := tx.TryAdvisoryLock(42)
+	if err != nil || !locked {
+		t.Errorf("could not acquire lock within transaction")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		t.Errorf("could not commit transaction - %s", err.Error())
+	}
+
+	err = db.AdvisoryUnlock(42)
+	if err != nil {
+		t.Errorf("could not release lock - %s", err.Error())
+	}
+
+	err = db.AdvisoryUnlock(42)
+	if err == nil {
+		t.Errorf("lock was released twice")
+	}
+}
+
+func TestTruncateAndDropTable(t *testing.T) {
+	err := db.CreateTable(TestReservedItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	err = db.Insert(&TestReservedItem{Group: "lashbits.tech", Order: 1})
+	if err != nil {
+		t.Errorf("could not insert object - %s", err.Error())
+	}
+
+	err = db.Truncate(TestReservedItemType, true, false)
+	if err != nil {
+		t.Errorf("could not truncate table - %s", err.Error())
+	}
+
+	count, err := db.Count(TestReservedItemType, "")
+	if err != nil || count != 0 {
+		t.Errorf("table was not truncated")
+	}
+
+	err = db.DropTable(TestReservedItemType, false)
+	if err != nil {
+		t.Errorf("could not drop table - %s", err.Error())
+	}
+
+	err = db.DropTable(TestReservedItemType, true)
+	if err != nil {
+		t.Errorf("could not drop missing table - %s", err.Error())
+	}
+}
+
+func TestInspectTable(t *testing.T) {
+	info, err := db.InspectTable("testitems")
+	if err != nil {
+		t.Fatalf("could not inspect table - %s", err.Error())
+	}
+
+	column, ok := info.Column("string_column")
+	if !ok || column.Type != "varchar(25)" || !column.Nullable {
+		t.Errorf("incorrect column info - %+v", column)
+	}
+
+	column, ok = info.Column("id")
+	if !ok || column.Nullable || !strings.HasPrefix(column.Default, "nextval") {
+		t.Errorf("incorrect id column info - %+v", column)
+	}
+
+	if len(info.Constraints) == 0 || info.Constraints[0].Type != "primary key" {
+		t.Errorf("incorrect constraints - %+v", info.Constraints)
+	}
+
+	if len(info.Indexes) == 0 || !info.Indexes[0].Primary {
+		t.Errorf("incorrect indexes - %+v", info.Indexes)
+	}
+
+	_, err = db.InspectTable("missingtable")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing table was inspected")
+	}
+}
+
+func TestValidateModels(t *testing.T) {
+	err := db.ValidateModels(TestItemType, TestUUIDItemType)
+	if err != nil {
+		t.Errorf("could not validate models - %s", err.Error())
+	}
+
+	var validationErr *ValidationError
+	err = db.WithTablePrefix("missing_").ValidateModels(TestItemType)
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 {
+		t.Errorf("missing table was not reported")
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"testusers.yml":   "fixture_user:\n  Name: fixtures\n",
+		"testorders.json": `{"fixture_order": {"test_user_id": "$fixture_user", "Amount": 5}}`,
+	}
+	for name, content := range files {
+		err := os.WriteFile(dir+"/"+name, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("could not write fixture file - %s", err.Error())
+		}
+	}
+
+	err := db.LoadFixtures(dir, TestUserType, TestOrderType)
+	if err != nil {
+		t.Fatalf("could not load fixtures - %s", err.Error())
+	}
+
+	user, err := SelectOne[TestUser](db, "where name = $1", "fixtures")
+	if err != nil {
+		t.Fatalf("could not select user - %s", err.Error())
+	}
+
+	orders, err := Select[TestOrder](db, "where test_user_id = $1", user.ID)
+	if err != nil || len(orders) != 1 || orders[0].Amount != 5 {
+		t.Errorf("order fixture does not reference user fixture")
+	}
+
+	db.Delete(TestOrderType, "where test_user_id = $1", user.ID)
+	db.Delete(TestUserType, "where id = $1", user.ID)
+}
+
+func TestNewDatabaseFromExecutor(t *testing.T) {
+	fromExecutor := NewDatabaseFromExecutor(db.Conn)
+
+	_, err := fromExecutor.Count(TestItemType, "")
+	if err != nil {
+		t.Errorf("could not count objects - %s", err.Error())
+	}
+
+	tx, err := fromExecutor.Begin()
+	if err != nil {
+		t.Fatalf("could not begin transaction - %s", err.Error())
+	}
+	tx.Rollback()
+
+	// an executor with only Exec, Query and QueryRow can neither start transactions nor send batches
+	basic := NewDatabaseFromExecutor(struct{ Executor }{db.Conn})
+	_, err = basic.Begin()
+	if err == nil {
+		t.Errorf("transaction was started on an executor without Begin")
+	}
+
+	err = basic.InsertMany([]*TestItem{{StringColumn: "batch"}})
+	if !errors.Is(err, errUnsupportedByExecutor) {
+		t.Errorf("batch was sent on an executor without SendBatch")
+	}
+}
+
+func TestNewDatabaseFromSQL(t *testing.T) {
+	sqlDB := stdlib.OpenDB(*db.Conn.Config())
+	defer sqlDB.Close()
+	fromSQL := NewDatabaseFromSQL(sqlDB)
+
+	item := &TestItem{StringColumn: "database/sql", IntColumn: 7, TimeColumn: time.Now().UTC().Truncate(time.Second)}
+	err := fromSQL.Insert(item)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	selected := &TestItem{}
+	err = fromSQL.FindByID(selected, item.ID)
+	if err != nil || selected.IntColumn != 7 {
+		t.Errorf("could not find inserted object")
+	}
+
+	err = fromSQL.RunInTransaction(func(tx *Tx) error {
+		item.IntColumn = 8
+		return tx.UpdateOne(item)
+	})
+	if err != nil {
+		t.Errorf("could not update object in transaction - %s", err.Error())
+	}
+
+	err = fromSQL.DeleteOne(item)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+
+	err = fromSQL.FindByID(selected, item.ID)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("deleted object was found")
+	}
+}
+
+func TestUpdateMany(t *testing.T) {
+	objects := []TestItem{
+		{StringColumn: "update many", IntColumn: 1, TimeColumn: time.Now().UTC()},
+		{StringColumn: "update many", IntColumn: 2, TimeColumn: time.Now().UTC()},
+	}
+
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	for i := range objects {
+		objects[i].IntColumn *= 10
+	}
+
+	err = db.UpdateMany(objects)
+	if err != nil {
+		t.Fatalf("could not update objects - %s", err.Error())
+	}
+
+	for _, object := range objects {
+		var selectedTestObject TestItem
+		err = db.FindByID(&selectedTestObject, object.ID)
+		if err != nil || selectedTestObject.IntColumn != object.IntColumn {
+			t.Errorf("object was not updated")
+		}
+	}
+
+	missing := TestItem{ID: -1, TimeColumn: time.Now().UTC()}
+	err = db.UpdateMany([]*TestItem{&objects[0], &missing})
+	if err == nil {
+		t.Errorf("update of missing object did not fail")
+	}
+
+	db.Delete(TestItemType, "where string_column = $1", "update many")
+}
+
+func TestDeleteByIDs(t *testing.T) {
+	objects := []TestItem{
+		{StringColumn: "delete by ids", TimeColumn: time.Now().UTC()},
+		{StringColumn: "delete by ids", TimeColumn: time.Now().UTC()},
+		{StringColumn: "delete by ids", TimeColumn: time.Now().UTC()},
+	}
+
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	deleted, err := db.DeleteByIDs(TestItemType, []int64{objects[0].ID, objects[2].ID, -1})
+	if err != nil || deleted != 2 {
+		t.Errorf("incorrect number of deleted objects - %d instead of 2", deleted)
+	}
+
+	remaining, err := db.Count(TestItemType, "where string_column = $1", "delete by ids")
+	if err != nil || remaining != 1 {
+		t.Errorf("incorrect number of remaining objects - %d instead of 1", remaining)
+	}
+
+	db.Delete(TestItemType, "where string_column = $1", "delete by ids")
+}
+
+type TestGeneratedItem struct {
+	ID        int64     `pgsql:"primary key"`
+	Amount    int       `pgsql:"not null"`
+	Total     int       `pgsql:"generated always as (amount * 2) stored" liteorm:"generated"`
+	CreatedAt time.Time `pgsql:"not null default now()" liteorm:"generated"`
+}
+
+var TestGeneratedItemType reflect.Type = reflect.TypeOf((*TestGeneratedItem)(nil)).Elem()
+
+func TestInsertGeneratedFields(t *testing.T) {
+	err := db.CreateTable(TestGeneratedItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	item := &TestGeneratedItem{Amount: 21}
+	err = db.Insert(item)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	if item.Total != 42 || item.CreatedAt.IsZero() {
+		t.Errorf("generated fields were not set on insert")
+	}
+
+	item.Amount = 5
+	err = db.UpdateOne(item)
+	if err != nil {
+		t.Fatalf("could not update object - %s", err.Error())
+	}
+
+	db.DropTable(TestGeneratedItemType, true)
+}
+
+func TestAggregate(t *testing.T) {
+	objects := []TestItem{
+		{StringColumn: "aggregate", IntColumn: 1, TimeColumn: time.Now().UTC()},
+		{StringColumn: "aggregate", IntColumn: 2, TimeColumn: time.Now().UTC()},
+		{StringColumn: "aggregate", IntColumn: 6, TimeColumn: time.Now().UTC()},
+	}
+
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	clauses := "where string_column = $1"
+	if sum, err := db.Sum(TestItemType, "IntColumn", clauses, "aggregate"); err != nil || sum != 9 {
+		t.Errorf("incorrect sum - %f instead of 9", sum)
+	}
+
+	if avg, err := db.Avg(TestItemType, "int_column", clauses, "aggregate"); err != nil || avg != 3 {
+		t.Errorf("incorrect average - %f instead of 3", avg)
+	}
+
+	if min, err := db.Min(TestItemType, "IntColumn", clauses, "aggregate"); err != nil || min != 1 {
+		t.Errorf("incorrect minimum - %f instead of 1", min)
+	}
+
+	if max, err := db.Max(TestItemType, "IntColumn", clauses, "aggregate"); err != nil || max != 6 {
+		t.Errorf("incorrect maximum - %f instead of 6", max)
+	}
+
+	if sum, err := db.Sum(TestItemType, "IntColumn", clauses, "missing"); err != nil || sum != 0 {
+		t.Errorf("incorrect sum of no rows - %f instead of 0", sum)
+	}
+
+	_, err = db.Aggregate(TestItemType, "stddev", "IntColumn", clauses, "aggregate")
+	if err == nil {
+		t.Errorf("unsupported aggregate function did not fail")
+	}
+
+	db.Delete(TestItemType, clauses, "aggregate")
+}
+
+type TestItemGroup struct {
+	StringColumn string
+	Total        int64
+	IntSum       int64
+}
+
+func TestSelectInto(t *testing.T) {
+	objects := []TestItem{
+		{StringColumn: "group a", IntColumn: 1, TimeColumn: time.Now().UTC()},
+		{StringColumn: "group a", IntColumn: 2, TimeColumn: time.Now().UTC()},
+		{StringColumn: "group b", IntColumn: 3, TimeColumn: time.Now().UTC()},
+	}
+
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	var groups []TestItemGroup
+	q := NewQuery().
+		Where("string_column like ?", "group %").
+		GroupBy("string_column").
+		Having("count(*) > ?", 1)
+	err = db.SelectInto(&groups, TestItemType, "string_column, count(*) as total, sum(int_column) as int_sum", q)
+	if err != nil {
+		t.Fatalf("could not select groups - %s", err.Error())
+	}
+
+	if len(groups) != 1 || groups[0].StringColumn != "group a" || groups[0].Total != 2 || groups[0].IntSum != 3 {
+		t.Errorf("incorrect groups selected - %v", groups)
+	}
+
+	db.Delete(TestItemType, "where string_column like $1", "group %")
+}
+
+func TestSelectQueryWith(t *testing.T) {
+	objects := []TestItem{
+		{StringColumn: "with", IntColumn: 1, TimeColumn: time.Now().UTC()},
+		{StringColumn: "with", IntColumn: 3, TimeColumn: time.Now().UTC()},
+		{StringColumn: "with", IntColumn: 5, TimeColumn: time.Now().UTC()},
+	}
+
+	err := db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	numbers := NewQuery().
+		Select("n").
+		From("(values (1)) as seed(n)").
+		UnionAll(NewQuery().Select("n + 1").From("numbers").Where("n < ?", 3))
+	q := NewQuery().
+		WithRecursive("numbers", numbers).
+		Where("string_column = ?", "with").
+		Where("int_column in (select n from numbers)").
+		OrderBy("int_column")
+
+	var result []TestItem
+	if resultif, err := db.SelectQuery(TestItemType, q); err == nil {
+		result = resultif.([]TestItem)
+	} else {
+		t.Fatalf("could not select objects - %s", err.Error())
+	}
+
+	if len(result) != 2 || result[0].IntColumn != 1 || result[1].IntColumn != 3 {
+		t.Errorf("incorrect objects selected - %v", result)
+	}
+
+	db.Delete(TestItemType, "where string_column = $1", "with")
+}
+
+type TestArticle struct {
+	ID     int64
+	Title  string `pglen:"200" fts:"english"`
+	Body   string `pglen:"2000" fts:"english"`
+	Search TSVector
+}
+
+var TestArticleType reflect.Type = reflect.TypeOf((*TestArticle)(nil)).Elem()
+
+func TestSearch(t *testing.T) {
+	err := db.CreateTable(TestArticleType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	articles := []TestArticle{
+		{Title: "Cooking pasta", Body: "Boil the water, then add the pasta."},
+		{Title: "Boiling water", Body: "Water boils at one hundred degrees, which is useful when cooking."},
+		{Title: "Gardening", Body: "Tomatoes need plenty of water."},
+	}
+	err = db.InsertMany(articles)
+	if err != nil {
+		t.Fatalf("could not insert articles - %s", err.Error())
+	}
+
+	if articles[0].Search == "" {
+		t.Errorf("search column not set from the inserted row")
+	}
+
+	var result []TestArticle
+	if resultif, err := db.Search(TestArticleType, "boiling water"); err == nil {
+		result = resultif.([]TestArticle)
+	} else {
+		t.Fatalf("could not search articles - %s", err.Error())
+	}
+
+	if len(result) != 2 || result[0].Title != "Boiling water" || result[1].Title != "Cooking pasta" {
+		t.Errorf("incorrect articles found - %v", result)
+	}
+
+	_, err = db.Search(TestItemType, "water")
+	if err == nil {
+		t.Errorf("expected error on search of a type without a search field")
+	}
+
+	db.DropTable(TestArticleType, true)
+}
+
+type TestPostStatus string
+
+func (TestPostStatus) EnumValues() []string {
+	return []string{"draft", "published"}
+}
+
+type TestPost struct {
+	ID             int64
+	Title          string `pglen:"100"`
+	Status         TestPostStatus
+	PreviousStatus *TestPostStatus
+}
+
+var TestPostType reflect.Type = reflect.TypeOf((*TestPost)(nil)).Elem()
+
+func TestEnums(t *testing.T) {
+	db.DropTable(TestPostType, true)
+	db.Conn.Exec(context.Background(), "drop type if exists test_post_status;")
+
+	err := db.CreateTable(TestPostType, false)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	// the existing enum type is reused
+	err = db.CreateTable(TestPostType, true)
+	if err != nil {
+		t.Fatalf("could not recreate table - %s", err.Error())
+	}
+
+	post := &TestPost{Title: "enums", Status: "draft"}
+	err = db.Insert(post)
+	if err != nil {
+		t.Fatalf("could not insert post - %s", err.Error())
+	}
+
+	draft := post.Status
+	post.Status, post.PreviousStatus = "published", &draft
+	err = db.UpdateOne(post)
+	if err != nil {
+		t.Fatalf("could not update post - %s", err.Error())
+	}
+
+	var result []TestPost
+	if resultif, err := db.Select(TestPostType, "where id = $1", post.ID); err == nil {
+		result = resultif.([]TestPost)
+	} else {
+		t.Fatalf("could not select posts - %s", err.Error())
+	}
+
+	if len(result) != 1 || result[0].Status != "published" || result[0].PreviousStatus == nil ||
+		*result[0].PreviousStatus != "draft" {
+		t.Errorf("incorrect post selected - %v", result)
+	}
+
+	post.Status = "deleted"
+	err = db.UpdateOne(post)
+	if err == nil {
+		t.Errorf("expected error on update with a value that is not part of the enum")
+	}
+
+	db.DropTable(TestPostType, true)
+	db.Conn.Exec(context.Background(), "drop type if exists test_post_status;")
+}
+
+type TestInvoice struct {
+	ID    int64
+	Total pgtype.Numeric `numeric:"12,2"`
+}
+
+var TestInvoiceType reflect.Type = reflect.TypeOf((*TestInvoice)(nil)).Elem()
+
+func TestNumeric(t *testing.T) {
+	err := db.CreateTable(TestInvoiceType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	invoice := &TestInvoice{}
+	invoice.Total.Scan("1234.567")
+	err = db.Insert(invoice)
+	if err != nil {
+		t.Fatalf("could not insert invoice - %s", err.Error())
+	}
+
+	result := &TestInvoice{}
+	err = db.FindByID(result, invoice.ID)
+	if err != nil {
+		t.Fatalf("could not find invoice - %s", err.Error())
+	}
+
+	// the value is rounded to the scale of the column
+	total, _ := result.Total.Float64Value()
+	if total.Float64 != 1234.57 {
+		t.Errorf("incorrect total of invoice - %f", total.Float64)
+	}
+
+	db.DropTable(TestInvoiceType, true)
+}
+
+type TestVisit struct {
+	ID       int64
+	ClientIP net.IP
+	Network  *net.IPNet
+	Addr     netip.Addr
+	Prefix   *netip.Prefix
+}
+
+var TestVisitType reflect.Type = reflect.TypeOf((*TestVisit)(nil)).Elem()
+
+func TestNetworkFields(t *testing.T) {
+	err := db.CreateTable(TestVisitType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	_, network, _ := net.ParseCIDR("192.168.0.0/24")
+	visit := &TestVisit{ClientIP: net.ParseIP("192.168.0.10"), Network: network,
+		Addr: netip.MustParseAddr("2001:db8::1")}
+	err = db.Insert(visit)
+	if err != nil {
+		t.Fatalf("could not insert visit - %s", err.Error())
+	}
+
+	result := &TestVisit{}
+	err = db.FindByID(result, visit.ID)
+	if err != nil {
+		t.Fatalf("could not find visit - %s", err.Error())
+	}
+
+	if !result.ClientIP.Equal(visit.ClientIP) || result.Network == nil || result.Network.String() != "192.168.0.0/24" ||
+		result.Addr != visit.Addr || result.Prefix != nil {
+		t.Errorf("incorrect visit found - %v", result)
+	}
+
+	db.DropTable(TestVisitType, true)
+}
+
+type TestSchedule struct {
+	ID        int64
+	Retention time.Duration
+	Timeout   *time.Duration
+	Elapsed   time.Duration `liteorm:"microseconds"`
+}
+
+var TestScheduleType reflect.Type = reflect.TypeOf((*TestSchedule)(nil)).Elem()
+
+func TestDurationFields(t *testing.T) {
+	err := db.CreateTable(TestScheduleType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	schedule := &TestSchedule{Retention: 30 * 24 * time.Hour, Elapsed: 1500 * time.Millisecond}
+	err = db.Insert(schedule)
+	if err != nil {
+		t.Fatalf("could not insert schedule - %s", err.Error())
+	}
+
+	result := &TestSchedule{}
+	err = db.FindByID(result, schedule.ID)
+	if err != nil {
+		t.Fatalf("could not find schedule - %s", err.Error())
+	}
+
+	if result.Retention != schedule.Retention || result.Timeout != nil || result.Elapsed != schedule.Elapsed {
+		t.Errorf("incorrect schedule found - %v", result)
+	}
+
+	count, err := db.Count(TestScheduleType, "where retention > interval '7 days'")
+	if err != nil || count != 1 {
+		t.Errorf("could not compare interval column - %d, %v", count, err)
+	}
+
+	db.DropTable(TestScheduleType, true)
+}
+
+type TestMeeting struct {
+	ID       int64
+	StartsAt Timestamp
+	EndsAt   *Timestamp
+}
+
+var TestMeetingType reflect.Type = reflect.TypeOf((*TestMeeting)(nil)).Elem()
+
+func TestNamedTimeFields(t *testing.T) {
+	err := db.CreateTable(TestMeetingType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	startsAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	meeting := &TestMeeting{StartsAt: Timestamp(startsAt)}
+	err = db.Insert(meeting)
+	if err != nil {
+		t.Fatalf("could not insert meeting - %s", err.Error())
+	}
+
+	result := &TestMeeting{}
+	err = db.FindByID(result, meeting.ID)
+	if err != nil {
+		t.Fatalf("could not find meeting - %s", err.Error())
+	}
+
+	if !time.Time(result.StartsAt).Equal(startsAt) || result.EndsAt != nil {
+		t.Errorf("incorrect meeting found - %v", result)
+	}
+
+	endsAt := Timestamp(startsAt.Add(time.Hour))
+	result.EndsAt = &endsAt
+	err = db.UpdateOne(result)
+	if err != nil {
+		t.Fatalf("could not update meeting - %s", err.Error())
+	}
+
+	var meetings []TestMeeting
+	err = db.Query(&meetings, `select * from "testmeetings" where "ends_at" > $1;`, startsAt)
+	if err != nil || len(meetings) != 1 || meetings[0].EndsAt == nil ||
+		!time.Time(*meetings[0].EndsAt).Equal(time.Time(endsAt)) {
+		t.Errorf("incorrect meetings queried - %v, %v", meetings, err)
+	}
+
+	db.DropTable(TestMeetingType, true)
+}
+
+type TestCustomer struct {
+	ID    int64
+	Name  string  `pglen:"100"`
+	Email string  `encrypt:"aes"`
+	Phone *string `encrypt:"aes"`
+}
+
+var TestCustomerType reflect.Type = reflect.TypeOf((*TestCustomer)(nil)).Elem()
+
+func TestEncryptedFields(t *testing.T) {
+	encrypted := db.WithKeyProvider(StaticKey(bytes.Repeat([]byte{7}, 32)))
+	err := encrypted.CreateTable(TestCustomerType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	customer := &TestCustomer{Name: "encrypted", Email: "someone@example.com"}
+	err = encrypted.Insert(customer)
+	if err != nil {
+		t.Fatalf("could not insert customer - %s", err.Error())
+	}
+
+	if customer.Email != "someone@example.com" {
+		t.Errorf("email not decrypted after insert - %s", customer.Email)
+	}
+
+	var stored []byte
+	err = db.Conn.QueryRow(context.Background(), "select email from testcustomers where id = $1",
+		customer.ID).Scan(&stored)
+	if err != nil || bytes.Contains(stored, []byte("someone")) {
+		t.Errorf("email not encrypted at rest - %v", err)
+	}
+
+	result := &TestCustomer{}
+	err = encrypted.FindByID(result, customer.ID)
+	if err != nil {
+		t.Fatalf("could not find customer - %s", err.Error())
+	}
+
+	if result.Email != customer.Email || result.Phone != nil {
+		t.Errorf("incorrect customer found - %v", result)
+	}
+
+	err = db.FindByID(result, customer.ID)
+	if err == nil {
+		t.Errorf("expected error on select of encrypted fields without a key provider")
+	}
+
+	err = db.Insert(&TestCustomer{Name: "not encrypted", Email: "someone@example.com"})
+	if err == nil {
+		t.Errorf("expected error on insert of encrypted fields without a key provider")
+	}
+
+	db.DropTable(TestCustomerType, true)
+}
+
+func TestAutomaticTimestamps(t *testing.T) {
+	err := db.CreateTable(TestEmbeddedItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	object := &TestEmbeddedItem{Name: "lashbits.tech"}
+	err = db.Insert(object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	if object.CreatedAt.IsZero() || !object.UpdatedAt.Equal(object.CreatedAt) {
+		t.Errorf("timestamps not set on insert - %v", object)
+	}
+
+	createdAt := object.CreatedAt
+	time.Sleep(time.Millisecond)
+	err = db.UpdateOne(object)
+	if err != nil {
+		t.Fatalf("could not update object - %s", err.Error())
+	}
+
+	if !object.CreatedAt.Equal(createdAt) || !object.UpdatedAt.After(createdAt) {
+		t.Errorf("timestamps not set on update - %v", object)
+	}
+
+	databaseObject := &TestEmbeddedItem{Name: "lashbits.tech"}
+	err = db.WithDatabaseTimestamps().Insert(databaseObject)
+	if err != nil {
+		t.Fatalf("could not insert object with database timestamps - %s", err.Error())
+	}
+
+	if databaseObject.CreatedAt.IsZero() || databaseObject.UpdatedAt.IsZero() {
+		t.Errorf("database timestamps not returned on insert - %v", databaseObject)
+	}
+
+	err = db.WithDatabaseTimestamps().UpdateColumns(databaseObject, "Name")
+	if err != nil {
+		t.Fatalf("could not update object with database timestamps - %s", err.Error())
+	}
+
+	selectedObject, err := SelectOne[TestEmbeddedItem](db, "where id = $1", databaseObject.ID)
+	if err != nil {
+		t.Fatalf("could not select object - %s", err.Error())
+	}
+
+	if !selectedObject.CreatedAt.Equal(databaseObject.CreatedAt) || selectedObject.UpdatedAt.IsZero() {
+		t.Errorf("database timestamps not set on update - %v", selectedObject)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	err := db.CreateTable(reflect.TypeOf(AuditLog{}), true)
+	if err != nil {
+		t.Fatalf("could not create audit log table - %s", err.Error())
+	}
+
+	err = db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	auditedDB := db.WithAudit()
+	ctx := WithAuditActor(context.Background(), "lashbits")
+	object := &TestItem{StringColumn: "audited", IntColumn: 1}
+	err = auditedDB.InsertCtx(ctx, object)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	object.IntColumn = 2
+	err = auditedDB.UpdateOneCtx(ctx, object)
+	if err != nil {
+		t.Fatalf("could not update object - %s", err.Error())
+	}
+
+	_, err = auditedDB.DeleteCtx(ctx, TestItemType, "where id = $1", object.ID)
+	if err != nil {
+		t.Fatalf("could not delete object - %s", err.Error())
+	}
+
+	entries, err := Select[AuditLog](db, "order by id")
+	if err != nil {
+		t.Fatalf("could not select audit log - %s", err.Error())
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("incorrect number of audit log rows - %d", len(entries))
+	}
+
+	rowID := fmt.Sprint(object.ID)
+	for i, operation := range []string{AuditInsert, AuditUpdate, AuditDelete} {
+		entry := entries[i]
+		if entry.Table != "testitems" || entry.RowID != rowID || entry.Operation != operation ||
+			entry.Actor != "lashbits" || entry.CreatedAt.IsZero() {
+			t.Errorf("incorrect audit log row - %v", entry)
+		}
+	}
+
+	if entries[0].OldValues != nil || entries[0].NewValues["int_column"] != float64(1) {
+		t.Errorf("incorrect insert values - %v", entries[0])
+	}
+
+	if entries[1].OldValues["int_column"] != float64(1) || entries[1].NewValues["int_column"] != float64(2) {
+		t.Errorf("incorrect update values - %v", entries[1])
+	}
+
+	if entries[2].OldValues["int_column"] != float64(2) || entries[2].NewValues != nil {
+		t.Errorf("incorrect delete values - %v", entries[2])
+	}
+
+	// the change is rolled back along with its audit log row
+	err = auditedDB.RunInTransaction(func(tx *Tx) error {
+		err := tx.Insert(&TestItem{StringColumn: "rolled back"})
+		if err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	if err == nil {
+		t.Errorf("transaction not rolled back")
+	}
+
+	count, err := db.Count(reflect.TypeOf(AuditLog{}), "")
+	if err != nil || count != 3 {
+		t.Errorf("audit log row of a rolled back change kept - %d", count)
+	}
+}
+
+func TestAuditBulkOperations(t *testing.T) {
+	err := db.CreateTable(reflect.TypeOf(AuditLog{}), true)
+	if err != nil {
+		t.Fatalf("could not create audit log table - %s", err.Error())
+	}
+
+	err = db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	err = db.CreateTable(TestUpsertItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	auditedDB := db.WithAudit()
+	objects := []TestItem{{StringColumn: "first", IntColumn: 1}, {StringColumn: "second", IntColumn: 1}}
+	err = auditedDB.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	objects[0].IntColumn = 2
+	objects[1].IntColumn = 2
+	err = auditedDB.UpdateMany(objects)
+	if err != nil {
+		t.Fatalf("could not update objects - %s", err.Error())
+	}
+
+	_, err = auditedDB.UpdateWhere(TestItemType, map[string]any{"IntColumn": 3}, "where int_column = $1", 2)
+	if err != nil {
+		t.Fatalf("could not update objects - %s", err.Error())
+	}
+
+	err = auditedDB.Upsert(&TestUpsertItem{KeyColumn: "lashbits.tech", ValueColumn: 1}, "KeyColumn")
+	if err == nil {
+		err = auditedDB.Upsert(&TestUpsertItem{KeyColumn: "lashbits.tech", ValueColumn: 2}, "KeyColumn")
+	}
+	if err != nil {
+		t.Fatalf("could not upsert object - %s", err.Error())
+	}
+
+	entries, err := Select[AuditLog](db, "order by id")
+	if err != nil {
+		t.Fatalf("could not select audit log - %s", err.Error())
+	}
+
+	operations := []string{AuditInsert, AuditInsert, AuditUpdate, AuditUpdate, AuditUpdate, AuditUpdate, AuditInsert,
+		AuditUpdate}
+	if len(entries) != len(operations) {
+		t.Fatalf("incorrect number of audit log rows - %d", len(entries))
+	}
+
+	for i, operation := range operations {
+		if entries[i].Operation != operation {
+			t.Errorf("incorrect audit log operation - %s instead of %s", entries[i].Operation, operation)
+		}
+	}
+
+	for _, entry := range entries[4:6] {
+		if entry.OldValues["int_column"] != float64(2) || entry.NewValues["int_column"] != float64(3) {
+			t.Errorf("incorrect update values - %v", entry)
+		}
+	}
+
+	if entries[7].OldValues["value_column"] != float64(1) || entries[7].NewValues["value_column"] != float64(2) {
+		t.Errorf("incorrect upsert values - %v", entries[7])
+	}
+
+	// copies cannot be audited
+	_, err = auditedDB.CopyFrom([]TestItem{{StringColumn: "copied"}})
+	if err == nil {
+		t.Errorf("copy on an audited database did not fail")
+	}
+}
+
+func TestTenantScope(t *testing.T) {
+	err := db.CreateTable(TestTenantItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	firstDB := db.Scoped(WithTenant(context.Background(), int64(1)))
+	secondDB := db.Scoped(WithTenant(context.Background(), int64(2)))
+	object := &TestTenantItem{Name: "first"}
+	err = firstDB.Insert(object)
+	if err != nil || object.TenantID != 1 {
+		t.Fatalf("could not insert object - %v", err)
+	}
+
+	err = secondDB.Insert(&TestTenantItem{Name: "second"})
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	objects, err := Select[TestTenantItem](firstDB, "")
+	if err != nil || len(objects) != 1 || objects[0].Name != "first" {
+		t.Errorf("rows of other tenants selected - %v", objects)
+	}
+
+	object.Name = "stolen"
+	err = secondDB.UpdateOne(object)
+	if err == nil {
+		t.Errorf("row of another tenant updated")
+	}
+
+	rows, err := secondDB.Delete(TestTenantItemType, "")
+	if err != nil || rows != 1 {
+		t.Errorf("incorrect number of rows deleted - %d", rows)
+	}
+
+	count, err := db.Count(TestTenantItemType, "")
+	if err != nil || count != 1 {
+		t.Errorf("rows of other tenants deleted - %d", count)
+	}
+
+	count, err = db.Scoped(context.Background()).Count(TestTenantItemType, "")
+	if err != nil || count != 0 {
+		t.Errorf("rows selected without tenant - %d", count)
+	}
+}
+
+func TestPartitions(t *testing.T) {
+	err := db.CreateTable(TestEventType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	err = db.EnsureMonthlyPartitions(TestEventType, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), 2)
+	if err != nil {
+		t.Fatalf("could not create partitions - %s", err.Error())
+	}
+
+	// existing partitions are left unchanged
+	err = db.EnsurePartition(TestEventType, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Errorf("could not ensure existing partition - %s", err.Error())
+	}
+
+	event := &TestEvent{Name: "partitioned", CreatedAt: time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)}
+	err = db.Insert(event)
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	var count int64
+	err = db.Conn.QueryRow(context.Background(), `select count(*) from "testevents_20240201"`).Scan(&count)
+	if err != nil || count != 1 {
+		t.Errorf("row not stored in its partition - %d", count)
+	}
+
+	err = db.Insert(&TestEvent{Name: "unpartitioned", CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err == nil {
+		t.Errorf("row inserted without a partition")
+	}
+}
+
+func TestMaterializedView(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	err = db.CreateTable(TestItemSummaryType, true)
+	if err != nil {
+		t.Fatalf("could not create materialized view - %s", err.Error())
+	}
+
+	err = db.CreateIndexes(TestItemSummaryType)
+	if err != nil {
+		t.Fatalf("could not create materialized view indexes - %s", err.Error())
+	}
+
+	err = db.InsertMany([]*TestItem{{IntColumn: 1}, {IntColumn: 1}, {IntColumn: 2}})
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	summaries, err := Select[TestItemSummary](db, "")
+	if err != nil || len(summaries) != 0 {
+		t.Errorf("materialized view refreshed before RefreshMaterializedView - %v", summaries)
+	}
+
+	err = db.RefreshMaterializedView(TestItemSummaryType, true)
+	if err != nil {
+		t.Fatalf("could not refresh materialized view - %s", err.Error())
+	}
+
+	summaries, err = Select[TestItemSummary](db, "order by int_column")
+	if err != nil || len(summaries) != 2 || summaries[0].Total != 2 || summaries[1].Total != 1 {
+		t.Errorf("incorrect materialized view rows - %v", summaries)
+	}
+
+	err = db.Insert(&TestItemSummary{IntColumn: 3, Total: 1})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("materialized view row inserted - %v", err)
+	}
+
+	_, err = db.Delete(TestItemSummaryType, "")
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("materialized view rows deleted - %v", err)
+	}
+
+	err = db.DropTable(TestItemSummaryType, false)
+	if err != nil {
+		t.Errorf("could not drop materialized view - %s", err.Error())
+	}
+}
+
+func TestView(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	err = db.CreateTable(TestItemViewType, true)
+	if err != nil {
+		t.Fatalf("could not create view - %s", err.Error())
+	}
+
+	// views are replaced rather than recreated
+	err = db.AutoMigrate(TestItemViewType)
+	if err != nil {
+		t.Fatalf("could not migrate view - %s", err.Error())
+	}
+
+	err = db.InsertMany([]*TestItem{{StringColumn: "visible", IntColumn: 1}, {StringColumn: "hidden"}})
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	objects, err := Select[TestItemView](db, "")
+	if err != nil || len(objects) != 1 || objects[0].StringColumn != "visible" {
+		t.Errorf("incorrect view rows - %v", objects)
+	}
+
+	objects[0].StringColumn = "changed"
+	err = db.UpdateOne(&objects[0])
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("view row updated - %v", err)
+	}
+
+	err = db.DropTable(TestItemViewType, false)
+	if err != nil {
+		t.Errorf("could not drop view - %s", err.Error())
+	}
+}
+
+func TestIDStrategyInserts(t *testing.T) {
+	err := db.CreateTables([]reflect.Type{TestSnowflakeItemType, TestKSUIDItemType, TestIdentityItemType}, true)
+	if err != nil {
+		t.Fatalf("could not create tables - %s", err.Error())
+	}
+
+	snowflakeObject := &TestSnowflakeItem{Name: "snowflake"}
+	ksuidObject := &TestKSUIDItem{Name: "ksuid"}
+	identityObject := &TestIdentityItem{Name: "identity"}
+	for _, object := range []any{snowflakeObject, ksuidObject, identityObject} {
+		err = db.Insert(object)
+		if err != nil {
+			t.Fatalf("could not insert object - %s", err.Error())
+		}
+	}
+
+	if snowflakeObject.ID <= 0 || len(ksuidObject.ID) != 27 || identityObject.ID != 1 {
+		t.Errorf("IDs not generated - %v %v %v", snowflakeObject, ksuidObject, identityObject)
+	}
+
+	selectedObject, err := SelectOne[TestKSUIDItem](db, "where id = $1", ksuidObject.ID)
+	if err != nil || selectedObject.Name != "ksuid" {
+		t.Errorf("could not select object by KSUID - %v", err)
+	}
+
+	objects := []*TestSnowflakeItem{{Name: "first"}, {Name: "second"}}
+	err = db.InsertMany(objects)
+	if err != nil || objects[0].ID == objects[1].ID || objects[0].ID <= snowflakeObject.ID {
+		t.Errorf("could not insert objects with snowflake IDs - %v", err)
+	}
+}
+
+func TestManyToMany(t *testing.T) {
+	err := db.CreateTables([]reflect.Type{TestRoleType, TestMemberType}, true)
+	if err != nil {
+		t.Fatalf("could not create tables - %s", err.Error())
+	}
+
+	members := []*TestMember{{Name: "first"}, {Name: "second"}}
+	roles := []*TestRole{{Name: "admin"}, {Name: "editor"}}
+	err = db.InsertMany(members)
+	if err == nil {
+		err = db.InsertMany(roles)
+	}
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	// the relation can be associated from either side, and associating twice has no effect
+	for _, pair := range [][2]any{{members[0], roles[1]}, {roles[0], members[0]}, {members[0], roles[0]},
+		{members[1], roles[1]}} {
+		err = db.Associate(pair[0], pair[1])
+		if err != nil {
+			t.Fatalf("could not associate objects - %s", err.Error())
+		}
+	}
+
+	err = db.Preload(members, "Roles")
+	if err != nil {
+		t.Fatalf("could not preload roles - %s", err.Error())
+	}
+
+	if len(members[0].Roles) != 2 || members[0].Roles[0].Name != "admin" || len(members[1].Roles) != 1 {
+		t.Errorf("incorrect roles preloaded - %v", members)
+	}
+
+	err = db.Dissociate(members[0], roles[0])
+	if err != nil {
+		t.Fatalf("could not dissociate objects - %s", err.Error())
+	}
+
+	err = db.Preload(members[0], "Roles")
+	if err != nil || len(members[0].Roles) != 1 || members[0].Roles[0].Name != "editor" {
+		t.Errorf("incorrect roles preloaded after dissociating - %v", members[0].Roles)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	err := db.CreateTable(TestUpsertItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	_, err = SelectOne[TestUpsertItem](db, "where key_column = $1", "missing")
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expected ErrNotFound when selecting a missing object - %v", err)
+	}
+
+	err = db.Insert(&TestUpsertItem{KeyColumn: "lashbits.tech"})
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	err = db.Insert(&TestUpsertItem{KeyColumn: "lashbits.tech"})
+	var violation ErrUniqueViolation
+	if !errors.As(err, &violation) || violation.Constraint != "testupsertitems_key_column_key" {
+		t.Errorf("expected ErrUniqueViolation when inserting a duplicate key - %v", err)
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	limited := db.WithTimeout(50 * time.Millisecond)
+	_, err := limited.QueryMaps("select pg_sleep(1)")
+	if err == nil {
+		t.Errorf("statement not canceled after the timeout")
+	}
+
+	ctx := WithTimeout(context.Background(), 5*time.Second)
+	_, err = limited.QueryMapsCtx(ctx, "select pg_sleep(0.1)")
+	if err != nil {
+		t.Errorf("timeout of the context not used - %s", err.Error())
+	}
+
+	err = db.WithStatementTimeout(50 * time.Millisecond).RunInTransaction(func(tx *Tx) error {
+		_, err := tx.QueryMaps("select pg_sleep(1)")
+		return err
+	})
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "57014" {
+		t.Errorf("statement not canceled by the database - %v", err)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	reconnecting, err := NewDatabase(db.Conn.Config().ConnString())
+	if err != nil {
+		t.Fatalf("could not connect - %s", err.Error())
+	}
+	defer reconnecting.Close()
+
+	err = reconnecting.Ping()
+	if err != nil {
+		t.Fatalf("could not ping database - %s", err.Error())
+	}
+
+	// the copies of the database share the new connection
+	scoped := reconnecting.Unscoped()
+	reconnecting.Conn.Close(context.Background())
+	err = scoped.Ping()
+	if err != nil {
+		t.Errorf("could not reconnect - %s", err.Error())
+	}
+
+	_, err = reconnecting.QueryMaps("select 1")
+	if err != nil {
+		t.Errorf("could not run statement after reconnecting - %s", err.Error())
+	}
+}
+
+func TestRegistryOpen(t *testing.T) {
+	registry := NewRegistry(UseTimeout(time.Second))
+	defer registry.CloseAll()
+
+	opened, err := registry.Open("analytics", db.Conn.Config().ConnString(), UseNaming(DefaultNaming{}))
+	if err != nil {
+		t.Fatalf("could not open database - %s", err.Error())
+	}
+
+	if opened.timeout != time.Second {
+		t.Errorf("options of the registry not applied")
+	}
+
+	analytics, ok := registry.Get("analytics")
+	if !ok || analytics.Ping() != nil {
+		t.Errorf("could not ping registered database")
+	}
+}
+
+func TestSelectEach(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	objects := []*TestItem{{StringColumn: "first", IntColumn: 1}, {StringColumn: "second", IntColumn: 2},
+		{StringColumn: "third", IntColumn: 3}}
+	err = db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	sum := 0
+	err = db.SelectEach(TestItemType, "where int_column > $1 order by int_column", func(object any) error {
+		sum += object.(*TestItem).IntColumn
+		return nil
+	}, 1)
+	if err != nil || sum != 5 {
+		t.Errorf("incorrect streamed objects - %d, %v", sum, err)
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err = db.SelectEach(TestItemType, "", func(object any) error {
+		count++
+		return stop
+	})
+	if err != stop || count != 1 {
+		t.Errorf("iteration not stopped by the error of the function - %v", err)
+	}
+
+	// the single connection of the database is busy reading the rows
+	err = db.SelectEach(TestItemType, "", func(object any) error {
+		_, err := db.Count(TestItemType, "")
+		return err
+	})
+	if err == nil {
+		t.Errorf("statement run on the busy connection")
+	}
+
+	count = 0
+	err = db.SelectEach(TestItemType, "", func(object any) error {
+		count++
+		return nil
+	})
+	if err != nil || count != 3 {
+		t.Errorf("connection not usable after a busy error - %d, %v", count, err)
+	}
+}
+
+func TestFindInBatches(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	objects := make([]*TestItem, 0)
+	for i := 0; i < 7; i++ {
+		objects = append(objects, &TestItem{StringColumn: fmt.Sprintf("item %d", i), IntColumn: i})
+	}
+	err = db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	sizes := make([]int, 0)
+	sum := 0
+	err = db.FindInBatches(TestItemType, 2, "where int_column > $1", func(batch any) error {
+		items := batch.([]TestItem)
+		sizes = append(sizes, len(items))
+		for _, item := range items {
+			sum += item.IntColumn
+		}
+		return nil
+	}, 0)
+	if err != nil {
+		t.Fatalf("could not find objects in batches - %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(sizes, []int{2, 2, 2}) || sum != 21 {
+		t.Errorf("incorrect batches - %v, %d", sizes, sum)
+	}
+
+	err = db.FindInBatches(TestItemType, 0, "", func(batch any) error { return nil })
+	if err == nil {
+		t.Errorf("found objects in batches of size 0")
+	}
+}
+
+func TestSelectPage(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	objects := make([]*TestItem, 0)
+	for i := 0; i < 5; i++ {
+		objects = append(objects, &TestItem{StringColumn: fmt.Sprintf("item %d", i), IntColumn: i})
+	}
+	err = db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	values := make([]int, 0)
+	cursor := Cursor{Limit: 2}
+	for pages := 0; pages < 5; pages++ {
+		page, token, err := db.SelectPage(TestItemType, cursor, "where int_column > $1", 0)
+		if err != nil {
+			t.Fatalf("could not select page - %s", err.Error())
+		}
+
+		for _, item := range page.([]TestItem) {
+			values = append(values, item.IntColumn)
+		}
+
+		if token == "" {
+			break
+		}
+
+		cursor, err = ParseCursor(token)
+		if err != nil {
+			t.Fatalf("could not parse cursor - %s", err.Error())
+		}
+	}
+
+	if !reflect.DeepEqual(values, []int{1, 2, 3, 4}) {
+		t.Errorf("incorrect pages - %v", values)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	objects := make([]*TestItem, 0)
+	for i := 0; i < 5; i++ {
+		objects = append(objects, &TestItem{StringColumn: fmt.Sprintf("item %d", i), IntColumn: i})
+	}
+	err = db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	page, err := db.Paginate(TestItemType, 2, 2, "order by int_column")
+	if err != nil {
+		t.Fatalf("could not paginate objects - %s", err.Error())
+	}
+
+	items := page.Items.([]TestItem)
+	if len(items) != 2 || items[0].IntColumn != 2 || items[1].IntColumn != 3 {
+		t.Errorf("incorrect page items - %v", items)
+	}
+
+	if page.Total != 5 || page.Page != 2 || page.PerPage != 2 || page.Pages != 3 {
+		t.Errorf("incorrect page metadata - %+v", page)
+	}
+
+	page, err = db.Paginate(TestItemType, 4, 2, "where int_column > $1 order by int_column", 0)
+	if err != nil {
+		t.Fatalf("could not paginate objects - %s", err.Error())
+	}
+
+	if len(page.Items.([]TestItem)) != 0 || page.Total != 4 || page.Pages != 2 {
+		t.Errorf("incorrect page past the last one - %+v", page)
+	}
+
+	_, err = db.Paginate(TestItemType, 0, 2, "")
+	if err == nil {
+		t.Errorf("paginated objects with page 0")
+	}
+}
+
+func TestFirstAndLast(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	objects := []*TestItem{{StringColumn: "a", IntColumn: 1}, {StringColumn: "b", IntColumn: 2},
+		{StringColumn: "c", IntColumn: 3}}
+	err = db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	first := TestItem{}
+	err = db.First(&first, "where int_column > $1", 1)
+	if err != nil || first.ID != objects[1].ID {
+		t.Errorf("incorrect first object - %v, %v", first, err)
+	}
+
+	last := TestItem{}
+	err = db.Last(&last, "")
+	if err != nil || last.ID != objects[2].ID {
+		t.Errorf("incorrect last object - %v, %v", last, err)
+	}
+
+	err = db.First(&first, "where int_column > $1", 3)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("incorrect error of missing first object - %v", err)
+	}
+}
+
+func TestExpandedSelect(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	objects := []*TestItem{{StringColumn: "a", IntColumn: 1}, {StringColumn: "b", IntColumn: 2},
+		{StringColumn: "c", IntColumn: 3}}
+	err = db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	clauses, args := Expand("where string_column in (?) and int_column > ? order by id", []string{"a", "c"}, 0)
+	result, err := db.Select(TestItemType, clauses, args...)
+	if err != nil {
+		t.Fatalf("could not select objects - %s", err.Error())
+	}
+
+	items := result.([]TestItem)
+	if len(items) != 2 || items[0].StringColumn != "a" || items[1].StringColumn != "c" {
+		t.Errorf("incorrect selected objects - %v", items)
+	}
+
+	// the raw clauses of the operations are expanded as well
+	result, err = db.Select(TestItemType, "where string_column in (?) order by id", []string{"a", "b"})
+	if err != nil || len(result.([]TestItem)) != 2 {
+		t.Errorf("could not select objects with ? placeholders - %v", err)
+	}
+
+	count, err := db.Count(TestItemType, "where int_column in (?) and string_column <> ?", []int{2, 3}, "c")
+	if err != nil || count != 1 {
+		t.Errorf("could not count objects with ? placeholders - %d, %v", count, err)
+	}
+
+	rows, err := db.UpdateWhere(TestItemType, map[string]any{"IntColumn": 4}, "where string_column in (?)",
+		[]string{"b", "c"})
+	if err != nil || rows != 2 {
+		t.Errorf("could not update objects with ? placeholders - %d, %v", rows, err)
+	}
+
+	rows, err = db.Delete(TestItemType, "where int_column = ?", 4)
+	if err != nil || rows != 2 {
+		t.Errorf("could not delete objects with ? placeholders - %d, %v", rows, err)
+	}
+}
+
+func TestSelectLocked(t *testing.T) {
+	err := db.CreateTable(TestItemType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	objects := []*TestItem{{StringColumn: "a", IntColumn: 1}, {StringColumn: "b", IntColumn: 2}}
+	err = db.InsertMany(objects)
+	if err != nil {
+		t.Fatalf("could not insert objects - %s", err.Error())
+	}
+
+	other, err := NewDatabase(db.Conn.Config().ConnString())
+	if err != nil {
+		t.Fatalf("could not open second connection - %s", err.Error())
+	}
+	defer other.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("could not begin transaction - %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	locked, err := tx.SelectForUpdate(TestItemType, "order by id limit 1")
+	if err != nil || len(locked.([]TestItem)) != 1 {
+		t.Fatalf("could not select objects for update - %v", err)
+	}
+
+	otherTx, err := other.Begin()
+	if err != nil {
+		t.Fatalf("could not begin transaction - %s", err.Error())
+	}
+	defer otherTx.Rollback()
+
+	claimed, err := otherTx.SelectLocked(TestItemType, ForUpdateSkipLocked, "order by id")
+	if err != nil {
+		t.Fatalf("could not select locked objects - %s", err.Error())
+	}
+
+	items := claimed.([]TestItem)
+	if len(items) != 1 || items[0].ID != objects[1].ID {
+		t.Errorf("locked objects not skipped - %v", items)
+	}
+
+	_, err = otherTx.SelectQuery(TestItemType, NewQuery().Where("id = ?", objects[0].ID).Lock(ForUpdateNoWait))
+	if err == nil {
+		t.Errorf("selected object locked by another transaction without waiting")
+	}
+}
+
+func TestQueue(t *testing.T) {
+	err := db.CreateTable(TestJobType, true)
+	if err != nil {
+		t.Fatalf("could not create table - %s", err.Error())
+	}
+
+	queue := NewQueue[TestJob](db).WithMaxAttempts(2)
+	for _, recipient := range []string{"first", "second"} {
+		err = queue.Enqueue(&TestJob{Recipient: recipient})
+		if err != nil {
+			t.Fatalf("could not enqueue job - %s", err.Error())
+		}
+	}
+
+	later := &TestJob{Recipient: "later", JobState: JobState{VisibleAt: time.Now().UTC().Add(time.Hour)}}
+	err = queue.Enqueue(later)
+	if err != nil {
+		t.Fatalf("could not enqueue job - %s", err.Error())
+	}
+
+	jobs, err := queue.Dequeue(5)
+	if err != nil {
+		t.Fatalf("could not dequeue jobs - %s", err.Error())
+	}
+
+	if len(jobs) != 2 || jobs[0].Recipient != "first" || jobs[0].Status != JobRunning || jobs[0].Attempts != 1 {
+		t.Fatalf("incorrect dequeued jobs - %v", jobs)
+	}
+
+	err = queue.Complete(&jobs[0])
+	if err != nil || jobs[0].Status != JobDone {
+		t.Errorf("could not complete job - %v", err)
+	}
+
+	err = queue.Complete(&jobs[0])
+	if !errors.Is(err, ErrJobNotHeld) {
+		t.Errorf("completed job twice - %v", err)
+	}
+
+	err = queue.Fail(&jobs[1], errors.New("mailbox full"))
+	if err != nil || jobs[1].Status != JobPending {
+		t.Errorf("could not fail job - %v", err)
+	}
+
+	retried, err := queue.Dequeue(5)
+	if err != nil || len(retried) != 1 || retried[0].ID != jobs[1].ID || retried[0].LastError != "mailbox full" {
+		t.Fatalf("incorrect retried jobs - %v, %v", retried, err)
+	}
+
+	err = queue.Fail(&retried[0], errors.New("mailbox full"))
+	if err != nil || retried[0].Status != JobFailed {
+		t.Errorf("job not failed after the maximum number of attempts - %v", err)
+	}
+
+	expired, err := queue.WithVisibilityTimeout(0).Dequeue(5)
+	if err != nil || len(expired) != 0 {
+		t.Errorf("dequeued jobs that are not visible - %v, %v", expired, err)
+	}
+
+	// a job scheduled in a time zone east of UTC is not visible before its time, nor one west of UTC after it
+	east := time.FixedZone("UTC+5", 5*60*60)
+	west := time.FixedZone("UTC-5", -5*60*60)
+	err = queue.Enqueue(&TestJob{Recipient: "east", JobState: JobState{VisibleAt: time.Now().In(east).Add(time.Hour)}})
+	if err == nil {
+		past := time.Now().In(west).Add(-time.Minute)
+		err = queue.Enqueue(&TestJob{Recipient: "west", JobState: JobState{VisibleAt: past}})
+	}
+	if err != nil {
+		t.Fatalf("could not enqueue job - %s", err.Error())
+	}
+
+	scheduled, err := queue.Dequeue(5)
+	if err != nil || len(scheduled) != 1 || scheduled[0].Recipient != "west" {
+		t.Errorf("incorrect jobs scheduled in other time zones - %v, %v", scheduled, err)
+	}
+}
+
+type TestUserOrder struct {
+	User  TestUser
+	Order *TestOrder
+}
+
+func TestSelectJoined(t *testing.T) {
+	users := []TestUser{{Name: "joined with orders"}, {Name: "joined without"}}
+	err := db.InsertMany(users)
+	if err != nil {
+		t.Fatalf("could not insert users - %s", err.Error())
+	}
+
+	orders := []TestOrder{{TestUserID: users[0].ID, Amount: 3}, {TestUserID: users[0].ID, Amount: 4}}
+	err = db.InsertMany(orders)
+	if err != nil {
+		t.Fatalf("could not insert orders - %s", err.Error())
+	}
+
+	var result []TestUserOrder
+	q := NewQuery().
+		LeftJoin("testorders", "testorders.test_user_id = testusers.id and testorders.amount > ?", 3).
+		Where("testusers.name like ?", "joined %").
+		OrderBy("testusers.id")
+	err = db.SelectJoined(&result, q)
+	if err != nil {
+		t.Fatalf("could not select joined rows - %s", err.Error())
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("incorrect number of joined rows - %d instead of 2", len(result))
+	}
+
+	if result[0].User.ID != users[0].ID || result[0].Order == nil || result[0].Order.Amount != 4 {
+		t.Errorf("incorrect joined row - %v", result[0])
+	}
+
+	if result[1].User.Name != "joined without" || result[1].Order != nil {
+		t.Errorf("incorrect row without join - %v", result[1])
+	}
+
+	db.Delete(TestOrderType, "where test_user_id = $1", users[0].ID)
+	db.Delete(TestUserType, "where name like $1", "joined %")
+}
@@ -0,0 +1,34 @@
+package liteorm
+
+import (
+	"context"
+	"reflect"
+)
+
+// DB is the set of operations shared by Database and Tx, which allows code to run either directly on the database or
+// within the transaction of its caller. It is also implemented by liteormtest.FakeDB, an in-memory fake for unit tests.
+type DB interface {
+	Insert(arg any) error
+	InsertCtx(ctx context.Context, arg any) error
+	Save(arg any) error
+	SaveCtx(ctx context.Context, arg any) error
+	SelectOne(arg any, clauses string, args ...any) error
+	SelectOneCtx(ctx context.Context, arg any, clauses string, args ...any) error
+	FindByID(arg any, id any) error
+	FindByIDCtx(ctx context.Context, arg any, id any) error
+	Select(t reflect.Type, clauses string, args ...any) (any, error)
+	SelectCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (any, error)
+	Count(t reflect.Type, clauses string, args ...any) (int64, error)
+	CountCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error)
+	Exists(t reflect.Type, clauses string, args ...any) (bool, error)
+	ExistsCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (bool, error)
+	UpdateOne(arg any) error
+	UpdateOneCtx(ctx context.Context, arg any) error
+	Delete(t reflect.Type, clauses string, args ...any) (int64, error)
+	DeleteCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error)
+	DeleteOne(arg any) error
+	DeleteOneCtx(ctx context.Context, arg any) error
+}
+
+var _ DB = (*Database)(nil)
+var _ DB = (*Tx)(nil)
@@ -0,0 +1,206 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Dialect adapts the statements generated by liteorm to a database engine. Statements are always built with the
+// numbered placeholders of PostgreSQL, i.e. $1, $2, etc., which are rewritten with Placeholder before they are run, so
+// the clauses passed to the operations use them regardless of the dialect.
+type Dialect interface {
+	// ColumnType returns the column type of a field, including the generated types of ID fields.
+	ColumnType(field reflect.StructField) (string, error)
+
+	// Placeholder returns the placeholder of the argument at the index received as argument, starting at 1.
+	Placeholder(index int) string
+
+	// Returning returns the clause appended to inserts to return the columns of the new row, including the ID and the
+	// other values generated by the database, given as a comma separated list of quoted column names.
+	Returning(columnNames string) string
+
+	// TableExistsStatement returns the statement that checks whether a table exists.
+	TableExistsStatement(schemaName string, tableName string) string
+
+	// CurrentTimestamp returns the expression of the current time in UTC, which sets the timestamp fields of inserted
+	// and updated rows that are not set by the client.
+	CurrentTimestamp() string
+}
+
+// PostgreSQL is the dialect of PostgreSQL, which is used unless another dialect is set with WithDialect.
+type PostgreSQL struct{}
+
+func (PostgreSQL) ColumnType(field reflect.StructField) (string, error) {
+	return buildColumnType(field)
+}
+
+func (PostgreSQL) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+func (PostgreSQL) Returning(columnNames string) string {
+	return fmt.Sprintf("returning %s", columnNames)
+}
+
+func (PostgreSQL) TableExistsStatement(schemaName string, tableName string) string {
+	return fmt.Sprintf(`
+        select exists (
+            select from information_schema.tables
+            where table_schema = '%s'
+            and table_name = '%s'
+        );`, schemaName, tableName)
+}
+
+func (PostgreSQL) CurrentTimestamp() string {
+	return "(now() at time zone 'utc')"
+}
+
+// sqliteColumnTypes maps the PostgreSQL column types generated by mapColumnType to SQLite column types. Types that are
+// not listed are used as they are, and SQLite derives their affinity from their name.
+var sqliteColumnTypes = map[string]string{
+	"smallint": "integer",
+	"int":      "integer",
+	"bigint":   "integer",
+	"float4":   "real",
+	"float8":   "real",
+	"uuid":     "blob",
+	"bytea":    "blob",
+	"jsonb":    "text",
+	"interval": "integer",
+}
+
+// SQLite is the dialect of SQLite, for databases created with NewDatabaseFromSQL on a SQLite driver. Inserts return the
+// generated values with a returning clause, which requires SQLite 3.35 or later. Array fields are not supported, and
+// neither are full-text search and the operations specific to PostgreSQL, e.g. schemas, AutoMigrate, InspectTable,
+// locks and notifications.
+type SQLite struct{}
+
+func (SQLite) ColumnType(field reflect.StructField) (string, error) {
+	// integer primary keys are aliases of the rowid, which SQLite generates on insert
+	if field.Name == "ID" && isIntegerType(field.Type) {
+		return "integer", nil
+	}
+
+	if field.Name == "ID" && isUUIDType(field.Type) {
+		return "blob default (randomblob(16))", nil
+	}
+
+	// SQLite has no enum types, and the values of enum fields are validated on insert and update anyway
+	if _, ok := getEnumValues(field.Type); ok {
+		return "text", nil
+	}
+
+	columnType, err := mapColumnType(field)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasSuffix(columnType, "[]") {
+		return "", errors.New(fmt.Sprintf("array field %s is not supported by SQLite", field.Name))
+	}
+
+	if columnType == "tsvector" {
+		return "", errors.New(fmt.Sprintf("full-text search field %s is not supported by SQLite", field.Name))
+	}
+
+	if sqliteType, ok := sqliteColumnTypes[columnType]; ok && parseTag(field)["type"] == "" {
+		return sqliteType, nil
+	}
+
+	return columnType, nil
+}
+
+func (SQLite) Placeholder(index int) string {
+	return fmt.Sprintf("?%d", index)
+}
+
+func (SQLite) Returning(columnNames string) string {
+	return fmt.Sprintf("returning %s", columnNames)
+}
+
+func (SQLite) TableExistsStatement(schemaName string, tableName string) string {
+	return fmt.Sprintf("select exists (select 1 from sqlite_master where type = 'table' and name = '%s');", tableName)
+}
+
+func (SQLite) CurrentTimestamp() string {
+	return "strftime('%Y-%m-%d %H:%M:%f', 'now')"
+}
+
+// WithDialect returns a copy of the database whose statements are generated for the dialect received as argument, e.g.
+// SQLite for a database created with NewDatabaseFromSQL. Transactions started from the copy use the same dialect.
+func (db *Database) WithDialect(dialect Dialect) *Database {
+	adapted := *db
+	adapted.dialect = dialect
+	return &adapted
+}
+
+// rebind rewrites the numbered placeholders of a statement, i.e. $1, $2, etc., with those of the dialect received as
+// argument. Placeholders within quoted strings and identifiers are left as they are.
+func rebind(statement string, dialect Dialect) string {
+	var result strings.Builder
+	var quote byte
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+
+		case c == '\'' || c == '"':
+			quote = c
+
+		case c == '$' && i+1 < len(statement) && statement[i+1] >= '0' && statement[i+1] <= '9':
+			end := i + 1
+			for end < len(statement) && statement[end] >= '0' && statement[end] <= '9' {
+				end++
+			}
+
+			index, _ := strconv.Atoi(statement[i+1 : end])
+			result.WriteString(dialect.Placeholder(index))
+			i = end - 1
+			continue
+		}
+
+		result.WriteByte(c)
+	}
+
+	return result.String()
+}
+
+// dialectExecutor wraps the executor of a session to rewrite the placeholders of the statements it runs.
+type dialectExecutor struct {
+	executor
+	dialect Dialect
+}
+
+func (e *dialectExecutor) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return e.executor.Exec(ctx, rebind(sql, e.dialect), args...)
+}
+
+func (e *dialectExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return e.executor.Query(ctx, rebind(sql, e.dialect), args...)
+}
+
+func (e *dialectExecutor) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return e.executor.QueryRow(ctx, rebind(sql, e.dialect), args...)
+}
+
+// SendBatch sends a copy of the batch whose queued statements are rewritten, so that the batch of the caller is left
+// as it is.
+func (e *dialectExecutor) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	rebound := &pgx.Batch{QueuedQueries: make([]*pgx.QueuedQuery, len(b.QueuedQueries))}
+	for i, query := range b.QueuedQueries {
+		queued := *query
+		queued.SQL = rebind(query.SQL, e.dialect)
+		rebound.QueuedQueries[i] = &queued
+	}
+
+	return e.executor.SendBatch(ctx, rebound)
+}
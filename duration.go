@@ -0,0 +1,64 @@
+package liteorm
+
+import (
+	"reflect"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// mapDurationType maps time.Duration fields to interval columns, or to bigint columns holding a number of microseconds
+// if the field has the "microseconds" option in its liteorm tag, e.g. `liteorm:"microseconds"`, which is easier to
+// compare and aggregate. Either way, the precision of the stored durations is a microsecond.
+func mapDurationType(field reflect.StructField, t reflect.Type) (string, bool) {
+	if t != durationType {
+		return "", false
+	}
+
+	if isMicrosecondsField(field) {
+		return "bigint", true
+	}
+
+	return "interval", true
+}
+
+// isMicrosecondsField reports whether a field holds a time.Duration, or a pointer to one, stored as microseconds.
+func isMicrosecondsField(field reflect.StructField) bool {
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	_, ok := parseTag(field)["microseconds"]
+	return ok && t == durationType
+}
+
+// getMicrosecondsValue returns the number of microseconds stored for a duration field. Nil pointers are stored as null.
+func getMicrosecondsValue(fieldv reflect.Value) any {
+	if fieldv.Kind() == reflect.Ptr {
+		if fieldv.IsNil() {
+			return nil
+		}
+		fieldv = fieldv.Elem()
+	}
+
+	return time.Duration(fieldv.Int()).Microseconds()
+}
+
+// setMicrosecondsValue sets a duration field from the number of microseconds scanned from its column. Null columns
+// leave the field at its zero value.
+func setMicrosecondsValue(fieldv reflect.Value, field reflect.StructField, microseconds *int64) {
+	fieldv.Set(reflect.Zero(field.Type))
+	if microseconds == nil {
+		return
+	}
+
+	value := reflect.ValueOf(time.Duration(*microseconds) * time.Microsecond)
+	if field.Type.Kind() == reflect.Ptr {
+		pointer := reflect.New(durationType)
+		pointer.Elem().Set(value)
+		value = pointer
+	}
+
+	fieldv.Set(value)
+}
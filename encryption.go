@@ -0,0 +1,245 @@
+package liteorm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"reflect"
+)
+
+// KeyProvider provides the keys of the fields encrypted with the "encrypt" tag, e.g. `encrypt:"aes"`. Values are
+// encrypted with AES-GCM, so keys must be 16, 24 or 32 bytes long, and the ID of the key is stored along with each value
+// so that keys can be rotated without re-encrypting existing rows.
+type KeyProvider interface {
+	// CurrentKey returns the key that new values are encrypted with, and its ID.
+	CurrentKey() (id string, key []byte, err error)
+
+	// Key returns the key with the ID received as argument, to decrypt the values encrypted with it.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKey is a KeyProvider with a single key, whose ID is empty.
+type StaticKey []byte
+
+func (k StaticKey) CurrentKey() (string, []byte, error) {
+	return "", k, nil
+}
+
+func (k StaticKey) Key(id string) ([]byte, error) {
+	if id != "" {
+		return nil, errors.New(fmt.Sprintf("unknown key %s", id))
+	}
+
+	return k, nil
+}
+
+// WithKeyProvider returns a copy of the database that encrypts and decrypts the fields tagged with encrypt using the
+// keys of the provider received as argument. Encrypted fields are stored in bytea columns, and since the same value is
+// encrypted differently each time, they cannot be used in the conditions of queries.
+func (db *Database) WithKeyProvider(provider KeyProvider) *Database {
+	encrypted := *db
+	encrypted.keyProvider = provider
+	return &encrypted
+}
+
+// isEncryptedField reports whether a field has the "encrypt" tag.
+func isEncryptedField(field reflect.StructField) bool {
+	_, ok := field.Tag.Lookup("encrypt")
+	return ok
+}
+
+// mapEncryptedColumnType returns the column type of an encrypted field, which must be a string, a pointer to a string
+// or a byte slice, and use a supported algorithm.
+func mapEncryptedColumnType(field reflect.StructField) (string, error) {
+	if algorithm := field.Tag.Get("encrypt"); algorithm != "aes" {
+		return "", errors.New(fmt.Sprintf("unsupported encryption algorithm %q of field %s", algorithm, field.Name))
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.String && !(t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8) {
+		return "", errors.New(fmt.Sprintf("encrypted field %s is not a string or a byte slice", field.Name))
+	}
+
+	return "bytea", nil
+}
+
+// encryptedValue is the value of an encrypted field before encryption, as returned by getColumnValue. It is replaced by
+// its ciphertext by encryptValues before the statement runs.
+type encryptedValue struct {
+	field     string
+	plaintext []byte
+}
+
+// encryptedColumn is the scan target of an encrypted column, which holds its ciphertext until decryptValues decrypts
+// it for setColumnValue.
+type encryptedColumn struct {
+	ciphertext []byte
+	plaintext  []byte
+	decrypted  bool
+}
+
+func (c *encryptedColumn) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		c.ciphertext = nil
+	case []byte:
+		c.ciphertext = append([]byte(nil), src...)
+	default:
+		return errors.New(fmt.Sprintf("cannot scan %T into an encrypted field", src))
+	}
+
+	return nil
+}
+
+// getEncryptedValue returns the value of an encrypted field to be encrypted. Nil pointers and slices are stored as
+// null.
+func getEncryptedValue(fieldv reflect.Value, field reflect.StructField) any {
+	if fieldv.Kind() == reflect.Ptr {
+		if fieldv.IsNil() {
+			return nil
+		}
+		fieldv = fieldv.Elem()
+	}
+
+	if fieldv.Kind() == reflect.Slice {
+		if fieldv.IsNil() {
+			return nil
+		}
+		return encryptedValue{field: field.Name, plaintext: fieldv.Bytes()}
+	}
+
+	return encryptedValue{field: field.Name, plaintext: []byte(fieldv.String())}
+}
+
+// setEncryptedValue sets an encrypted field from the decrypted value of its column. Null columns leave the field at
+// its zero value.
+func setEncryptedValue(fieldv reflect.Value, field reflect.StructField, column *encryptedColumn) error {
+	fieldv.Set(reflect.Zero(field.Type))
+	if column.ciphertext == nil {
+		return nil
+	}
+
+	if !column.decrypted {
+		return errors.New(fmt.Sprintf("could not decrypt field %s - no key provider is set", field.Name))
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	value := reflect.New(t).Elem()
+	if t.Kind() == reflect.Slice {
+		value.SetBytes(column.plaintext)
+	} else {
+		value.SetString(string(column.plaintext))
+	}
+
+	if field.Type.Kind() == reflect.Ptr {
+		value = value.Addr()
+	}
+
+	fieldv.Set(value)
+	return nil
+}
+
+// encryptValues replaces the values of encrypted fields among the values received as argument with their ciphertext,
+// i.e. the length of the key ID, the key ID, the nonce and the sealed value.
+func (s settings) encryptValues(values []any) error {
+	for i, value := range values {
+		value, ok := value.(encryptedValue)
+		if !ok {
+			continue
+		}
+
+		if s.keyProvider == nil {
+			return errors.New(fmt.Sprintf("could not encrypt field %s - no key provider is set", value.field))
+		}
+
+		id, key, err := s.keyProvider.CurrentKey()
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not encrypt field %s", value.field))
+		}
+
+		if len(id) > 255 {
+			return errors.New(fmt.Sprintf("could not encrypt field %s - key ID is too long", value.field))
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not encrypt field %s", value.field))
+		}
+
+		ciphertext := append([]byte{byte(len(id))}, id...)
+		nonce := make([]byte, aead.NonceSize())
+		if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not encrypt field %s", value.field))
+		}
+
+		ciphertext = append(ciphertext, nonce...)
+		values[i] = aead.Seal(ciphertext, nonce, value.plaintext, nil)
+	}
+
+	return nil
+}
+
+// decryptValues decrypts the encrypted columns among the scanned values received as argument. Without a key provider,
+// the columns are left encrypted, and setColumnValue fails on those that are not null.
+func (s settings) decryptValues(values []any) error {
+	if s.keyProvider == nil {
+		return nil
+	}
+
+	for _, value := range values {
+		column, ok := value.(*encryptedColumn)
+		if !ok || column.ciphertext == nil {
+			continue
+		}
+
+		ciphertext := column.ciphertext
+		if len(ciphertext) < 1 || len(ciphertext) < 1+int(ciphertext[0]) {
+			return errors.New("could not decrypt column - value is too short")
+		}
+
+		id := string(ciphertext[1 : 1+ciphertext[0]])
+		ciphertext = ciphertext[1+len(id):]
+		key, err := s.keyProvider.Key(id)
+		if err != nil {
+			return errors.Wrap(err, "could not decrypt column")
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return errors.Wrap(err, "could not decrypt column")
+		}
+
+		if len(ciphertext) < aead.NonceSize() {
+			return errors.New("could not decrypt column - value is too short")
+		}
+
+		column.plaintext, err = aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+		if err != nil {
+			return errors.Wrap(err, "could not decrypt column")
+		}
+		column.decrypted = true
+	}
+
+	return nil
+}
+
+// newAEAD returns the AES-GCM cipher of the key received as argument.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
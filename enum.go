@@ -0,0 +1,114 @@
+package liteorm
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+// Enum is implemented by string types whose values are restricted to a fixed set, which are stored in a column of a
+// PostgreSQL enum type, e.g.
+//
+//	type PostStatus string
+//
+//	func (PostStatus) EnumValues() []string {
+//		return []string{"draft", "published", "archived"}
+//	}
+//
+// The enum type is named after the Go type in snake case, e.g. post_status, and is created by CreateTable and
+// AutoMigrate unless it already exists. Inserts and updates fail on values that are not in the set, before they reach
+// the database.
+type Enum interface {
+	EnumValues() []string
+}
+
+var enumType = reflect.TypeOf((*Enum)(nil)).Elem()
+
+// getEnumValues returns the allowed values of string types implementing Enum.
+func getEnumValues(t reflect.Type) ([]string, bool) {
+	if t.Kind() != reflect.String || !t.Implements(enumType) {
+		return nil, false
+	}
+
+	return reflect.Zero(t).Interface().(Enum).EnumValues(), true
+}
+
+// enumTypeName returns the name of the PostgreSQL enum type of an Enum type.
+func enumTypeName(t reflect.Type) string {
+	return SnakeCase(t.Name())
+}
+
+// enumTypes returns the Enum types of the fields of the type received as argument, including those of pointer and
+// slice fields, in field order and without duplicates.
+func enumTypes(argt reflect.Type) []reflect.Type {
+	types := make([]reflect.Type, 0)
+	seen := make(map[reflect.Type]bool)
+	for _, field := range columnFields(argt) {
+		t := field.Type
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+
+		if _, ok := getEnumValues(t); ok && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+
+	return types
+}
+
+// buildCreateEnumStatements builds a statement for each Enum type of the fields of the type received as argument that
+// creates the enum type, unless a type with the same name already exists.
+func buildCreateEnumStatements(argt reflect.Type) []string {
+	types := enumTypes(argt)
+	statements := make([]string, len(types))
+	for i, t := range types {
+		values, _ := getEnumValues(t)
+		quotedValues := make([]string, len(values))
+		for j, value := range values {
+			quotedValues[j] = fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "''"))
+		}
+
+		// there is no create type if not exists, so the error raised for existing types is caught instead
+		statements[i] = fmt.Sprintf("do $$ begin create type %s as enum (%s); exception when duplicate_object then "+
+			"null; end $$;", quoteIdentifier(enumTypeName(t)), strings.Join(quotedValues, ","))
+	}
+
+	return statements
+}
+
+// validateEnumValue checks that the value of a field of an Enum type, or of a pointer or slice of one, is one of the
+// allowed values. Nil pointers are stored as null, and are always valid.
+func validateEnumValue(field reflect.StructField, fieldv reflect.Value) error {
+	switch fieldv.Kind() {
+	case reflect.Ptr:
+		if fieldv.IsNil() {
+			return nil
+		}
+		return validateEnumValue(field, fieldv.Elem())
+
+	case reflect.Slice:
+		for i := 0; i < fieldv.Len(); i++ {
+			if err := validateEnumValue(field, fieldv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	values, ok := getEnumValues(fieldv.Type())
+	if !ok {
+		return nil
+	}
+
+	for _, value := range values {
+		if fieldv.String() == value {
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("invalid value %q of field %s - allowed values are %s", fieldv.String(), field.Name,
+		strings.Join(values, ", ")))
+}
@@ -0,0 +1,214 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+// ErrStaleObject is returned by UpdateOne when the object has a Version field and the row was modified since the object
+// was selected, i.e. the version of the row no longer matches the version of the object.
+var ErrStaleObject = errors.New("object is stale")
+
+// ErrNotFound is returned by SelectOne, FindByID and the other selects of a single object when no row matches, and by
+// DeleteOne when no row matches the ID of the object. It also matches pgx.ErrNoRows, which these selects returned
+// before, with errors.Is.
+var ErrNotFound error = notFoundError{}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string {
+	return "object not found"
+}
+
+func (notFoundError) Is(target error) bool {
+	return target == pgx.ErrNoRows
+}
+
+// ErrUniqueViolation is returned when a statement violates a unique constraint or index, e.g. when inserting an object
+// with the email of another one. It matches with errors.As, and with errors.Is against ErrUniqueViolation{}, or against
+// ErrUniqueViolation{Constraint: name} to match a single constraint.
+type ErrUniqueViolation struct {
+	// Constraint is the name of the violated constraint or index
+	Constraint string
+
+	// Table is the name of the table of the violated constraint
+	Table string
+
+	// Err is the error returned by PostgreSQL
+	Err error
+}
+
+func (e ErrUniqueViolation) Error() string {
+	return e.Err.Error()
+}
+
+func (e ErrUniqueViolation) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrUniqueViolation) Is(target error) bool {
+	t, ok := target.(ErrUniqueViolation)
+	return ok && (t.Constraint == "" || t.Constraint == e.Constraint)
+}
+
+// ErrForeignKeyViolation is returned when a statement violates a foreign key constraint, e.g. when inserting an object
+// referencing a missing row or deleting a referenced row. It matches like ErrUniqueViolation.
+type ErrForeignKeyViolation struct {
+	// Constraint is the name of the violated constraint
+	Constraint string
+
+	// Table is the name of the table of the violated constraint
+	Table string
+
+	// Err is the error returned by PostgreSQL
+	Err error
+}
+
+func (e ErrForeignKeyViolation) Error() string {
+	return e.Err.Error()
+}
+
+func (e ErrForeignKeyViolation) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrForeignKeyViolation) Is(target error) bool {
+	t, ok := target.(ErrForeignKeyViolation)
+	return ok && (t.Constraint == "" || t.Constraint == e.Constraint)
+}
+
+// ErrCheckViolation is returned when a statement violates a check constraint, e.g. one added by a migration. It
+// matches like ErrUniqueViolation.
+type ErrCheckViolation struct {
+	// Constraint is the name of the violated constraint
+	Constraint string
+
+	// Table is the name of the table of the violated constraint
+	Table string
+
+	// Err is the error returned by PostgreSQL
+	Err error
+}
+
+func (e ErrCheckViolation) Error() string {
+	return e.Err.Error()
+}
+
+func (e ErrCheckViolation) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrCheckViolation) Is(target error) bool {
+	t, ok := target.(ErrCheckViolation)
+	return ok && (t.Constraint == "" || t.Constraint == e.Constraint)
+}
+
+// translateError translates the errors of pgx into the errors of liteorm, i.e. missing rows into ErrNotFound and
+// constraint violations into the violation types. Other errors are returned unchanged.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case "23505":
+		return ErrUniqueViolation{Constraint: pgErr.ConstraintName, Table: pgErr.TableName, Err: err}
+	case "23503":
+		return ErrForeignKeyViolation{Constraint: pgErr.ConstraintName, Table: pgErr.TableName, Err: err}
+	case "23514":
+		return ErrCheckViolation{Constraint: pgErr.ConstraintName, Table: pgErr.TableName, Err: err}
+	}
+
+	return err
+}
+
+// errorTranslatingExecutor wraps the executor of a session to translate the errors of the statements it runs, see
+// translateError.
+type errorTranslatingExecutor struct {
+	executor
+}
+
+func (e *errorTranslatingExecutor) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	commandTag, err := e.executor.Exec(ctx, sql, args...)
+	return commandTag, translateError(err)
+}
+
+func (e *errorTranslatingExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := e.executor.Query(ctx, sql, args...)
+	if rows == nil {
+		return nil, translateError(err)
+	}
+
+	// like pgx, rows are returned along with the error, so that they can be closed either way
+	return &errorTranslatingRows{Rows: rows}, translateError(err)
+}
+
+func (e *errorTranslatingExecutor) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &errorTranslatingRow{Row: e.executor.QueryRow(ctx, sql, args...)}
+}
+
+func (e *errorTranslatingExecutor) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &errorTranslatingBatchResults{BatchResults: e.executor.SendBatch(ctx, b)}
+}
+
+func (e *errorTranslatingExecutor) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string,
+	rowSrc pgx.CopyFromSource) (int64, error) {
+	count, err := e.executor.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	return count, translateError(err)
+}
+
+// errorTranslatingRows translates the error of rows, which queries report once the rows are read.
+type errorTranslatingRows struct {
+	pgx.Rows
+}
+
+func (r *errorTranslatingRows) Err() error {
+	return translateError(r.Rows.Err())
+}
+
+// errorTranslatingRow translates the error of a row, which QueryRow defers to Scan.
+type errorTranslatingRow struct {
+	pgx.Row
+}
+
+func (r *errorTranslatingRow) Scan(dest ...any) error {
+	return translateError(r.Row.Scan(dest...))
+}
+
+// errorTranslatingBatchResults translates the errors of the results of a batch.
+type errorTranslatingBatchResults struct {
+	pgx.BatchResults
+}
+
+func (b *errorTranslatingBatchResults) Exec() (pgconn.CommandTag, error) {
+	commandTag, err := b.BatchResults.Exec()
+	return commandTag, translateError(err)
+}
+
+func (b *errorTranslatingBatchResults) Query() (pgx.Rows, error) {
+	rows, err := b.BatchResults.Query()
+	if rows == nil {
+		return nil, translateError(err)
+	}
+
+	return &errorTranslatingRows{Rows: rows}, translateError(err)
+}
+
+func (b *errorTranslatingBatchResults) QueryRow() pgx.Row {
+	return &errorTranslatingRow{Row: b.BatchResults.QueryRow()}
+}
+
+func (b *errorTranslatingBatchResults) Close() error {
+	return translateError(b.BatchResults.Close())
+}
@@ -0,0 +1,58 @@
+package liteorm
+
+import (
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestTranslateError(t *testing.T) {
+	notFound := errors.Wrap(translateError(pgx.ErrNoRows), "could not select object")
+	if !errors.Is(notFound, ErrNotFound) || !errors.Is(notFound, pgx.ErrNoRows) {
+		t.Errorf("missing row not translated - %v", notFound)
+	}
+
+	pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key", TableName: "users"}
+	uniqueViolation := errors.Wrap(translateError(pgErr), "could not insert object")
+	var violation ErrUniqueViolation
+	if !errors.As(uniqueViolation, &violation) || violation.Constraint != "users_email_key" ||
+		violation.Table != "users" {
+		t.Errorf("unique violation not translated - %v", uniqueViolation)
+	}
+
+	if !errors.Is(uniqueViolation, ErrUniqueViolation{}) ||
+		!errors.Is(uniqueViolation, ErrUniqueViolation{Constraint: "users_email_key"}) {
+		t.Errorf("unique violation does not match")
+	}
+
+	if errors.Is(uniqueViolation, ErrUniqueViolation{Constraint: "users_name_key"}) ||
+		errors.Is(uniqueViolation, ErrForeignKeyViolation{}) {
+		t.Errorf("unique violation matches another violation")
+	}
+
+	var unwrapped *pgconn.PgError
+	if !errors.As(uniqueViolation, &unwrapped) || IsTransientError(uniqueViolation) {
+		t.Errorf("unique violation does not wrap the error of PostgreSQL")
+	}
+
+	if !errors.Is(translateError(&pgconn.PgError{Code: "23503"}), ErrForeignKeyViolation{}) ||
+		!errors.Is(translateError(&pgconn.PgError{Code: "23514"}), ErrCheckViolation{}) {
+		t.Errorf("foreign key or check violation not translated")
+	}
+
+	other := &pgconn.PgError{Code: "42P01"}
+	if translateError(other) != other {
+		t.Errorf("other error translated")
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	failing := NewDatabaseFromExecutor(closedExecutor{err: connClosedError{err: errors.New("connection refused")}}).
+		WithTimeout(time.Second)
+	_, err := failing.Select(TestItemType, "")
+	if !errors.Is(err, ErrConnClosed) {
+		t.Errorf("expected ErrConnClosed when the query fails - %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+// Executor runs statements. It is implemented by *pgx.Conn, pgx.Tx and *pgxpool.Pool, as well as by mocks such as
+// pgxmock, which allows the same operations to run on a single connection, a pool or a transaction.
+type Executor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// executor is an Executor that also sends batches and copies rows, which InsertMany and CopyFrom rely on.
+type executor interface {
+	Executor
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// errUnsupportedByExecutor is returned by the batches and copies of an executor that does not implement them.
+var errUnsupportedByExecutor = errors.New("operation not supported by the executor")
+
+// fullExecutor returns the executor received as argument if it sends batches and copies rows, and otherwise wraps it
+// so that batches and copies fail with errUnsupportedByExecutor.
+func fullExecutor(e Executor) executor {
+	if full, ok := e.(executor); ok {
+		return full
+	}
+
+	return basicExecutor{Executor: e}
+}
+
+// basicExecutor extends an Executor with batches and copies that always fail.
+type basicExecutor struct {
+	Executor
+}
+
+func (basicExecutor) SendBatch(context.Context, *pgx.Batch) pgx.BatchResults {
+	return errorBatchResults{err: errUnsupportedByExecutor}
+}
+
+func (basicExecutor) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, errUnsupportedByExecutor
+}
+
+// errorBatchResults are the results of a batch that could not be sent.
+type errorBatchResults struct {
+	err error
+}
+
+func (r errorBatchResults) Exec() (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, r.err
+}
+
+func (r errorBatchResults) Query() (pgx.Rows, error) {
+	return nil, r.err
+}
+
+func (r errorBatchResults) QueryRow() pgx.Row {
+	return errorRow{err: r.err}
+}
+
+func (r errorBatchResults) Close() error {
+	return r.err
+}
+
+// errorRow is a row that fails to scan with the error it holds.
+type errorRow struct {
+	err error
+}
+
+func (r errorRow) Scan(...any) error {
+	return r.err
+}
+
+// errorRows are the rows of a query that could not run, which are empty and report the error they hold, so that they
+// can be returned along with the error like pgx does.
+type errorRows struct {
+	err error
+}
+
+func (r errorRows) Close() {}
+
+func (r errorRows) Err() error {
+	return r.err
+}
+
+func (r errorRows) CommandTag() pgconn.CommandTag {
+	return pgconn.CommandTag{}
+}
+
+func (r errorRows) FieldDescriptions() []pgconn.FieldDescription {
+	return nil
+}
+
+func (r errorRows) Next() bool {
+	return false
+}
+
+func (r errorRows) Scan(...any) error {
+	return r.err
+}
+
+func (r errorRows) Values() ([]any, error) {
+	return nil, r.err
+}
+
+func (r errorRows) RawValues() [][]byte {
+	return nil
+}
+
+func (r errorRows) Conn() *pgx.Conn {
+	return nil
+}
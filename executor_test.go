@@ -0,0 +1,122 @@
+package liteorm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"testing"
+)
+
+// nilRowsExecutor fails every statement, returning nil rows along with the error of its queries like mocks such as
+// pgxmock do.
+type nilRowsExecutor struct {
+	err error
+}
+
+func (e nilRowsExecutor) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, e.err
+}
+
+func (e nilRowsExecutor) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, e.err
+}
+
+func (e nilRowsExecutor) QueryRow(context.Context, string, ...any) pgx.Row {
+	return errorRow{err: e.err}
+}
+
+func TestNilRowsExecutor(t *testing.T) {
+	failure := errors.New("query failed")
+	failing := NewDatabaseFromExecutor(nilRowsExecutor{err: failure})
+
+	operations := map[string]func() error{
+		"Select": func() error {
+			_, err := failing.Select(TestItemType, "")
+			return err
+		},
+		"SelectEach": func() error {
+			return failing.SelectEach(TestItemType, "", func(any) error { return nil })
+		},
+		"FindInBatches": func() error {
+			return failing.FindInBatches(TestItemType, 10, "", func(any) error { return nil })
+		},
+		"SelectPage": func() error {
+			_, _, err := failing.SelectPage(TestItemType, Cursor{Limit: 10}, "")
+			return err
+		},
+		"Paginate": func() error {
+			_, err := failing.Paginate(TestItemType, 1, 10, "")
+			return err
+		},
+		"Query": func() error {
+			var result []TestItem
+			return failing.Query(&result, "select * from testitems;")
+		},
+		"QueryMaps": func() error {
+			_, err := failing.QueryMaps("select * from testitems;")
+			return err
+		},
+		"SelectJoined": func() error {
+			var result []TestUserOrder
+			return failing.SelectJoined(&result, NewQuery())
+		},
+		"Explain": func() error {
+			_, err := failing.Explain(TestItemType, "")
+			return err
+		},
+		"InspectTable": func() error {
+			_, err := failing.InspectTable("testitems")
+			return err
+		},
+		"GenerateModels": func() error {
+			_, err := failing.GenerateModels("models")
+			return err
+		},
+	}
+
+	for name, operation := range operations {
+		if err := operation(); !errors.Is(err, failure) {
+			t.Errorf("incorrect error of %s - %v", name, err)
+		}
+	}
+}
+
+// failingQueryer fails every statement like a *sql.DB that lost its connection.
+type failingQueryer struct {
+	err error
+}
+
+func (q failingQueryer) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, q.err
+}
+
+func (q failingQueryer) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, q.err
+}
+
+func (q failingQueryer) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func TestSQLExecutorQueryError(t *testing.T) {
+	failure := errors.New("connection refused")
+	rows, err := sqlExecutor{queryer: failingQueryer{err: failure}}.Query(context.Background(), "select 1;")
+	if err != failure || rows == nil {
+		t.Fatalf("incorrect query result - %v, %v", rows, err)
+	}
+
+	// the rows returned along with the error can be used like those of pgx
+	if rows.Next() || rows.Err() != failure {
+		t.Errorf("rows of failed query not carrying the error")
+	}
+	rows.Close()
+
+	defer func() {
+		if p := recover(); p == nil || !errors.Is(p.(error), errUnsupportedByExecutor) {
+			t.Errorf("large objects not reported as unsupported - %v", p)
+		}
+	}()
+	(&sqlTx{}).LargeObjects()
+}
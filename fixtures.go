@@ -0,0 +1,167 @@
+package liteorm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// fixtureExtensions are the extensions of fixture files, in the order they are looked up.
+var fixtureExtensions = []string{".yml", ".yaml", ".json"}
+
+// LoadFixtures inserts the fixtures of the types received as argument from the directory dir, e.g. to set up test data
+// or seed a local database. The fixtures of a type are read from the file named after its table, with the extension
+// .yml, .yaml or .json, which maps a label for each fixture to its values by field or column name:
+//
+//	alice:
+//	  Name: Alice
+//	first_post:
+//	  Title: Hello
+//	  author_id: $alice
+//
+// A string value of the form $label is replaced by the ID of the fixture with that label, so the types are loaded in
+// the order of their foreign keys, and the fixtures of a type in the order of their labels. All fixtures are inserted
+// within a single transaction, so that either all or none of them are loaded.
+func (db *Database) LoadFixtures(dir string, types ...reflect.Type) error {
+	return db.LoadFixturesCtx(context.Background(), dir, types...)
+}
+
+func (db *Database) LoadFixturesCtx(ctx context.Context, dir string, types ...reflect.Type) error {
+	errmsg := fmt.Sprintf("could not load fixtures from %s", dir)
+
+	sortedTypes, err := sortByReferences(types, db.namingStrategy())
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	return db.RunInTransactionCtx(ctx, func(tx *Tx) error {
+		ids := make(map[string]any)
+		for _, t := range sortedTypes {
+			err := loadFixtures(ctx, tx, dir, t, ids)
+			if err != nil {
+				return errors.Wrap(err, errmsg)
+			}
+		}
+
+		return nil
+	})
+}
+
+// loadFixtures inserts the fixtures of a type, and adds the IDs of the inserted objects to ids by label.
+func loadFixtures(ctx context.Context, tx *Tx, dir string, t reflect.Type, ids map[string]any) error {
+	fixtures, err := readFixtureFile(dir, tx.unqualifiedNamingStrategy().TableName(t))
+	if err != nil {
+		return err
+	}
+
+	labels := make([]string, 0, len(fixtures))
+	for label := range fixtures {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		if _, exists := ids[label]; exists {
+			return errors.New(fmt.Sprintf("fixture label %s is used more than once", label))
+		}
+
+		object, err := buildFixture(t, fixtures[label], ids, tx.namingStrategy())
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("invalid fixture %s", label))
+		}
+
+		err = tx.InsertCtx(ctx, object)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not insert fixture %s", label))
+		}
+
+		ids[label], err = getIDValue(object)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFixtureFile reads the fixtures of a table, by label, from the first fixture file of the table found in dir.
+func readFixtureFile(dir string, tableName string) (map[string]map[string]any, error) {
+	for _, extension := range fixtureExtensions {
+		path := filepath.Join(dir, tableName+extension)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fixtures := make(map[string]map[string]any)
+		if extension == ".json" {
+			err = json.Unmarshal(data, &fixtures)
+		} else {
+			err = yaml.Unmarshal(data, &fixtures)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("could not parse %s", path))
+		}
+
+		return fixtures, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("no fixture file found for table %s", tableName))
+}
+
+// buildFixture builds an object of the type from the values of a fixture, by field or column name. References to other
+// fixtures are replaced by their IDs, and the values are then decoded into the fields as json.
+func buildFixture(t reflect.Type, values map[string]any, ids map[string]any,
+	naming NamingStrategy) (any, error) {
+	jsonKeys := make(map[string]string)
+	for _, field := range columnFields(t) {
+		jsonKey := field.Name
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+			jsonKey = name
+		}
+
+		jsonKeys[field.Name] = jsonKey
+		jsonKeys[columnName(field, naming)] = jsonKey
+	}
+
+	jsonValues := make(map[string]any)
+	for key, value := range values {
+		jsonKey, ok := jsonKeys[key]
+		if !ok || jsonKey == "-" {
+			return nil, errors.New(fmt.Sprintf("type %s does not have a field for %s", t.Name(), key))
+		}
+
+		if reference, ok := value.(string); ok && strings.HasPrefix(reference, "$") {
+			id, ok := ids[reference[1:]]
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("unknown fixture %s referenced by %s", reference[1:], key))
+			}
+			value = id
+		}
+
+		jsonValues[jsonKey] = value
+	}
+
+	data, err := json.Marshal(jsonValues)
+	if err != nil {
+		return nil, err
+	}
+
+	object := reflect.New(t).Interface()
+	err = json.Unmarshal(data, object)
+	if err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
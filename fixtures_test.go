@@ -0,0 +1,48 @@
+package liteorm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildFixture(t *testing.T) {
+	ids := map[string]any{"alice": int64(7)}
+	object, err := buildFixture(TestOrderType, map[string]any{"test_user_id": "$alice", "Amount": 3}, ids,
+		DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not build fixture - %s", err.Error())
+	}
+
+	if order := object.(*TestOrder); order.TestUserID != 7 || order.Amount != 3 {
+		t.Errorf("incorrect fixture - %+v", order)
+	}
+
+	_, err = buildFixture(TestOrderType, map[string]any{"test_user_id": "$bob"}, ids, DefaultNaming{})
+	if err == nil {
+		t.Errorf("unknown reference was resolved")
+	}
+
+	_, err = buildFixture(TestOrderType, map[string]any{"Price": 3}, ids, DefaultNaming{})
+	if err == nil {
+		t.Errorf("unknown field was set")
+	}
+}
+
+func TestReadFixtureFile(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "testusers.yml"), []byte("alice:\n  name: Alice\n"), 0644)
+	if err != nil {
+		t.Fatalf("could not write fixture file - %s", err.Error())
+	}
+
+	fixtures, err := readFixtureFile(dir, "testusers")
+	if err != nil || fixtures["alice"]["name"] != "Alice" {
+		t.Errorf("could not read fixture file - %v", fixtures)
+	}
+
+	_, err = readFixtureFile(dir, "testorders")
+	if err == nil {
+		t.Errorf("missing fixture file was read")
+	}
+}
@@ -0,0 +1,306 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+// goTypes maps the column types reported by InspectTable to the Go types of the generated fields.
+var goTypes = map[string]string{
+	"boolean":   "bool",
+	"smallint":  "int16",
+	"int":       "int",
+	"bigint":    "int64",
+	"float4":    "float32",
+	"float8":    "float64",
+	"timestamp": "time.Time",
+	"uuid":      "pgtype.UUID",
+	"bytea":     "[]byte",
+	"jsonb":     "map[string]any",
+	"numeric":   "pgtype.Numeric",
+	"inet":      "net.IP",
+	"cidr":      "net.IPNet",
+	"interval":  "time.Duration",
+}
+
+// initialisms are the words of column names that are written in upper case in field names, following Go conventions.
+var initialisms = map[string]bool{
+	"id":   true,
+	"uuid": true,
+	"url":  true,
+	"uri":  true,
+	"api":  true,
+	"http": true,
+	"json": true,
+	"sql":  true,
+	"ip":   true,
+}
+
+// GenerateModels generates the Go source of a package with a struct for each of the live tables received as argument,
+// or for all tables of the schema of the database if none are given. The fields carry the tags that map them back to
+// the columns, so the structs can be used with liteorm as they are. Columns of types without a Go counterpart are
+// generated as strings with an explicit column type, and may need to be adjusted.
+func (db *Database) GenerateModels(packageName string, tableNames ...string) ([]byte, error) {
+	return db.GenerateModelsCtx(context.Background(), packageName, tableNames...)
+}
+
+func (db *Database) GenerateModelsCtx(ctx context.Context, packageName string, tableNames ...string) ([]byte, error) {
+	errmsg := "could not generate models"
+
+	if len(tableNames) == 0 {
+		var err error
+		tableNames, err = db.listTables(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, errmsg)
+		}
+	}
+
+	tables := make([]*TableInfo, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		info, err := db.InspectTableCtx(ctx, tableName)
+		if err != nil {
+			return nil, errors.Wrap(err, errmsg)
+		}
+
+		tables = append(tables, info)
+	}
+
+	source, err := generateModels(packageName, tables)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	return source, nil
+}
+
+// listTables returns the names of the tables of the schema of the database.
+func (db *Database) listTables(ctx context.Context) ([]string, error) {
+	rows, err := db.session().Query(ctx, `
+        select table_name
+        from information_schema.tables
+        where table_schema = $1
+        and table_type = 'BASE TABLE'
+        order by table_name;`, db.schemaName())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tableNames := make([]string, 0)
+	for rows.Next() {
+		var tableName string
+		err = rows.Scan(&tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		tableNames = append(tableNames, tableName)
+	}
+
+	return tableNames, rows.Err()
+}
+
+// generateModels generates the formatted source of a package with the structs of the tables received as argument.
+func generateModels(packageName string, tables []*TableInfo) ([]byte, error) {
+	imports := make(map[string]bool)
+	var models strings.Builder
+	for _, info := range tables {
+		models.WriteString(generateModel(info, imports))
+	}
+
+	var source strings.Builder
+	source.WriteString("// Code generated by liteorm from the schema of the database.\n\n")
+	source.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	if len(imports) > 0 {
+		importPaths := make([]string, 0, len(imports))
+		for importPath := range imports {
+			importPaths = append(importPaths, importPath)
+		}
+		sort.Strings(importPaths)
+
+		source.WriteString("import (\n")
+		for _, importPath := range importPaths {
+			source.WriteString(fmt.Sprintf("\t%q\n", importPath))
+		}
+		source.WriteString(")\n\n")
+	}
+	source.WriteString(models.String())
+
+	return format.Source([]byte(source.String()))
+}
+
+// generateModel generates the struct of a table, and adds the packages it uses to the imports.
+func generateModel(info *TableInfo, imports map[string]bool) string {
+	primaryKey := constraintColumns(info, "primary key")
+	foreignKeys := constraintColumns(info, "foreign key")
+	structName := exportedName(singularize(info.Name))
+
+	var model strings.Builder
+	model.WriteString(fmt.Sprintf("type %s struct {\n", structName))
+	for _, column := range info.Columns {
+		fieldName := exportedName(column.Name)
+		fieldType, columnType := generateFieldType(column)
+		if strings.Contains(fieldType, "time.") {
+			imports["time"] = true
+		}
+		if strings.Contains(fieldType, "pgtype.") {
+			imports["github.com/jackc/pgx/v5/pgtype"] = true
+		}
+		if strings.Contains(fieldType, "net.") {
+			imports["net"] = true
+		}
+
+		tags := make([]string, 0)
+		if SnakeCase(fieldName) != column.Name {
+			tags = append(tags, fmt.Sprintf("column:%q", column.Name))
+		}
+
+		switch {
+		case primaryKey[column.Name] != "":
+			tags = append(tags, `pgsql:"primary key"`)
+		case !column.Nullable:
+			tags = append(tags, `pgsql:"not null"`)
+		}
+
+		if strings.HasPrefix(column.Type, "varchar(") {
+			length := column.Type[len("varchar("):strings.Index(column.Type, ")")]
+			tags = append(tags, fmt.Sprintf("pglen:%q", length))
+		}
+
+		if strings.HasPrefix(column.Type, "numeric(") {
+			precisionAndScale := column.Type[len("numeric("):strings.Index(column.Type, ")")]
+			tags = append(tags, fmt.Sprintf("numeric:%q", precisionAndScale))
+		}
+
+		if references := foreignKeys[column.Name]; references != "" {
+			tags = append(tags, fmt.Sprintf("fk:%q", references))
+		}
+
+		if columnType != "" {
+			tags = append(tags, fmt.Sprintf(`liteorm:"type:%s"`, columnType))
+		}
+
+		model.WriteString(fmt.Sprintf("\t%s %s", fieldName, fieldType))
+		if len(tags) > 0 {
+			model.WriteString(fmt.Sprintf(" `%s`", strings.Join(tags, " ")))
+		}
+		model.WriteString("\n")
+	}
+	model.WriteString("}\n\n")
+
+	// the table name is only set explicitly if the default naming does not derive it from the struct name
+	if pluralize(structName) != info.Name {
+		model.WriteString(fmt.Sprintf("func (%s) TableName() string {\n\treturn %q\n}\n\n", structName, info.Name))
+	}
+
+	return model.String()
+}
+
+// generateFieldType returns the Go type of the field of a column. If the column type is not the one liteorm generates
+// for that Go type, it is returned as well, to be set explicitly with the liteorm tag.
+func generateFieldType(column ColumnInfo) (string, string) {
+	columnType := column.Type
+	prefix := ""
+	for strings.HasSuffix(columnType, "[]") {
+		columnType = strings.TrimSuffix(columnType, "[]")
+		prefix += "[]"
+	}
+
+	goType, ok := goTypes[columnType]
+	if strings.HasPrefix(columnType, "varchar(") {
+		goType, ok = "string", true
+	}
+
+	if strings.HasPrefix(columnType, "numeric(") {
+		goType, ok = "pgtype.Numeric", true
+	}
+
+	if !ok {
+		return prefix + "string", column.Type
+	}
+
+	// nullable columns map to pointers, except for the types that are nil themselves
+	if column.Nullable && prefix == "" && goType != "[]byte" && goType != "map[string]any" && goType != "net.IP" {
+		goType = "*" + goType
+	}
+
+	return prefix + goType, ""
+}
+
+// constraintColumns returns the columns constrained by the single column constraints of the type received as argument,
+// mapped to the rest of the constraint definition, e.g. the referenced table of a foreign key, or to the constraint
+// type if there is no such rest.
+func constraintColumns(info *TableInfo, constraintType string) map[string]string {
+	columns := make(map[string]string)
+	for _, constraint := range info.Constraints {
+		if constraint.Type != constraintType {
+			continue
+		}
+
+		// definitions have the form PRIMARY KEY (id) or FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		start, end := strings.Index(constraint.Definition, "("), strings.Index(constraint.Definition, ")")
+		if start < 0 || end < start || strings.Contains(constraint.Definition[start:end], ",") {
+			continue
+		}
+
+		column := strings.Trim(constraint.Definition[start+1:end], `"`)
+		rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(constraint.Definition[end+1:]), "REFERENCES"))
+		if rest == "" {
+			rest = constraintType
+		}
+
+		columns[column] = strings.ToLower(rest)
+	}
+
+	return columns
+}
+
+// exportedName converts a snake case name to an exported Go name, e.g. user_id to UserID.
+func exportedName(name string) string {
+	var result strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+
+		if initialisms[word] {
+			result.WriteString(strings.ToUpper(word))
+		} else {
+			result.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	return result.String()
+}
+
+// singularize returns the singular form of a plural noun, reversing pluralize for the nouns it generates.
+func singularize(noun string) string {
+	longestPlural, singularForm := "", ""
+	for singular, plural := range irregularPlurals {
+		if strings.HasSuffix(noun, plural) && len(plural) > len(longestPlural) {
+			longestPlural, singularForm = plural, singular
+		}
+	}
+
+	if longestPlural != "" {
+		return strings.TrimSuffix(noun, longestPlural) + singularForm
+	}
+
+	switch {
+	case strings.HasSuffix(noun, "ies") && len(noun) > 3:
+		return strings.TrimSuffix(noun, "ies") + "y"
+
+	case strings.HasSuffix(noun, "ses"), strings.HasSuffix(noun, "xes"), strings.HasSuffix(noun, "zes"),
+		strings.HasSuffix(noun, "ches"), strings.HasSuffix(noun, "shes"):
+		return strings.TrimSuffix(noun, "es")
+
+	case strings.HasSuffix(noun, "s") && !strings.HasSuffix(noun, "ss"):
+		return strings.TrimSuffix(noun, "s")
+	}
+
+	return noun
+}
@@ -0,0 +1,65 @@
+package liteorm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSingularize(t *testing.T) {
+	nouns := []string{"user", "category", "status", "box", "person", "child", "address", "testitem"}
+	for _, noun := range nouns {
+		if singular := singularize(pluralize(noun)); singular != noun {
+			t.Errorf("incorrect singular of %s - %s instead of %s", pluralize(noun), singular, noun)
+		}
+	}
+}
+
+func TestGenerateModels(t *testing.T) {
+	info := &TableInfo{
+		Name: "blog_posts",
+		Columns: []ColumnInfo{
+			{Name: "id", Type: "bigint", Default: "nextval('blog_posts_id_seq'::regclass)"},
+			{Name: "title", Type: "varchar(100)"},
+			{Name: "author_id", Type: "bigint"},
+			{Name: "published_at", Type: "timestamp", Nullable: true},
+			{Name: "tags", Type: "varchar(25)[]", Nullable: true},
+			{Name: "rating", Type: "numeric(3,1)", Nullable: true},
+			{Name: "location", Type: "point", Nullable: true},
+			{Name: "author_ip", Type: "inet", Nullable: true},
+		},
+		Constraints: []ConstraintInfo{
+			{Name: "blog_posts_pkey", Type: "primary key", Definition: "PRIMARY KEY (id)"},
+			{Name: "blog_posts_author_id_fkey", Type: "foreign key",
+				Definition: "FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE CASCADE"},
+		},
+	}
+
+	source, err := generateModels("models", []*TableInfo{info})
+	if err != nil {
+		t.Fatalf("could not generate models - %s", err.Error())
+	}
+
+	expected := []string{
+		"package models",
+		`"time"`,
+		`"github.com/jackc/pgx/v5/pgtype"`,
+		`"net"`,
+		"type BlogPost struct {",
+		"ID          int64      `pgsql:\"primary key\"`",
+		"Title       string     `pgsql:\"not null\" pglen:\"100\"`",
+		"AuthorID    int64      `pgsql:\"not null\" fk:\"authors(id) on delete cascade\"`",
+		"PublishedAt *time.Time",
+		"Tags        []string   `pglen:\"25\"`",
+		"Rating      *pgtype.Numeric `numeric:\"3,1\"`",
+		"Location    string     `liteorm:\"type:point\"`",
+		"AuthorIP    net.IP",
+		`func (BlogPost) TableName() string { return "blog_posts" }`,
+	}
+	// the alignment of the fields is left to gofmt, so whitespace is not compared
+	generated := strings.Join(strings.Fields(string(source)), " ")
+	for _, part := range expected {
+		if !strings.Contains(generated, strings.Join(strings.Fields(part), " ")) {
+			t.Errorf("generated source does not contain %s:\n%s", part, source)
+		}
+	}
+}
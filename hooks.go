@@ -0,0 +1,84 @@
+package liteorm
+
+import (
+	"context"
+	"reflect"
+)
+
+// Model types can implement the following interfaces to be notified of the operations performed on them. The hooks are
+// called with the context of the operation, and an error returned by a hook aborts the operation. Hooks are usually
+// implemented with pointer receivers, so that they can modify the object.
+
+// BeforeInserter is implemented by types that are notified before an object is inserted (by Insert, InsertMany,
+// Upsert, or CopyFrom).
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInserter is implemented by types that are notified after an object is inserted, once its ID field is set.
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// AfterSelecter is implemented by types that are notified after an object is selected and its fields are set.
+type AfterSelecter interface {
+	AfterSelect(ctx context.Context) error
+}
+
+// BeforeUpdater is implemented by types that are notified before an object is updated.
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdater is implemented by types that are notified after an object is updated.
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleter is implemented by types that are notified before objects are deleted. Since Delete operates on a type
+// rather than on objects, the hook is called on a zero value of the type with the clauses and arguments of the delete.
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context, clauses string, args ...any) error
+}
+
+func beforeInsert(ctx context.Context, arg any) error {
+	if hook, ok := arg.(BeforeInserter); ok {
+		return hook.BeforeInsert(ctx)
+	}
+	return nil
+}
+
+func afterInsert(ctx context.Context, arg any) error {
+	if hook, ok := arg.(AfterInserter); ok {
+		return hook.AfterInsert(ctx)
+	}
+	return nil
+}
+
+func afterSelect(ctx context.Context, arg any) error {
+	if hook, ok := arg.(AfterSelecter); ok {
+		return hook.AfterSelect(ctx)
+	}
+	return nil
+}
+
+func beforeUpdate(ctx context.Context, arg any) error {
+	if hook, ok := arg.(BeforeUpdater); ok {
+		return hook.BeforeUpdate(ctx)
+	}
+	return nil
+}
+
+func afterUpdate(ctx context.Context, arg any) error {
+	if hook, ok := arg.(AfterUpdater); ok {
+		return hook.AfterUpdate(ctx)
+	}
+	return nil
+}
+
+func beforeDelete(ctx context.Context, t reflect.Type, clauses string, args ...any) error {
+	if hook, ok := reflect.New(t).Interface().(BeforeDeleter); ok {
+		return hook.BeforeDelete(ctx, clauses, args...)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package liteorm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The ID strategies, selected with the "id" option in the liteorm tag of ID fields, e.g. `liteorm:"id:identity"`.
+// Integer IDs default to IDSerial and UUID IDs to IDUUID, which are generated by the database along with IDIdentity,
+// while IDSnowflake, IDKSUID and the strategies registered with RegisterIDGenerator are generated by liteorm before
+// the objects are inserted.
+const (
+	// IDSerial generates integer IDs with a bigserial column
+	IDSerial = "serial"
+
+	// IDIdentity generates integer IDs with a bigint generated always as identity column
+	IDIdentity = "identity"
+
+	// IDUUID generates random UUIDs with gen_random_uuid
+	IDUUID = "uuid"
+
+	// IDSnowflake generates time ordered int64 IDs, see SnowflakeGenerator
+	IDSnowflake = "snowflake"
+
+	// IDKSUID generates time ordered 27 character string IDs, see KSUIDGenerator
+	IDKSUID = "ksuid"
+)
+
+// IDGenerator generates the IDs of new objects on the client, for the ID strategies registered with
+// RegisterIDGenerator. The ID must be convertible to the type of the ID field. Integer IDs of string ID fields are
+// formatted in decimal.
+type IDGenerator interface {
+	NextID() (any, error)
+}
+
+// IDGeneratorFunc is an IDGenerator implemented by a function.
+type IDGeneratorFunc func() (any, error)
+
+func (f IDGeneratorFunc) NextID() (any, error) {
+	return f()
+}
+
+var (
+	idGeneratorsMutex sync.RWMutex
+	idGenerators      = map[string]IDGenerator{
+		IDSnowflake: NewSnowflakeGenerator(0),
+		IDKSUID:     KSUIDGenerator{},
+	}
+)
+
+// RegisterIDGenerator registers the generator of the ID strategy received as argument, which is selected by the ID
+// fields tagged with its name, e.g. `liteorm:"id:ulid"` for RegisterIDGenerator("ulid", ...). Registering a built-in
+// client side strategy replaces its generator, e.g. to set the node of IDSnowflake. The column type of the ID fields of
+// registered strategies is derived from their Go type like for the other fields.
+func RegisterIDGenerator(strategy string, generator IDGenerator) {
+	idGeneratorsMutex.Lock()
+	defer idGeneratorsMutex.Unlock()
+	idGenerators[strategy] = generator
+
+	// the inserted columns depend on the registered strategies
+	clearStatementCache()
+}
+
+// getIDGenerator returns the generator of a client side ID strategy.
+func getIDGenerator(strategy string) (IDGenerator, bool) {
+	idGeneratorsMutex.RLock()
+	defer idGeneratorsMutex.RUnlock()
+	generator, ok := idGenerators[strategy]
+	return generator, ok
+}
+
+// getIDStrategy returns the ID strategy of an ID field, i.e. the one of its tag or the default of its type. Fields of
+// other types have no strategy, and their values are set by the client.
+func getIDStrategy(field reflect.StructField) string {
+	if strategy := parseTag(field)["id"]; strategy != "" {
+		return strategy
+	}
+
+	if isIntegerType(field.Type) {
+		return IDSerial
+	}
+
+	if isUUIDType(field.Type) {
+		return IDUUID
+	}
+
+	return ""
+}
+
+// isClientGeneratedID reports whether a field is an ID field generated by liteorm rather than by the database, which is
+// then part of the inserted columns.
+func isClientGeneratedID(field reflect.StructField) bool {
+	if field.Name != "ID" {
+		return false
+	}
+
+	_, ok := getIDGenerator(getIDStrategy(field))
+	return ok
+}
+
+// isInsertedField reports whether the column of a field is set when inserting an object, i.e. whether it is neither
+// generated by the database nor an ID generated by the database.
+func isInsertedField(field reflect.StructField) bool {
+	return (field.Name != "ID" || isClientGeneratedID(field)) && !isGeneratedField(field)
+}
+
+// buildIDColumnType returns the column type of an ID field according to its strategy. It returns false for the ID fields
+// that are mapped like other fields, i.e. those of registered strategies and those without a strategy.
+func buildIDColumnType(field reflect.StructField) (string, bool, error) {
+	strategy := getIDStrategy(field)
+	switch strategy {
+	case IDSerial, IDIdentity, IDSnowflake:
+		if !isIntegerType(field.Type) {
+			return "", false, errors.New(fmt.Sprintf("ID strategy %s requires an integer ID field", strategy))
+		}
+	case IDUUID:
+		if !isUUIDType(field.Type) {
+			return "", false, errors.New(fmt.Sprintf("ID strategy %s requires a UUID ID field", strategy))
+		}
+	case IDKSUID:
+		if field.Type.Kind() != reflect.String {
+			return "", false, errors.New(fmt.Sprintf("ID strategy %s requires a string ID field", strategy))
+		}
+	}
+
+	switch strategy {
+	case IDSerial:
+		return idColumnType, true, nil
+	case IDIdentity:
+		return "bigint generated always as identity", true, nil
+	case IDUUID:
+		return uuidIDColumnType, true, nil
+	case IDSnowflake:
+		return "bigint", true, nil
+	case IDKSUID:
+		return "char(27)", true, nil
+	}
+
+	if _, ok := getIDGenerator(strategy); !ok && strategy != "" {
+		return "", false, errors.New(fmt.Sprintf("unknown ID strategy %s", strategy))
+	}
+
+	return "", false, nil
+}
+
+// buildIDReferenceType returns the column type of the columns referencing an ID field, e.g. those of join tables, i.e.
+// the column type of the ID without the generation of the database.
+func buildIDReferenceType(field reflect.StructField) (string, error) {
+	columnType, err := buildColumnType(field)
+	if err != nil {
+		return "", err
+	}
+
+	switch getIDStrategy(field) {
+	case IDSerial, IDIdentity:
+		return "bigint", nil
+	case IDUUID:
+		return "uuid", nil
+	}
+
+	return columnType, nil
+}
+
+// setGeneratedID sets the ID field of an object about to be inserted, if it is generated by liteorm and not set yet.
+func setGeneratedID(arg any) error {
+	argv, err := getObjectValue(arg)
+	if err != nil {
+		return err
+	}
+
+	field, ok := argv.Type().FieldByName("ID")
+	if !ok || !isClientGeneratedID(field) {
+		return nil
+	}
+
+	fieldv := argv.FieldByIndex(field.Index)
+	if !fieldv.IsZero() {
+		return nil
+	}
+
+	if !argv.CanSet() {
+		return errors.New("could not set the generated ID of an object passed by value")
+	}
+
+	generator, _ := getIDGenerator(getIDStrategy(field))
+	id, err := generator.NextID()
+	if err != nil {
+		return errors.Wrap(err, "could not generate ID")
+	}
+
+	idv := reflect.ValueOf(id)
+	if !idv.IsValid() {
+		return errors.New(fmt.Sprintf("generated ID %v cannot be converted to %s", id, field.Type))
+	}
+
+	// numbers are convertible to strings, but as runes, so they are formatted in decimal instead
+	if field.Type.Kind() == reflect.String {
+		switch idv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			idv = reflect.ValueOf(strconv.FormatInt(idv.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			idv = reflect.ValueOf(strconv.FormatUint(idv.Uint(), 10))
+		}
+	}
+
+	if !idv.Type().ConvertibleTo(field.Type) {
+		return errors.New(fmt.Sprintf("generated ID %v cannot be converted to %s", id, field.Type))
+	}
+
+	fieldv.Set(idv.Convert(field.Type))
+	return nil
+}
+
+// snowflakeEpoch is the start of the timestamps of the snowflake IDs, 2020-01-01 UTC in milliseconds.
+const snowflakeEpoch = 1577836800000
+
+// SnowflakeGenerator generates the int64 IDs of IDSnowflake, which are made of the milliseconds since 2020, the node
+// of the generator on 10 bits and a sequence number on 12 bits. The IDs are ordered by creation time, and unique as
+// long as each process generating IDs for the same table has its own node.
+type SnowflakeGenerator struct {
+	mutex     sync.Mutex
+	node      int64
+	timestamp int64
+	sequence  int64
+}
+
+// NewSnowflakeGenerator returns a snowflake generator with the node received as argument, between 0 and 1023.
+func NewSnowflakeGenerator(node int64) *SnowflakeGenerator {
+	return &SnowflakeGenerator{node: node & 0x3ff}
+}
+
+func (g *SnowflakeGenerator) NextID() (any, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	timestamp := time.Now().UnixMilli() - snowflakeEpoch
+	if timestamp < g.timestamp {
+		// the clock went backwards, so the IDs keep the last timestamp until it catches up
+		timestamp = g.timestamp
+	}
+
+	if timestamp == g.timestamp {
+		g.sequence = (g.sequence + 1) & 0xfff
+		if g.sequence == 0 {
+			// the sequence of the millisecond is exhausted
+			for timestamp <= g.timestamp {
+				timestamp = time.Now().UnixMilli() - snowflakeEpoch
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+
+	g.timestamp = timestamp
+	return timestamp<<22 | g.node<<12 | g.sequence, nil
+}
+
+// ksuidEpoch is the start of the timestamps of KSUIDs, in seconds since the Unix epoch.
+const ksuidEpoch = 1400000000
+
+// ksuidAlphabet is the base62 alphabet of KSUIDs.
+const ksuidAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// KSUIDGenerator generates the string IDs of IDKSUID, i.e. K-sortable unique IDs made of the seconds since the KSUID
+// epoch and 16 random bytes, encoded in 27 base62 characters.
+type KSUIDGenerator struct{}
+
+func (KSUIDGenerator) NextID() (any, error) {
+	payload := make([]byte, 20)
+	binary.BigEndian.PutUint32(payload, uint32(time.Now().Unix()-ksuidEpoch))
+	if _, err := rand.Read(payload[4:]); err != nil {
+		return nil, err
+	}
+
+	value := new(big.Int).SetBytes(payload)
+	base := big.NewInt(int64(len(ksuidAlphabet)))
+	digit := new(big.Int)
+	encoded := make([]byte, 27)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		value.DivMod(value, base, digit)
+		encoded[i] = ksuidAlphabet[digit.Int64()]
+	}
+
+	return string(encoded), nil
+}
@@ -0,0 +1,187 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// TableInfo describes a table of the live database, as returned by InspectTable.
+type TableInfo struct {
+	Schema      string
+	Name        string
+	Columns     []ColumnInfo
+	Indexes     []IndexInfo
+	Constraints []ConstraintInfo
+}
+
+// ColumnInfo describes a column of a live table. Its type is given in the form generated by liteorm, e.g. varchar(255)
+// or int[], so that it can be compared with the type of a field.
+type ColumnInfo struct {
+	Name     string
+	Type     string
+	Nullable bool
+
+	// Default is the default expression of the column, empty if it does not have one
+	Default string
+}
+
+// IndexInfo describes an index of a live table, including the indexes backing primary keys and unique constraints.
+type IndexInfo struct {
+	Name       string
+	Unique     bool
+	Primary    bool
+	Definition string
+}
+
+// ConstraintInfo describes a constraint of a live table. Its type is one of "primary key", "foreign key", "unique",
+// "check" and "exclusion".
+type ConstraintInfo struct {
+	Name       string
+	Type       string
+	Definition string
+}
+
+// constraintTypes maps the constraint types of pg_constraint to their names.
+var constraintTypes = map[string]string{
+	"p": "primary key",
+	"f": "foreign key",
+	"u": "unique",
+	"c": "check",
+	"x": "exclusion",
+}
+
+const inspectColumnsStatement = `
+        select column_name, udt_name, coalesce(character_maximum_length, 0), coalesce(numeric_precision, 0),
+            coalesce(numeric_scale, 0), is_nullable = 'YES', coalesce(column_default, '')
+        from information_schema.columns
+        where table_schema = $1
+        and table_name = $2
+        order by ordinal_position;`
+
+const inspectIndexesStatement = `
+        select i.relname, ix.indisunique, ix.indisprimary, pg_get_indexdef(ix.indexrelid)
+        from pg_index ix
+        join pg_class i on i.oid = ix.indexrelid
+        join pg_class t on t.oid = ix.indrelid
+        join pg_namespace n on n.oid = t.relnamespace
+        where n.nspname = $1
+        and t.relname = $2
+        order by i.relname;`
+
+const inspectConstraintsStatement = `
+        select c.conname, c.contype::text, pg_get_constraintdef(c.oid)
+        from pg_constraint c
+        join pg_class t on t.oid = c.conrelid
+        join pg_namespace n on n.oid = t.relnamespace
+        where n.nspname = $1
+        and t.relname = $2
+        order by c.conname;`
+
+// InspectTable returns the columns, indexes and constraints of the live table whose name is received as argument. The
+// name may be qualified with a schema, and otherwise refers to the schema of the database. If the table does not exist,
+// ErrNotFound is returned.
+func (db *Database) InspectTable(name string) (*TableInfo, error) {
+	return db.InspectTableCtx(context.Background(), name)
+}
+
+func (db *Database) InspectTableCtx(ctx context.Context, name string) (*TableInfo, error) {
+	errmsg := fmt.Sprintf("could not inspect table %s", name)
+
+	info := &TableInfo{Schema: db.schemaName(), Name: name}
+	if i := strings.Index(name, "."); i >= 0 {
+		info.Schema, info.Name = name[:i], name[i+1:]
+	}
+
+	s := db.session()
+	columns, err := inspectColumns(ctx, s, info.Schema, info.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	if len(columns) == 0 {
+		return nil, errors.Wrap(ErrNotFound, errmsg)
+	}
+	info.Columns = columns
+
+	rows, err := s.Query(ctx, inspectIndexesStatement, info.Schema, info.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var index IndexInfo
+		err = rows.Scan(&index.Name, &index.Unique, &index.Primary, &index.Definition)
+		if err != nil {
+			return nil, errors.Wrap(err, errmsg)
+		}
+
+		info.Indexes = append(info.Indexes, index)
+	}
+
+	if rows.Err() != nil {
+		return nil, errors.Wrap(rows.Err(), errmsg)
+	}
+
+	rows, err = s.Query(ctx, inspectConstraintsStatement, info.Schema, info.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var constraint ConstraintInfo
+		var constraintType string
+		err = rows.Scan(&constraint.Name, &constraintType, &constraint.Definition)
+		if err != nil {
+			return nil, errors.Wrap(err, errmsg)
+		}
+
+		constraint.Type = constraintTypes[constraintType]
+		info.Constraints = append(info.Constraints, constraint)
+	}
+
+	if rows.Err() != nil {
+		return nil, errors.Wrap(rows.Err(), errmsg)
+	}
+
+	return info, nil
+}
+
+// Column returns the column of the table whose name is received as argument.
+func (info *TableInfo) Column(name string) (ColumnInfo, bool) {
+	for _, column := range info.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+
+	return ColumnInfo{}, false
+}
+
+// inspectColumns returns the columns of a live table, which are empty if the table does not exist.
+func inspectColumns(ctx context.Context, s *session, schema string, tableName string) ([]ColumnInfo, error) {
+	rows, err := s.Query(ctx, inspectColumnsStatement, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make([]ColumnInfo, 0)
+	for rows.Next() {
+		var column ColumnInfo
+		var udtName string
+		var maxLength, precision, scale int
+		err = rows.Scan(&column.Name, &udtName, &maxLength, &precision, &scale, &column.Nullable, &column.Default)
+		if err != nil {
+			return nil, err
+		}
+
+		column.Type = mapUDTColumnType(udtName, maxLength, precision, scale)
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
@@ -0,0 +1,11 @@
+// Package columns shares the mapping of columns to fields of liteorm with liteormtest, without exporting it from
+// liteorm.
+package columns
+
+import (
+	"reflect"
+)
+
+// FindField returns the field of the type that maps to the column received as argument with the naming strategy,
+// which is a liteorm.NamingStrategy. It is set by liteorm when it is initialized.
+var FindField func(t reflect.Type, column string, naming any) (reflect.StructField, bool)
@@ -0,0 +1,206 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+// joinComponent is a field of a composite struct that receives the columns of one of the joined tables.
+type joinComponent struct {
+	field    reflect.StructField
+	t        reflect.Type
+	nullable bool
+}
+
+// SelectJoined selects the rows of the joins of the query into dest, a pointer to a slice of composite structs whose
+// fields, embedded or not, are the models of the joined tables, e.g.
+//
+//	type UserOrder struct {
+//		User  User
+//		Order *Order
+//	}
+//
+//	var result []UserOrder
+//	db.SelectJoined(&result, NewQuery().LeftJoin("orders", "orders.user_id = users.id"))
+//
+// The table of the first model is the one selected from, and the other tables are joined by the query. Models that may
+// be missing from a row, i.e. those joined with LeftJoin, must be pointers, which are left nil if the ID of the model is
+// null. Soft deleted rows of the first model are left out unless the database is unscoped, while those of the joined
+// tables have to be excluded by the join conditions.
+func (db *Database) SelectJoined(dest any, q *Query) error {
+	return db.SelectJoinedCtx(context.Background(), dest, q)
+}
+
+func (db *Database) SelectJoinedCtx(ctx context.Context, dest any, q *Query) error {
+	return db.retry(ctx, false, func() error {
+		return selectJoined(ctx, db.readSession(), dest, q)
+	})
+}
+
+func (tx *Tx) SelectJoined(dest any, q *Query) error {
+	return tx.SelectJoinedCtx(context.Background(), dest, q)
+}
+
+func (tx *Tx) SelectJoinedCtx(ctx context.Context, dest any, q *Query) error {
+	return selectJoined(ctx, tx.session(), dest, q)
+}
+
+func selectJoined(ctx context.Context, s *session, dest any, q *Query) error {
+	destv := reflect.ValueOf(dest)
+	if destv.Kind() != reflect.Ptr || destv.IsNil() || destv.Elem().Kind() != reflect.Slice ||
+		destv.Elem().Type().Elem().Kind() != reflect.Struct {
+		return errors.New("could not select joined rows - provided argument is not a pointer to a slice of structs")
+	}
+	destv = destv.Elem()
+
+	compositet := destv.Type().Elem()
+	errmsg := fmt.Sprintf("could not select joined rows of type %s", compositet.Name())
+
+	components, err := joinComponents(compositet)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	statement, args := q.buildStatement(components[0].t, s.tenant, func(clauses string, tenantIdx int) string {
+		return buildSelectJoinedStatement(components, clauses, s.unscoped, tenantIdx, s.namingStrategy())
+	})
+	rows, err := s.Query(ctx, statement, args...)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+	defer rows.Close()
+
+	result := reflect.MakeSlice(destv.Type(), 0, 0)
+	for rows.Next() {
+		// the columns of nullable components are scanned through an additional pointer, which is nil for null columns
+		componentValues := make([][]any, len(components))
+		scanValues := make([]any, 0)
+		for i, component := range components {
+			componentValues[i] = buildSliceFromFields(component.t)
+			for _, value := range componentValues[i] {
+				if component.nullable {
+					value = reflect.New(reflect.TypeOf(value)).Interface()
+				}
+				scanValues = append(scanValues, value)
+			}
+		}
+
+		err = rows.Scan(scanValues...)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		composite := reflect.New(compositet).Elem()
+		offset := 0
+		for i, component := range components {
+			values := componentValues[i]
+			scanned := scanValues[offset : offset+len(values)]
+			offset += len(values)
+
+			if component.nullable && !setNullableValues(component.t, values, scanned) {
+				continue
+			}
+
+			object := reflect.New(component.t)
+			err = s.decryptValues(values)
+			if err == nil {
+				err = setObjectFields(object.Interface(), values...)
+			}
+			if err != nil {
+				return errors.Wrap(err, errmsg)
+			}
+
+			err = afterSelect(ctx, object.Interface())
+			if err != nil {
+				return errors.Wrap(err, errmsg)
+			}
+
+			fieldv := composite.FieldByIndex(component.field.Index)
+			if component.nullable {
+				fieldv.Set(object)
+			} else {
+				fieldv.Set(object.Elem())
+			}
+		}
+
+		result = reflect.Append(result, composite)
+	}
+
+	if rows.Err() != nil {
+		return errors.Wrap(rows.Err(), errmsg)
+	}
+
+	destv.Set(result)
+	return nil
+}
+
+// joinComponents returns the fields of the composite type that hold the models of the joined tables, i.e. the fields
+// of struct types, or pointers to them, with an ID field.
+func joinComponents(compositet reflect.Type) ([]joinComponent, error) {
+	components := make([]joinComponent, 0)
+	for i := 0; i < compositet.NumField(); i++ {
+		field := compositet.Field(i)
+		component := joinComponent{field: field, t: field.Type}
+		if component.t.Kind() == reflect.Ptr {
+			component.t = component.t.Elem()
+			component.nullable = true
+		}
+
+		if _, hasID := component.t.FieldByName("ID"); component.t.Kind() != reflect.Struct || !hasID {
+			return nil, errors.New(fmt.Sprintf("field %s is not a model with an ID field", field.Name))
+		}
+
+		components = append(components, component)
+	}
+
+	if len(components) == 0 {
+		return nil, errors.New("type does not have any model fields")
+	}
+
+	if components[0].nullable {
+		return nil, errors.New(fmt.Sprintf("field %s of the table selected from is a pointer", components[0].field.Name))
+	}
+
+	return components, nil
+}
+
+// setNullableValues copies the values scanned through additional pointers into the values of a nullable component,
+// and reports whether the component is present in the row, i.e. whether its ID is not null.
+func setNullableValues(t reflect.Type, values []any, scanned []any) bool {
+	present := false
+	for i, field := range columnFields(t) {
+		pointer := reflect.ValueOf(scanned[i]).Elem()
+		if pointer.IsNil() {
+			continue
+		}
+
+		if field.Name == "ID" {
+			present = true
+		}
+		values[i] = pointer.Interface()
+	}
+
+	return present
+}
+
+// buildSelectJoinedStatement builds a statement that selects the columns of all components, qualified with their table
+// names, from the table of the first component and the joins of the clauses.
+func buildSelectJoinedStatement(components []joinComponent, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	columnNames := make([]string, 0)
+	for _, component := range components {
+		// columns are qualified with the unqualified table name, which is also the alias of soft deleted sources
+		parts := strings.Split(naming.TableName(component.t), ".")
+		tableName := quoteIdentifier(parts[len(parts)-1])
+		for _, field := range columnFields(component.t) {
+			columnNames = append(columnNames, fmt.Sprintf("%s.%s", tableName, quoteIdentifier(columnName(field,
+				naming))))
+		}
+	}
+
+	return fmt.Sprintf("select %s from %s %s;", strings.Join(columnNames, ","),
+		buildSelectSource(components[0].t, unscoped, tenantIdx, naming), clauses)
+}
@@ -0,0 +1,404 @@
+package liteormtest
+
+import (
+	"fmt"
+	"github.com/lashbits/liteorm"
+	"github.com/lashbits/liteorm/internal/columns"
+	"github.com/pkg/errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tokenPattern splits clauses into quoted identifiers, placeholders, operators, punctuation, words and numbers.
+var tokenPattern = regexp.MustCompile(`"[^"]+"|\$\d+|\?|<=|>=|<>|!=|=|<|>|,|;|[A-Za-z_][A-Za-z0-9_.]*|\d+|\S`)
+
+// predicate compares the column of an object with an argument, or checks whether it is null.
+type predicate struct {
+	column   string
+	operator string
+	argIndex int
+}
+
+// ordering sorts the objects by a column.
+type ordering struct {
+	column     string
+	descending bool
+}
+
+// filter is the parsed form of the clauses supported by FakeDB.
+type filter struct {
+	predicates []predicate
+	orderings  []ordering
+	limit      int
+	offset     int
+}
+
+// parseClauses parses clauses of the form "where a = $1 and b is null order by c desc limit 10 offset 20", and returns
+// an error for any other clause. The arguments are referenced either by numbered placeholders, i.e. $1, $2, etc., or by
+// positional ones, i.e. ?, which are numbered in order like Query.Build does, but not by both.
+func parseClauses(clauses string) (*filter, error) {
+	tokens := tokenPattern.FindAllString(clauses, -1)
+	if len(tokens) > 0 && tokens[len(tokens)-1] == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	p := &clauseParser{tokens: tokens}
+	f := &filter{limit: -1}
+	if p.accept("where") {
+		for {
+			predicate, err := p.parsePredicate()
+			if err != nil {
+				return nil, err
+			}
+
+			f.predicates = append(f.predicates, predicate)
+			if !p.accept("and") {
+				break
+			}
+		}
+	}
+
+	if p.accept("order") {
+		if !p.accept("by") {
+			return nil, p.unsupported()
+		}
+
+		for {
+			column, ok := p.next()
+			if !ok {
+				return nil, p.unsupported()
+			}
+
+			ordering := ordering{column: unquote(column)}
+			if p.accept("desc") {
+				ordering.descending = true
+			} else {
+				p.accept("asc")
+			}
+
+			f.orderings = append(f.orderings, ordering)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	var err error
+	if p.accept("limit") {
+		if f.limit, err = p.nextNumber(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.accept("offset") {
+		if f.offset, err = p.nextNumber(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.done() {
+		return nil, p.unsupported()
+	}
+
+	return f, nil
+}
+
+// clauseParser consumes the tokens of clauses.
+type clauseParser struct {
+	tokens []string
+	pos    int
+
+	// numbered and positional count the numbered and positional placeholders parsed so far
+	numbered   int
+	positional int
+}
+
+func (p *clauseParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *clauseParser) next() (string, bool) {
+	if p.done() {
+		return "", false
+	}
+
+	p.pos++
+	return p.tokens[p.pos-1], true
+}
+
+// accept consumes the next token if it matches the keyword received as argument, regardless of case.
+func (p *clauseParser) accept(keyword string) bool {
+	if !p.done() && strings.EqualFold(p.tokens[p.pos], keyword) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *clauseParser) nextNumber() (int, error) {
+	token, _ := p.next()
+	number, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, p.unsupported()
+	}
+
+	return number, nil
+}
+
+func (p *clauseParser) parsePredicate() (predicate, error) {
+	column, ok := p.next()
+	if !ok {
+		return predicate{}, p.unsupported()
+	}
+
+	if p.accept("is") {
+		operator := "is null"
+		if p.accept("not") {
+			operator = "is not null"
+		}
+
+		if !p.accept("null") {
+			return predicate{}, p.unsupported()
+		}
+
+		return predicate{column: unquote(column), operator: operator}, nil
+	}
+
+	operator, _ := p.next()
+	switch operator {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+	default:
+		return predicate{}, p.unsupported()
+	}
+
+	argIndex, err := p.nextPlaceholder()
+	if err != nil {
+		return predicate{}, err
+	}
+
+	return predicate{column: unquote(column), operator: operator, argIndex: argIndex}, nil
+}
+
+// nextPlaceholder consumes a placeholder and returns the index of its argument, starting at 1.
+func (p *clauseParser) nextPlaceholder() (int, error) {
+	placeholder, _ := p.next()
+	argIndex, err := strconv.Atoi(strings.TrimPrefix(placeholder, "$"))
+	switch {
+	case placeholder == "?":
+		p.positional++
+		argIndex = p.positional
+	case strings.HasPrefix(placeholder, "$") && err == nil:
+		p.numbered++
+	default:
+		return 0, errors.New(fmt.Sprintf("unsupported placeholder %q in clauses for FakeDB - %s", placeholder,
+			strings.Join(p.tokens, " ")))
+	}
+
+	if p.positional > 0 && p.numbered > 0 {
+		return 0, errors.New(fmt.Sprintf("mixed ? and $n placeholders in clauses for FakeDB - %s",
+			strings.Join(p.tokens, " ")))
+	}
+
+	return argIndex, nil
+}
+
+func (p *clauseParser) unsupported() error {
+	return errors.New(fmt.Sprintf("unsupported clauses for FakeDB - %s", strings.Join(p.tokens, " ")))
+}
+
+func unquote(column string) string {
+	return strings.Trim(column, `"`)
+}
+
+// apply returns the objects that match the predicates, ordered, limited and offset as specified by the filter.
+func (f *filter) apply(t reflect.Type, objects []reflect.Value, args []any,
+	naming liteorm.NamingStrategy) ([]reflect.Value, error) {
+	columnValue := func(object reflect.Value, column string) (reflect.Value, error) {
+		field, ok := columns.FindField(t, column, naming)
+		if !ok {
+			return reflect.Value{}, errors.New(fmt.Sprintf("type %s does not have a field for column %s", t.Name(),
+				column))
+		}
+
+		return object.FieldByIndex(field.Index), nil
+	}
+
+	result := make([]reflect.Value, 0, len(objects))
+	for _, object := range objects {
+		matches := true
+		for _, predicate := range f.predicates {
+			value, err := columnValue(object, predicate.column)
+			if err != nil {
+				return nil, err
+			}
+
+			if predicate.argIndex > len(args) {
+				return nil, errors.New(fmt.Sprintf("missing argument $%d", predicate.argIndex))
+			}
+
+			var arg any
+			if predicate.argIndex > 0 {
+				arg = args[predicate.argIndex-1]
+			}
+
+			ok, err := predicate.matches(value, arg)
+			if err != nil {
+				return nil, err
+			}
+			matches = matches && ok
+		}
+
+		if matches {
+			result = append(result, object)
+		}
+	}
+
+	var sortErr error
+	sort.SliceStable(result, func(i, j int) bool {
+		for _, ordering := range f.orderings {
+			left, err := columnValue(result[i], ordering.column)
+			if err != nil {
+				sortErr = err
+				return false
+			}
+			right, _ := columnValue(result[j], ordering.column)
+
+			c, err := compareValues(left.Interface(), right.Interface())
+			if err != nil {
+				sortErr = err
+				return false
+			}
+
+			if c != 0 {
+				return (c < 0) != ordering.descending
+			}
+		}
+		return false
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	if f.offset >= len(result) {
+		return nil, nil
+	}
+	result = result[f.offset:]
+
+	if f.limit >= 0 && f.limit < len(result) {
+		result = result[:f.limit]
+	}
+
+	return result, nil
+}
+
+// matches reports whether the value of a column satisfies the predicate for the argument received as argument.
+func (p predicate) matches(value reflect.Value, arg any) (bool, error) {
+	isNull := (value.Kind() == reflect.Ptr || value.Kind() == reflect.Slice || value.Kind() == reflect.Map) &&
+		value.IsNil()
+	switch p.operator {
+	case "is null":
+		return isNull, nil
+	case "is not null":
+		return !isNull, nil
+	}
+
+	// comparisons with null are never true, like in SQL
+	if isNull || arg == nil {
+		return false, nil
+	}
+
+	c, err := compareValues(value.Interface(), arg)
+	if err != nil {
+		return false, err
+	}
+
+	switch p.operator {
+	case "=":
+		return c == 0, nil
+	case "!=", "<>":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// compareValues compares two values, dereferencing pointers and converting numbers, and returns -1, 0 or 1.
+func compareValues(left any, right any) (int, error) {
+	leftv, rightv := reflect.ValueOf(left), reflect.ValueOf(right)
+	for leftv.Kind() == reflect.Ptr && !leftv.IsNil() {
+		leftv = leftv.Elem()
+	}
+	for rightv.Kind() == reflect.Ptr && !rightv.IsNil() {
+		rightv = rightv.Elem()
+	}
+
+	if leftNumber, ok := toFloat(leftv); ok {
+		if rightNumber, ok := toFloat(rightv); ok {
+			return compareOrdered(leftNumber, rightNumber), nil
+		}
+	}
+
+	if leftv.Kind() == reflect.String && rightv.Kind() == reflect.String {
+		return compareOrdered(leftv.String(), rightv.String()), nil
+	}
+
+	if leftTime, ok := leftv.Interface().(time.Time); ok {
+		if rightTime, ok := rightv.Interface().(time.Time); ok {
+			switch {
+			case leftTime.Before(rightTime):
+				return -1, nil
+			case leftTime.After(rightTime):
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+
+	if leftv.Kind() == reflect.Bool && rightv.Kind() == reflect.Bool {
+		return compareOrdered(strconv.FormatBool(leftv.Bool()), strconv.FormatBool(rightv.Bool())), nil
+	}
+
+	if leftv.IsValid() && rightv.IsValid() && leftv.Type() == rightv.Type() {
+		if reflect.DeepEqual(leftv.Interface(), rightv.Interface()) {
+			return 0, nil
+		}
+		return 1, nil
+	}
+
+	return 0, errors.New(fmt.Sprintf("cannot compare %v with %v", left, right))
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
+
+func compareOrdered[T float64 | string](left T, right T) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,309 @@
+// Package liteormtest provides an in-memory fake of liteorm.Database for unit tests of code that depends on liteorm.DB.
+package liteormtest
+
+import (
+	"context"
+	"fmt"
+	"github.com/lashbits/liteorm"
+	"github.com/pkg/errors"
+	"reflect"
+	"sync"
+)
+
+// FakeDB is an in-memory implementation of liteorm.DB, whose zero value is an empty fake database. Objects are stored
+// in maps by table and ID, and integer IDs are assigned on insert like serial columns. The clauses of selects, counts
+// and deletes support simple predicates, i.e. comparisons of columns with arguments and null checks combined with
+// "and", along with "order by", "limit" and "offset". Other clauses, hooks, soft deletes and versions are not emulated.
+type FakeDB struct {
+	// Naming maps types and fields to tables and columns, liteorm.DefaultNaming if not set
+	Naming liteorm.NamingStrategy
+
+	mutex  sync.Mutex
+	tables map[string]*fakeTable
+}
+
+// fakeTable holds the objects of a table, by ID and in insertion order.
+type fakeTable struct {
+	objects map[any]reflect.Value
+	ids     []any
+	lastID  int64
+}
+
+var _ liteorm.DB = (*FakeDB)(nil)
+
+// NewFakeDB returns an empty fake database.
+func NewFakeDB() *FakeDB {
+	return &FakeDB{tables: make(map[string]*fakeTable)}
+}
+
+func (db *FakeDB) naming() liteorm.NamingStrategy {
+	if db.Naming == nil {
+		return liteorm.DefaultNaming{}
+	}
+
+	return db.Naming
+}
+
+// table returns the table of the type, which is created along with the map of the tables if it does not exist yet. The
+// mutex must be held.
+func (db *FakeDB) table(t reflect.Type) *fakeTable {
+	if db.tables == nil {
+		db.tables = make(map[string]*fakeTable)
+	}
+
+	tableName := db.naming().TableName(t)
+	table, ok := db.tables[tableName]
+	if !ok {
+		table = &fakeTable{objects: make(map[any]reflect.Value)}
+		db.tables[tableName] = table
+	}
+
+	return table
+}
+
+// rows returns copies of the objects of the type matching the clauses.
+func (db *FakeDB) rows(t reflect.Type, clauses string, args []any) ([]reflect.Value, error) {
+	filter, err := parseClauses(clauses)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	table := db.table(t)
+	objects := make([]reflect.Value, 0, len(table.ids))
+	for _, id := range table.ids {
+		objects = append(objects, copyObject(table.objects[id]))
+	}
+
+	return filter.apply(t, objects, args, db.naming())
+}
+
+func (db *FakeDB) Insert(arg any) error {
+	return db.InsertCtx(context.Background(), arg)
+}
+
+func (db *FakeDB) InsertCtx(ctx context.Context, arg any) error {
+	argv, err := objectValue(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not insert object")
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	table := db.table(argv.Type())
+	idField := argv.FieldByName("ID")
+	if idField.IsZero() {
+		if !idField.CanInt() {
+			return errors.New(fmt.Sprintf("could not insert object of type %s - ID is not set",
+				argv.Type().Name()))
+		}
+
+		table.lastID++
+		idField.SetInt(table.lastID)
+	}
+
+	id := idField.Interface()
+	if _, exists := table.objects[id]; exists {
+		return errors.New(fmt.Sprintf("could not insert object of type %s - duplicate ID %v", argv.Type().Name(), id))
+	}
+
+	table.objects[id] = copyObject(argv)
+	table.ids = append(table.ids, id)
+	return nil
+}
+
+func (db *FakeDB) Save(arg any) error {
+	return db.SaveCtx(context.Background(), arg)
+}
+
+func (db *FakeDB) SaveCtx(ctx context.Context, arg any) error {
+	argv, err := objectValue(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not save object")
+	}
+
+	if argv.FieldByName("ID").IsZero() {
+		return db.InsertCtx(ctx, arg)
+	}
+
+	return db.UpdateOneCtx(ctx, arg)
+}
+
+func (db *FakeDB) SelectOne(arg any, clauses string, args ...any) error {
+	return db.SelectOneCtx(context.Background(), arg, clauses, args...)
+}
+
+func (db *FakeDB) SelectOneCtx(ctx context.Context, arg any, clauses string, args ...any) error {
+	argv, err := objectValue(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not select object")
+	}
+	errmsg := fmt.Sprintf("could not select object of type %s", argv.Type().Name())
+
+	rows, err := db.rows(argv.Type(), clauses, args)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	if len(rows) == 0 {
+		return errors.Wrap(liteorm.ErrNotFound, errmsg)
+	}
+
+	argv.Set(rows[0])
+	return nil
+}
+
+func (db *FakeDB) FindByID(arg any, id any) error {
+	return db.FindByIDCtx(context.Background(), arg, id)
+}
+
+func (db *FakeDB) FindByIDCtx(ctx context.Context, arg any, id any) error {
+	return db.SelectOneCtx(ctx, arg, "where id = $1", id)
+}
+
+func (db *FakeDB) Select(t reflect.Type, clauses string, args ...any) (any, error) {
+	return db.SelectCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *FakeDB) SelectCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (any, error) {
+	rows, err := db.rows(t, clauses, args)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not select objects of type %s", t.Name()))
+	}
+
+	result := reflect.MakeSlice(reflect.SliceOf(t), 0, len(rows))
+	for _, row := range rows {
+		result = reflect.Append(result, row)
+	}
+
+	return result.Interface(), nil
+}
+
+func (db *FakeDB) Count(t reflect.Type, clauses string, args ...any) (int64, error) {
+	return db.CountCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *FakeDB) CountCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error) {
+	rows, err := db.rows(t, clauses, args)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("could not count objects of type %s", t.Name()))
+	}
+
+	return int64(len(rows)), nil
+}
+
+func (db *FakeDB) Exists(t reflect.Type, clauses string, args ...any) (bool, error) {
+	return db.ExistsCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *FakeDB) ExistsCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (bool, error) {
+	count, err := db.CountCtx(ctx, t, clauses, args...)
+	return count > 0, err
+}
+
+func (db *FakeDB) UpdateOne(arg any) error {
+	return db.UpdateOneCtx(context.Background(), arg)
+}
+
+func (db *FakeDB) UpdateOneCtx(ctx context.Context, arg any) error {
+	argv, err := objectValue(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not update object")
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	table := db.table(argv.Type())
+	id := argv.FieldByName("ID").Interface()
+	if _, exists := table.objects[id]; !exists {
+		return errors.New("incorrect number of rows affected after updating the object")
+	}
+
+	table.objects[id] = copyObject(argv)
+	return nil
+}
+
+func (db *FakeDB) Delete(t reflect.Type, clauses string, args ...any) (int64, error) {
+	return db.DeleteCtx(context.Background(), t, clauses, args...)
+}
+
+func (db *FakeDB) DeleteCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error) {
+	rows, err := db.rows(t, clauses, args)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("could not delete objects of type %s", t.Name()))
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	table := db.table(t)
+	for _, row := range rows {
+		table.remove(row.FieldByName("ID").Interface())
+	}
+
+	return int64(len(rows)), nil
+}
+
+func (db *FakeDB) DeleteOne(arg any) error {
+	return db.DeleteOneCtx(context.Background(), arg)
+}
+
+func (db *FakeDB) DeleteOneCtx(ctx context.Context, arg any) error {
+	argv, err := objectValue(arg)
+	if err != nil {
+		return errors.Wrap(err, "could not delete object")
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if !db.table(argv.Type()).remove(argv.FieldByName("ID").Interface()) {
+		return errors.Wrap(liteorm.ErrNotFound, fmt.Sprintf("could not delete object of type %s",
+			argv.Type().Name()))
+	}
+
+	return nil
+}
+
+// remove removes the object with the ID received as argument, and reports whether it existed.
+func (table *fakeTable) remove(id any) bool {
+	if _, exists := table.objects[id]; !exists {
+		return false
+	}
+
+	delete(table.objects, id)
+	for i, tableID := range table.ids {
+		if tableID == id {
+			table.ids = append(table.ids[:i], table.ids[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+// objectValue returns the struct pointed to by arg, which must have an ID field.
+func objectValue(arg any) (reflect.Value, error) {
+	argv := reflect.ValueOf(arg)
+	if argv.Kind() != reflect.Ptr || argv.IsNil() || argv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("provided argument is not a pointer to a struct")
+	}
+
+	argv = argv.Elem()
+	if !argv.FieldByName("ID").IsValid() {
+		return reflect.Value{}, errors.New(fmt.Sprintf("type %s does not have an ID field", argv.Type().Name()))
+	}
+
+	return argv, nil
+}
+
+// copyObject returns a copy of the struct received as argument, so that the stored objects are not shared with callers.
+func copyObject(v reflect.Value) reflect.Value {
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	return copied
+}
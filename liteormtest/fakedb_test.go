@@ -0,0 +1,122 @@
+package liteormtest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/lashbits/liteorm"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type TestItem struct {
+	ID           int64
+	StringColumn string
+	IntColumn    int
+	Parent       *int64
+}
+
+var TestItemType reflect.Type = reflect.TypeOf((*TestItem)(nil)).Elem()
+
+func TestFakeDB(t *testing.T) {
+	var db liteorm.DB = NewFakeDB()
+
+	for i := 1; i <= 3; i++ {
+		err := db.Insert(&TestItem{StringColumn: "fake", IntColumn: i})
+		if err != nil {
+			t.Fatalf("could not insert object - %s", err.Error())
+		}
+	}
+
+	resultif, err := db.Select(TestItemType, `where string_column = $1 and "int_column" >= $2 order by int_column desc`,
+		"fake", 2)
+	if err != nil {
+		t.Fatalf("could not select objects - %s", err.Error())
+	}
+
+	result := resultif.([]TestItem)
+	if len(result) != 2 || result[0].IntColumn != 3 || result[1].IntColumn != 2 {
+		t.Errorf("incorrect objects selected - %+v", result)
+	}
+
+	object := TestItem{}
+	err = db.FindByID(&object, int64(2))
+	if err != nil || object.IntColumn != 2 {
+		t.Errorf("could not find object by id")
+	}
+
+	object.StringColumn = "updated"
+	err = db.UpdateOne(&object)
+	if err != nil {
+		t.Errorf("could not update object - %s", err.Error())
+	}
+
+	count, err := db.Count(TestItemType, "where string_column = $1 and parent is null", "updated")
+	if err != nil || count != 1 {
+		t.Errorf("incorrect count of updated objects")
+	}
+
+	err = db.SelectOne(&object, "where int_column > $1", 5)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("object selected without matching row")
+	}
+
+	err = db.DeleteOne(&object)
+	if err != nil {
+		t.Errorf("could not delete object - %s", err.Error())
+	}
+
+	err = db.DeleteOne(&object)
+	if !errors.Is(err, liteorm.ErrNotFound) {
+		t.Errorf("object was deleted twice")
+	}
+
+	rows, err := db.Delete(TestItemType, "limit 1")
+	if err != nil || rows != 1 {
+		t.Errorf("could not delete objects")
+	}
+
+	_, err = db.Select(TestItemType, "where int_column in (1, 2)")
+	if err == nil {
+		t.Errorf("unsupported clauses were accepted")
+	}
+
+	_, err = db.Select(TestItemType, "where int_column = :value", 1)
+	if err == nil || !strings.Contains(err.Error(), "unsupported placeholder") {
+		t.Errorf("unsupported placeholder was accepted")
+	}
+
+	_, err = db.Select(TestItemType, "where int_column = ? and string_column = $2", 1, "value")
+	if err == nil {
+		t.Errorf("mixed placeholders were accepted")
+	}
+}
+
+func TestFakeDBPositionalPlaceholders(t *testing.T) {
+	db := &FakeDB{}
+	for i := 1; i <= 3; i++ {
+		err := db.Insert(&TestItem{StringColumn: fmt.Sprintf("item %d", i), IntColumn: i})
+		if err != nil {
+			t.Fatalf("could not insert object - %s", err.Error())
+		}
+	}
+
+	count, err := db.Count(TestItemType, "where int_column >= ? and string_column <> ?", 2, "item 3")
+	if err != nil || count != 1 {
+		t.Errorf("incorrect count of objects - %d, %v", count, err)
+	}
+}
+
+func TestFakeDBZeroValue(t *testing.T) {
+	db := &FakeDB{Naming: liteorm.DefaultNaming{}}
+	err := db.Insert(&TestItem{StringColumn: "zero value"})
+	if err != nil {
+		t.Fatalf("could not insert object - %s", err.Error())
+	}
+
+	count, err := db.Count(TestItemType, "")
+	if err != nil || count != 1 {
+		t.Errorf("incorrect count of objects - %d", count)
+	}
+}
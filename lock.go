@@ -0,0 +1,149 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// LockMode is a locking clause of a select statement, which locks the selected rows until the end of the transaction,
+// see SelectLocked and Query.Lock.
+type LockMode string
+
+const (
+	// ForUpdate locks the rows against updates, deletes and other locks, waiting for the rows locked by other
+	// transactions
+	ForUpdate LockMode = "for update"
+
+	// ForNoKeyUpdate locks the rows like ForUpdate, but still lets other transactions lock them with ForKeyShare, e.g.
+	// to insert rows referencing them
+	ForNoKeyUpdate LockMode = "for no key update"
+
+	// ForShare locks the rows against updates and deletes, but lets other transactions lock them with ForShare
+	ForShare LockMode = "for share"
+
+	// ForKeyShare locks the rows against deletes and updates of their keys
+	ForKeyShare LockMode = "for key share"
+
+	// ForUpdateNoWait locks the rows like ForUpdate, but fails instead of waiting for the rows locked by other
+	// transactions
+	ForUpdateNoWait LockMode = "for update nowait"
+
+	// ForUpdateSkipLocked locks the rows like ForUpdate, but skips the rows locked by other transactions, e.g. so that
+	// workers claim distinct rows of a job queue
+	ForUpdateSkipLocked LockMode = "for update skip locked"
+
+	// ForShareSkipLocked locks the rows like ForShare, but skips the rows locked by other transactions with ForUpdate
+	ForShareSkipLocked LockMode = "for share skip locked"
+)
+
+// AdvisoryLock acquires the session level advisory lock of the key received as argument, waiting until it is available.
+// The lock is held until it is released with AdvisoryUnlock or the connection is closed, and coordinates work such as
+// singleton jobs across instances.
+func (db *Database) AdvisoryLock(key int64) error {
+	return db.AdvisoryLockCtx(context.Background(), key)
+}
+
+func (db *Database) AdvisoryLockCtx(ctx context.Context, key int64) error {
+	return advisoryLock(ctx, db.session(), "pg_advisory_lock", key)
+}
+
+// TryAdvisoryLock acquires the session level advisory lock of the key received as argument if it is available, and
+// reports whether it was acquired.
+func (db *Database) TryAdvisoryLock(key int64) (bool, error) {
+	return db.TryAdvisoryLockCtx(context.Background(), key)
+}
+
+func (db *Database) TryAdvisoryLockCtx(ctx context.Context, key int64) (bool, error) {
+	return tryAdvisoryLock(ctx, db.session(), "pg_try_advisory_lock", key)
+}
+
+// AdvisoryUnlock releases the session level advisory lock of the key received as argument. Releasing a lock that is not
+// held is an error.
+func (db *Database) AdvisoryUnlock(key int64) error {
+	return db.AdvisoryUnlockCtx(context.Background(), key)
+}
+
+func (db *Database) AdvisoryUnlockCtx(ctx context.Context, key int64) error {
+	released, err := tryAdvisoryLock(ctx, db.session(), "pg_advisory_unlock", key)
+	if err != nil {
+		return err
+	}
+
+	if !released {
+		return errors.New(fmt.Sprintf("could not release advisory lock %d - lock is not held", key))
+	}
+
+	return nil
+}
+
+// AdvisoryLock acquires the transaction level advisory lock of the key received as argument, waiting until it is
+// available. The lock is released when the transaction ends.
+func (tx *Tx) AdvisoryLock(key int64) error {
+	return tx.AdvisoryLockCtx(context.Background(), key)
+}
+
+func (tx *Tx) AdvisoryLockCtx(ctx context.Context, key int64) error {
+	return advisoryLock(ctx, tx.session(), "pg_advisory_xact_lock", key)
+}
+
+// TryAdvisoryLock acquires the transaction level advisory lock of the key received as argument if it is available, and
+// reports whether it was acquired.
+func (tx *Tx) TryAdvisoryLock(key int64) (bool, error) {
+	return tx.TryAdvisoryLockCtx(context.Background(), key)
+}
+
+func (tx *Tx) TryAdvisoryLockCtx(ctx context.Context, key int64) (bool, error) {
+	return tryAdvisoryLock(ctx, tx.session(), "pg_try_advisory_xact_lock", key)
+}
+
+func advisoryLock(ctx context.Context, s *session, function string, key int64) error {
+	_, err := s.Exec(ctx, fmt.Sprintf("select %s($1);", function), key)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not acquire advisory lock %d", key))
+	}
+
+	return nil
+}
+
+// tryAdvisoryLock calls an advisory lock function that reports whether it succeeded.
+func tryAdvisoryLock(ctx context.Context, s *session, function string, key int64) (bool, error) {
+	var ok bool
+	err := s.QueryRow(ctx, fmt.Sprintf("select %s($1);", function), key).Scan(&ok)
+	if err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("could not call %s for advisory lock %d", function, key))
+	}
+
+	return ok, nil
+}
+
+// SelectLocked selects the rows of the type matching the clauses like Select, and locks them with the locking clause
+// received as argument until the transaction ends. The clauses may order and limit the rows, e.g. to claim the next
+// jobs of a queue with ForUpdateSkipLocked:
+//
+//	jobs, err := tx.SelectLocked(t, liteorm.ForUpdateSkipLocked, "where status = $1 order by id limit 10", "pending")
+func (tx *Tx) SelectLocked(t reflect.Type, mode LockMode, clauses string, args ...any) (any, error) {
+	return tx.SelectLockedCtx(context.Background(), t, mode, clauses, args...)
+}
+
+func (tx *Tx) SelectLockedCtx(ctx context.Context, t reflect.Type, mode LockMode, clauses string,
+	args ...any) (any, error) {
+	return selectAll(ctx, tx.session(), t, buildLockClauses(clauses, mode), args...)
+}
+
+// SelectForUpdate selects the rows of the type matching the clauses like Select, and locks them against updates by other
+// transactions until the transaction ends, see SelectLocked.
+func (tx *Tx) SelectForUpdate(t reflect.Type, clauses string, args ...any) (any, error) {
+	return tx.SelectForUpdateCtx(context.Background(), t, clauses, args...)
+}
+
+func (tx *Tx) SelectForUpdateCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (any, error) {
+	return tx.SelectLockedCtx(ctx, t, ForUpdate, clauses, args...)
+}
+
+// buildLockClauses appends the locking clause received as argument to the clauses, which follows their limit and
+// offset.
+func buildLockClauses(clauses string, mode LockMode) string {
+	return fmt.Sprintf("%s %s", clauses, mode)
+}
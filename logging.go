@@ -0,0 +1,148 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"strings"
+	"time"
+)
+
+// Logger receives every statement run by liteorm, together with its arguments, the time it took and the error it
+// resulted in, if any. For queries, the duration covers the time until the first response of the database.
+type Logger interface {
+	LogStatement(ctx context.Context, sql string, args []any, duration time.Duration, err error)
+}
+
+// LoggerFunc adapts a function to the Logger interface.
+type LoggerFunc func(ctx context.Context, sql string, args []any, duration time.Duration, err error)
+
+func (f LoggerFunc) LogStatement(ctx context.Context, sql string, args []any, duration time.Duration, err error) {
+	f(ctx, sql, args, duration, err)
+}
+
+// WithLogger returns a copy of the database that passes every statement it runs to the logger received as argument.
+// Transactions started from the copy use the same logger.
+func (db *Database) WithLogger(logger Logger) *Database {
+	logged := *db
+	logged.logger = logger
+	return &logged
+}
+
+// loggingExecutor wraps the executor of a session to pass the statements it runs to a logger.
+type loggingExecutor struct {
+	executor
+	logger Logger
+}
+
+func (e *loggingExecutor) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	start := time.Now()
+	commandTag, err := e.executor.Exec(ctx, sql, args...)
+	e.logger.LogStatement(ctx, sql, args, time.Since(start), err)
+	return commandTag, err
+}
+
+func (e *loggingExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := e.executor.Query(ctx, sql, args...)
+	e.logger.LogStatement(ctx, sql, args, time.Since(start), err)
+	return rows, err
+}
+
+func (e *loggingExecutor) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &loggingRow{
+		Row:    e.executor.QueryRow(ctx, sql, args...),
+		ctx:    ctx,
+		sql:    sql,
+		args:   args,
+		start:  time.Now(),
+		logger: e.logger,
+	}
+}
+
+func (e *loggingExecutor) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &loggingBatchResults{
+		BatchResults: e.executor.SendBatch(ctx, b),
+		ctx:          ctx,
+		sql:          fmt.Sprintf("batch of %d statements", b.Len()),
+		start:        time.Now(),
+		logger:       e.logger,
+	}
+}
+
+func (e *loggingExecutor) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string,
+	rowSrc pgx.CopyFromSource) (int64, error) {
+	start := time.Now()
+	count, err := e.executor.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	sql := fmt.Sprintf("copy %s (%s) from stdin", tableName.Sanitize(), strings.Join(columnNames, ","))
+	e.logger.LogStatement(ctx, sql, nil, time.Since(start), err)
+	return count, err
+}
+
+// loggingRow logs the statement of a row once the row is scanned, since QueryRow defers errors to Scan.
+type loggingRow struct {
+	pgx.Row
+	ctx    context.Context
+	sql    string
+	args   []any
+	start  time.Time
+	logger Logger
+}
+
+func (r *loggingRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	r.logger.LogStatement(r.ctx, r.sql, r.args, time.Since(r.start), err)
+	return err
+}
+
+// loggingBatchResults logs a batch once its results are closed, with the first error that occurred.
+type loggingBatchResults struct {
+	pgx.BatchResults
+	ctx    context.Context
+	sql    string
+	start  time.Time
+	logger Logger
+	err    error
+}
+
+func (b *loggingBatchResults) Exec() (pgconn.CommandTag, error) {
+	commandTag, err := b.BatchResults.Exec()
+	b.recordError(err)
+	return commandTag, err
+}
+
+func (b *loggingBatchResults) Query() (pgx.Rows, error) {
+	rows, err := b.BatchResults.Query()
+	b.recordError(err)
+	return rows, err
+}
+
+func (b *loggingBatchResults) QueryRow() pgx.Row {
+	return &errorRecordingRow{Row: b.BatchResults.QueryRow(), batch: b}
+}
+
+func (b *loggingBatchResults) Close() error {
+	err := b.BatchResults.Close()
+	b.recordError(err)
+	b.logger.LogStatement(b.ctx, b.sql, nil, time.Since(b.start), b.err)
+	return err
+}
+
+func (b *loggingBatchResults) recordError(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// errorRecordingRow records the error of scanning a row of a batch in the batch results.
+type errorRecordingRow struct {
+	pgx.Row
+	batch *loggingBatchResults
+}
+
+func (r *errorRecordingRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	r.batch.recordError(err)
+	return err
+}
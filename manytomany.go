@@ -0,0 +1,353 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// joinTable is the join table of a many-to-many relation, declared with the "m2m" tag on a slice field holding the
+// related objects, e.g.
+//
+//	type User struct {
+//		ID    int64
+//		Roles []Role `m2m:"user_roles"`
+//	}
+//
+// The tag holds the name of the join table, optionally followed by the names of its columns holding the IDs of the
+// object and of the related object, e.g. `m2m:"user_roles,user_id,role_id"`. The columns default to the column names
+// of the ID fields named after the types, e.g. user_id and role_id. The join table is created along with the table of
+// the type by CreateTable, rows are added to and removed from it with Associate and Dissociate, and the related objects
+// are loaded by Preload.
+type joinTable struct {
+	name          string
+	t             reflect.Type
+	column        string
+	relatedType   reflect.Type
+	relatedColumn string
+}
+
+// getJoinTable returns the join table of the many-to-many relation field received as argument, of the type argt.
+func getJoinTable(argt reflect.Type, field reflect.StructField, naming NamingStrategy) (joinTable, error) {
+	if field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() != reflect.Struct {
+		return joinTable{}, errors.New("many-to-many relation field is not a slice of structs")
+	}
+
+	parts := strings.Split(field.Tag.Get("m2m"), ",")
+	if parts[0] == "" || (len(parts) != 1 && len(parts) != 3) {
+		return joinTable{}, errors.New(fmt.Sprintf("invalid m2m tag of field %s", field.Name))
+	}
+
+	relatedType := field.Type.Elem()
+	table := joinTable{
+		name:          parts[0],
+		t:             argt,
+		column:        naming.ColumnName(argt.Name() + "ID"),
+		relatedType:   relatedType,
+		relatedColumn: naming.ColumnName(relatedType.Name() + "ID"),
+	}
+
+	if len(parts) == 3 {
+		table.column, table.relatedColumn = strings.TrimSpace(parts[1]), strings.TrimSpace(parts[2])
+	}
+
+	if table.column == table.relatedColumn {
+		return joinTable{}, errors.New(fmt.Sprintf("join table %s needs distinct column names", table.name))
+	}
+
+	return table, nil
+}
+
+// joinTables returns the join tables of the many-to-many relations of the type received as argument.
+func joinTables(argt reflect.Type, naming NamingStrategy) ([]joinTable, error) {
+	tables := make([]joinTable, 0)
+	for i := 0; i < argt.NumField(); i++ {
+		field := argt.Field(i)
+		if kind, ok := getRelationKind(field); !ok || kind != manyToMany {
+			continue
+		}
+
+		table, err := getJoinTable(argt, field, naming)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// findJoinTable returns the join table of the relation between the types received as argument, which is declared on
+// either of them. The returned table is oriented from argt to relatedType.
+func findJoinTable(argt reflect.Type, relatedType reflect.Type, naming NamingStrategy) (joinTable, error) {
+	tables, err := joinTables(argt, naming)
+	if err != nil {
+		return joinTable{}, err
+	}
+
+	for _, table := range tables {
+		if table.relatedType == relatedType {
+			return table, nil
+		}
+	}
+
+	tables, err = joinTables(relatedType, naming)
+	if err != nil {
+		return joinTable{}, err
+	}
+
+	for _, table := range tables {
+		if table.relatedType == argt {
+			return joinTable{name: table.name, t: argt, column: table.relatedColumn, relatedType: relatedType,
+				relatedColumn: table.column}, nil
+		}
+	}
+
+	return joinTable{}, errors.New(fmt.Sprintf("types %s and %s do not have a many-to-many relation", argt.Name(),
+		relatedType.Name()))
+}
+
+// buildJoinTableName returns the quoted name of a join table, qualified with the schema of the settings unless the tag
+// already qualifies it.
+func buildJoinTableName(table joinTable, schema string) string {
+	if schema != "" && !strings.Contains(table.name, ".") {
+		return quoteIdentifier(schema + "." + table.name)
+	}
+
+	return quoteIdentifier(table.name)
+}
+
+// buildCreateJoinTableStatement builds the statement creating a join table, unless it already exists. The types of its
+// columns are those referencing the IDs of the types, see buildIDReferenceType. Each pair of objects is associated at
+// most once.
+func buildCreateJoinTableStatement(table joinTable, schema string) (string, error) {
+	columnType, err := buildJoinColumnType(table.t)
+	if err != nil {
+		return "", err
+	}
+
+	relatedColumnType, err := buildJoinColumnType(table.relatedType)
+	if err != nil {
+		return "", err
+	}
+
+	column, relatedColumn := quoteIdentifier(table.column), quoteIdentifier(table.relatedColumn)
+	return fmt.Sprintf("create table if not exists %s (%s %s not null, %s %s not null, primary key (%s,%s));",
+		buildJoinTableName(table, schema), column, columnType, relatedColumn, relatedColumnType, column,
+		relatedColumn), nil
+}
+
+// buildJoinColumnType returns the type of the join table column holding the IDs of the type received as argument.
+func buildJoinColumnType(t reflect.Type) (string, error) {
+	field, ok := t.FieldByName("ID")
+	if !ok {
+		return "", errors.New(fmt.Sprintf("type %s of a many-to-many relation does not have an ID field", t.Name()))
+	}
+
+	return buildIDReferenceType(field)
+}
+
+// createJoinTables creates the join tables of the many-to-many relations declared on the type received as argument,
+// after dropping them if dropExisting is set.
+func (db *Database) createJoinTables(ctx context.Context, t reflect.Type, dropExisting bool) error {
+	tables, err := joinTables(t, db.namingStrategy())
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if dropExisting {
+			_, err = db.session().Exec(ctx, fmt.Sprintf("drop table if exists %s;",
+				buildJoinTableName(table, db.schema)))
+			if err != nil {
+				return err
+			}
+		}
+
+		statement, err := buildCreateJoinTableStatement(table, db.schema)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.session().Exec(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildAssociateStatement builds the statement adding the row of a pair of objects to a join table, unless it exists.
+func buildAssociateStatement(table joinTable, schema string) string {
+	return fmt.Sprintf("insert into %s (%s,%s) values ($1,$2) on conflict do nothing;",
+		buildJoinTableName(table, schema), quoteIdentifier(table.column), quoteIdentifier(table.relatedColumn))
+}
+
+// buildDissociateStatement builds the statement removing the row of a pair of objects from a join table.
+func buildDissociateStatement(table joinTable, schema string) string {
+	return fmt.Sprintf("delete from %s where %s = $1 and %s = $2;", buildJoinTableName(table, schema),
+		quoteIdentifier(table.column), quoteIdentifier(table.relatedColumn))
+}
+
+// Associate relates the objects received as argument through the join table of the many-to-many relation between
+// their types, which can be declared on either of them. Associating objects that are already related has no effect.
+func (db *Database) Associate(a any, b any) error {
+	return db.AssociateCtx(context.Background(), a, b)
+}
+
+func (db *Database) AssociateCtx(ctx context.Context, a any, b any) error {
+	return associate(ctx, db.session(), a, b, true)
+}
+
+// Dissociate removes the relation between the objects received as argument from the join table of the many-to-many
+// relation between their types. Dissociating objects that are not related has no effect.
+func (db *Database) Dissociate(a any, b any) error {
+	return db.DissociateCtx(context.Background(), a, b)
+}
+
+func (db *Database) DissociateCtx(ctx context.Context, a any, b any) error {
+	return associate(ctx, db.session(), a, b, false)
+}
+
+func (tx *Tx) Associate(a any, b any) error {
+	return tx.AssociateCtx(context.Background(), a, b)
+}
+
+func (tx *Tx) AssociateCtx(ctx context.Context, a any, b any) error {
+	return associate(ctx, tx.session(), a, b, true)
+}
+
+func (tx *Tx) Dissociate(a any, b any) error {
+	return tx.DissociateCtx(context.Background(), a, b)
+}
+
+func (tx *Tx) DissociateCtx(ctx context.Context, a any, b any) error {
+	return associate(ctx, tx.session(), a, b, false)
+}
+
+// associate adds the row of the objects received as argument to the join table of their relation, or removes it if
+// associated is not set.
+func associate(ctx context.Context, s *session, a any, b any, associated bool) error {
+	errmsg := "could not associate objects"
+	if !associated {
+		errmsg = "could not dissociate objects"
+	}
+
+	argt, err := getObjectType(a)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	relatedType, err := getObjectType(b)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	table, err := findJoinTable(argt, relatedType, s.namingStrategy())
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	id, err := getIntegerIDValue(a)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	relatedID, err := getIntegerIDValue(b)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	statement := buildAssociateStatement(table, s.schema)
+	if !associated {
+		statement = buildDissociateStatement(table, s.schema)
+	}
+
+	_, err = s.Exec(ctx, statement, id, relatedID)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	return nil
+}
+
+// preloadManyToMany sets the slice field of each object to the related objects associated with it in the join table of
+// the relation, in the order of their IDs.
+func preloadManyToMany(ctx context.Context, s *session, objects []any, argt reflect.Type,
+	field reflect.StructField) error {
+	table, err := getJoinTable(argt, field, s.namingStrategy())
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, len(objects))
+	for i, object := range objects {
+		ids[i], err = getIntegerIDValue(object)
+		if err != nil {
+			return err
+		}
+	}
+
+	statement := fmt.Sprintf("select %s, %s from %s where %s = any($1);", quoteIdentifier(table.column),
+		quoteIdentifier(table.relatedColumn), buildJoinTableName(table, s.schema), quoteIdentifier(table.column))
+	rows, err := s.Query(ctx, statement, ids)
+	if err != nil {
+		return err
+	}
+
+	relatedIDsByID := make(map[int64][]int64)
+	relatedIDs := make([]int64, 0)
+	for rows.Next() {
+		var id, relatedID int64
+		err = rows.Scan(&id, &relatedID)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		relatedIDsByID[id] = append(relatedIDsByID[id], relatedID)
+		relatedIDs = append(relatedIDs, relatedID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	relatedType := table.relatedType
+	relatedif, err := selectAll(ctx, s, relatedType, buildIDsClause(relatedType, s.namingStrategy()), relatedIDs)
+	if err != nil {
+		return err
+	}
+
+	related := reflect.ValueOf(relatedif)
+	byID := make(map[int64]reflect.Value)
+	for i := 0; i < related.Len(); i++ {
+		id, err := getIntegerIDValue(related.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		byID[id] = related.Index(i)
+	}
+
+	for i, object := range objects {
+		relatedIDs := relatedIDsByID[ids[i]]
+		sort.Slice(relatedIDs, func(a, b int) bool { return relatedIDs[a] < relatedIDs[b] })
+		group := reflect.MakeSlice(field.Type, 0, len(relatedIDs))
+		for _, relatedID := range relatedIDs {
+			if elem, ok := byID[relatedID]; ok {
+				group = reflect.Append(group, elem)
+			}
+		}
+
+		argv, err := getObjectValue(object)
+		if err != nil {
+			return err
+		}
+		argv.FieldByIndex(field.Index).Set(group)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package liteorm
+
+import (
+	"time"
+)
+
+// Metrics observes the operations of liteorm, the same ones traced by Tracer, once they complete. It is the integration
+// point for metrics libraries such as Prometheus, which are adapted by counting operations and errors and observing
+// latencies by operation and table:
+//
+//	func (m promMetrics) ObserveOperation(operation, tableName string, duration time.Duration, err error) {
+//		m.latency.WithLabelValues(operation, tableName).Observe(duration.Seconds())
+//		if err != nil {
+//			m.errors.WithLabelValues(operation, tableName).Inc()
+//		}
+//	}
+type Metrics interface {
+	ObserveOperation(operation string, tableName string, duration time.Duration, err error)
+}
+
+// WithMetrics returns a copy of the database whose operations are observed by the metrics received as argument.
+// Transactions started from the copy use the same metrics.
+func (db *Database) WithMetrics(metrics Metrics) *Database {
+	measured := *db
+	measured.metrics = metrics
+	return &measured
+}
+
+// metricsSpan wraps the span of an operation to pass its duration and error to the metrics when it ends.
+type metricsSpan struct {
+	Span
+	metrics   Metrics
+	operation string
+	tableName string
+	start     time.Time
+}
+
+func (s *metricsSpan) End(err error) {
+	s.Span.End(err)
+	s.metrics.ObserveOperation(s.operation, s.tableName, time.Since(s.start), err)
+}
@@ -0,0 +1,164 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// Migration is a versioned change to the database schema. A migration is applied by either running the Up statement or
+// calling UpFunc, and reverted by either running the Down statement or calling DownFunc; the functions take precedence
+// over the statements when set. Each migration runs within its own transaction.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	UpFunc   func(tx *Tx) error
+	DownFunc func(tx *Tx) error
+}
+
+// migrationsTableName is the name of the table that tracks the applied migrations.
+var migrationsTableName = "schema_migrations"
+
+// Migrate applies the migrations that have not been applied yet, in ascending order of version.
+func (db *Database) Migrate(migrations []Migration) error {
+	return db.MigrateCtx(context.Background(), migrations)
+}
+
+func (db *Database) MigrateCtx(ctx context.Context, migrations []Migration) error {
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return errors.Wrap(err, "could not migrate")
+	}
+
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not migrate")
+	}
+
+	for _, migration := range sorted {
+		if applied[migration.Version] {
+			continue
+		}
+
+		statement := fmt.Sprintf("insert into %s (version, name) values ($1, $2);", migrationsTableName)
+		err = db.runMigration(ctx, migration.Up, migration.UpFunc, statement, migration.Version, migration.Name)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not apply migration %d", migration.Version))
+		}
+	}
+
+	return nil
+}
+
+// MigrateDown reverts the applied migrations with a version greater than targetVersion, in descending order of version.
+func (db *Database) MigrateDown(migrations []Migration, targetVersion int64) error {
+	return db.MigrateDownCtx(context.Background(), migrations, targetVersion)
+}
+
+func (db *Database) MigrateDownCtx(ctx context.Context, migrations []Migration, targetVersion int64) error {
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return errors.Wrap(err, "could not revert migrations")
+	}
+
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not revert migrations")
+	}
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		migration := sorted[i]
+		if migration.Version <= targetVersion || !applied[migration.Version] {
+			continue
+		}
+
+		statement := fmt.Sprintf("delete from %s where version = $1;", migrationsTableName)
+		err = db.runMigration(ctx, migration.Down, migration.DownFunc, statement, migration.Version)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not revert migration %d", migration.Version))
+		}
+	}
+
+	return nil
+}
+
+// sortMigrations returns a copy of the migrations received as argument sorted by version. Duplicate versions are
+// reported as an error.
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, errors.New(fmt.Sprintf("duplicate migration version %d", sorted[i].Version))
+		}
+	}
+
+	return sorted, nil
+}
+
+// appliedMigrations creates the migrations table if needed, and returns the set of versions that have been applied.
+func (db *Database) appliedMigrations(ctx context.Context) (map[int64]bool, error) {
+	statement := fmt.Sprintf(`
+        create table if not exists %s (
+            version bigint primary key,
+            name varchar(255),
+            applied_at timestamp default now()
+        );`, migrationsTableName)
+	_, err := db.session().Exec(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.session().Query(ctx, fmt.Sprintf("select version from %s;", migrationsTableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// runMigration runs either the statement or the function of a migration, followed by the statement that records it in
+// the migrations table, within a single transaction.
+func (db *Database) runMigration(ctx context.Context, statement string, fn func(tx *Tx) error, record string,
+	recordArgs ...any) error {
+	tx, err := db.BeginCtx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Tx.Rollback(ctx)
+
+	if fn != nil {
+		err = fn(tx)
+	} else {
+		_, err = tx.session().Exec(ctx, statement)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.session().Exec(ctx, record, recordArgs...)
+	if err != nil {
+		return err
+	}
+
+	return tx.CommitCtx(ctx)
+}
@@ -0,0 +1,156 @@
+package liteorm
+
+import (
+	"reflect"
+	"sync"
+)
+
+// columnFieldsCache and statementCache hold the metadata of the types and the statements built from it, which only
+// depend on the types and the settings of the operations, so that operations do not reflect on the types and build the
+// same statements each time. The column fields are cached per type, and the statements that do not depend on the
+// clauses or values of the operations per type and settings, see statementKey.
+var (
+	columnFieldsCache sync.Map
+	statementCache    sync.Map
+)
+
+// statementKey identifies a cached statement, by the kind of statement, e.g. "insert", the type and the settings the
+// statement is built with. The naming strategy is represented by its key, see namingKey.
+type statementKey struct {
+	kind     string
+	t        reflect.Type
+	unscoped bool
+	naming   any
+	dialect  Dialect
+}
+
+// cachedStatement returns the statement of the kind received as argument for the type and settings, built with build
+// the first time. Statements whose naming strategy or dialect cannot be part of a key are built each time.
+func cachedStatement(kind string, t reflect.Type, unscoped bool, naming NamingStrategy, dialect Dialect,
+	build func() string) string {
+	key, ok := namingKey(naming)
+	if !ok || !isComparable(reflect.ValueOf(dialect)) {
+		return build()
+	}
+
+	statementKey := statementKey{kind: kind, t: t, unscoped: unscoped, naming: key, dialect: dialect}
+	if statement, ok := statementCache.Load(statementKey); ok {
+		return statement.(string)
+	}
+
+	statement, _ := statementCache.LoadOrStore(statementKey, build())
+	return statement.(string)
+}
+
+// clearStatementCache removes the cached statements, e.g. once a change of the global configuration makes them stale.
+func clearStatementCache() {
+	statementCache.Range(func(key any, _ any) bool {
+		statementCache.Delete(key)
+		return true
+	})
+}
+
+// defaultNamingKey, schemaNamingKey and prefixNamingKey are the keys of the naming strategies of liteorm.
+type (
+	defaultNamingKey struct {
+		tablePrefix  string
+		columnNaming uintptr
+	}
+
+	schemaNamingKey struct {
+		naming any
+		schema string
+	}
+
+	prefixNamingKey struct {
+		naming any
+		prefix string
+	}
+)
+
+// namingKey returns a comparable value that identifies the names derived by a naming strategy, which is false if there
+// is none. DefaultNaming is identified by its resolved table prefix and its column naming, unless the column naming is
+// a function other than SnakeCase and LowerCase, e.g. a closure. Other strategies are their own key if they are
+// comparable.
+func namingKey(naming NamingStrategy) (any, bool) {
+	switch n := naming.(type) {
+	case DefaultNaming:
+		key := defaultNamingKey{tablePrefix: n.TablePrefix}
+		if key.tablePrefix == "" {
+			key.tablePrefix = TablePrefix
+		}
+
+		if n.ColumnNaming != nil {
+			key.columnNaming = reflect.ValueOf(n.ColumnNaming).Pointer()
+			if key.columnNaming != snakeCasePointer && key.columnNaming != lowerCasePointer {
+				return nil, false
+			}
+		}
+
+		return key, true
+	case schemaNaming:
+		key, ok := namingKey(n.NamingStrategy)
+		return schemaNamingKey{naming: key, schema: n.schema}, ok
+	case prefixNaming:
+		key, ok := namingKey(n.NamingStrategy)
+		return prefixNamingKey{naming: key, prefix: n.prefix}, ok
+	}
+
+	return naming, isComparable(reflect.ValueOf(naming))
+}
+
+var (
+	snakeCasePointer = reflect.ValueOf(SnakeCase).Pointer()
+	lowerCasePointer = reflect.ValueOf(LowerCase).Pointer()
+)
+
+// isComparable reports whether a value can be part of a map key, i.e. whether neither it nor the values it holds are
+// slices, maps or functions.
+func isComparable(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Func:
+		return false
+	case reflect.Interface:
+		return v.IsNil() || isComparable(v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isComparable(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isComparable(v.Field(i)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// cachedInsertStatement returns the insert statement of the type received as argument, see buildInsertStatement.
+func (s settings) cachedInsertStatement(argt reflect.Type) string {
+	return cachedStatement("insert", argt, false, s.namingStrategy(), s.sqlDialect(), func() string {
+		return buildInsertStatement(argt, s.namingStrategy(), s.sqlDialect())
+	})
+}
+
+// cachedSelectStatement returns the statement selecting the columns of the type received as argument with the clauses,
+// see buildSelectStatement. The part of the statement preceding the clauses is cached, unless the statement is scoped
+// by a tenant, whose placeholder depends on the arguments of the clauses.
+func (s settings) cachedSelectStatement(argt reflect.Type, clauses string, tenantIdx int) string {
+	if tenantIdx > 0 {
+		return buildSelectStatement(argt, clauses, s.unscoped, tenantIdx, s.namingStrategy())
+	}
+
+	prefix := cachedStatement("select", argt, s.unscoped, s.namingStrategy(), nil, func() string {
+		return buildSelectFieldsPrefix(argt, columnFields(argt), s.unscoped, 0, s.namingStrategy())
+	})
+
+	return prefix + " " + clauses + ";"
+}
@@ -0,0 +1,189 @@
+package liteorm
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives the names of tables from types and the names of columns from field names. Explicit names set
+// with TableNamer or the column tag take precedence over the strategy.
+type NamingStrategy interface {
+	TableName(t reflect.Type) string
+	ColumnName(fieldName string) string
+}
+
+// TablePrefix is prepended to the table names generated by DefaultNaming, and thus by BuildTableName, unless the naming
+// sets its own prefix. It allows liteorm to share a database with other applications without name collisions.
+var TablePrefix string
+
+// DefaultNaming is the naming strategy used unless another one is set with Database.WithNaming. Table names are the
+// plural form of the lower case type name, e.g. Person maps to people and Status to statuses, preceded by the table
+// prefix. Column names are derived by the column naming.
+type DefaultNaming struct {
+	// TablePrefix is prepended to the generated table names, e.g. "app_", but not to the names set with TableNamer. The
+	// global TablePrefix is used if not set
+	TablePrefix string
+
+	// ColumnNaming derives column names from field names, SnakeCase if not set
+	ColumnNaming ColumnNaming
+}
+
+func (n DefaultNaming) TableName(t reflect.Type) string {
+	if tableName, ok := getTableNamerName(t); ok {
+		return tableName
+	}
+
+	prefix := n.TablePrefix
+	if prefix == "" {
+		prefix = TablePrefix
+	}
+
+	return prefix + pluralize(t.Name())
+}
+
+func (n DefaultNaming) ColumnName(fieldName string) string {
+	if n.ColumnNaming == nil {
+		return SnakeCase(fieldName)
+	}
+
+	return n.ColumnNaming(fieldName)
+}
+
+// irregularPlurals maps the singular form of nouns with irregular plurals to their plural form. Uncountable nouns map
+// to themselves.
+var irregularPlurals = map[string]string{
+	"person":      "people",
+	"man":         "men",
+	"woman":       "women",
+	"human":       "humans",
+	"child":       "children",
+	"mouse":       "mice",
+	"goose":       "geese",
+	"foot":        "feet",
+	"tooth":       "teeth",
+	"leaf":        "leaves",
+	"knife":       "knives",
+	"wife":        "wives",
+	"datum":       "data",
+	"medium":      "media",
+	"criterion":   "criteria",
+	"analysis":    "analyses",
+	"index":       "indices",
+	"matrix":      "matrices",
+	"vertex":      "vertices",
+	"sheep":       "sheep",
+	"fish":        "fish",
+	"series":      "series",
+	"species":     "species",
+	"news":        "news",
+	"equipment":   "equipment",
+	"information": "information",
+	"metadata":    "metadata",
+}
+
+// pluralize returns the lower case plural form of the type name received as argument. Only the last word of the name,
+// as separated by SnakeCase, is pluralized, and irregular plurals only match that word as a whole, so that AdminPerson
+// maps to adminpeople but Talisman to talismans.
+func pluralize(name string) string {
+	start := lastWordStart(name)
+	prefix, noun := strings.ToLower(name[:start]), strings.ToLower(name[start:])
+	if plural, ok := irregularPlurals[noun]; ok {
+		return prefix + plural
+	}
+
+	switch {
+	case strings.HasSuffix(noun, "s"), strings.HasSuffix(noun, "x"), strings.HasSuffix(noun, "z"),
+		strings.HasSuffix(noun, "ch"), strings.HasSuffix(noun, "sh"):
+		return prefix + noun + "es"
+
+	case strings.HasSuffix(noun, "y") && len(noun) > 1 && !strings.ContainsRune("aeiou", rune(noun[len(noun)-2])):
+		return prefix + strings.TrimSuffix(noun, "y") + "ies"
+	}
+
+	return prefix + noun + "s"
+}
+
+// lastWordStart returns the byte index of the start of the last word of the name received as argument, as separated by
+// SnakeCase.
+func lastWordStart(name string) int {
+	runes := []rune(name)
+	start := 0
+	for i := range runes {
+		if isWordStart(runes, i) {
+			start = i
+		}
+	}
+
+	return len(string(runes[:start]))
+}
+
+// isWordStart reports whether the rune at the index received as argument starts a new word of a name, i.e. it is an
+// upper case letter following a lower case letter or a digit, or the last letter of an acronym followed by a lower
+// case letter, e.g. the S of HTTPServer.
+func isWordStart(runes []rune, i int) bool {
+	if i == 0 || !unicode.IsUpper(runes[i]) {
+		return false
+	}
+
+	previous := runes[i-1]
+	nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+	return unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower)
+}
+
+// ColumnNaming derives the name of the column of a field from the field name. It applies to all fields without a
+// column tag, including the ID, DeletedAt and Version fields.
+type ColumnNaming func(fieldName string) string
+
+// SnakeCase is the default column naming, which separates the words of a field name with underscores, e.g. CreatedAt
+// maps to created_at and UserID to user_id.
+func SnakeCase(fieldName string) string {
+	runes := []rune(fieldName)
+	var result strings.Builder
+	for i, r := range runes {
+		if isWordStart(runes, i) {
+			result.WriteRune('_')
+		}
+
+		result.WriteRune(unicode.ToLower(r))
+	}
+
+	return result.String()
+}
+
+// LowerCase is the column naming of earlier versions, which lower cases the field name, e.g. CreatedAt maps to
+// createdat. It is meant for databases whose tables were created with those versions.
+func LowerCase(fieldName string) string {
+	return strings.ToLower(fieldName)
+}
+
+// schemaNaming qualifies the table names of a naming strategy with a schema. Names that are already qualified, e.g. by
+// TableNamer, are left as is.
+type schemaNaming struct {
+	NamingStrategy
+	schema string
+}
+
+func (n schemaNaming) TableName(t reflect.Type) string {
+	tableName := n.NamingStrategy.TableName(t)
+	if strings.Contains(tableName, ".") {
+		return tableName
+	}
+
+	return n.schema + "." + tableName
+}
+
+// prefixNaming prepends a prefix to the table names of a naming strategy other than DefaultNaming, except for the names
+// set with TableNamer.
+type prefixNaming struct {
+	NamingStrategy
+	prefix string
+}
+
+func (n prefixNaming) TableName(t reflect.Type) string {
+	if tableName, ok := getTableNamerName(t); ok {
+		return tableName
+	}
+
+	return n.prefix + n.NamingStrategy.TableName(t)
+}
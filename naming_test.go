@@ -0,0 +1,110 @@
+package liteorm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnakeCase(t *testing.T) {
+	names := map[string]string{
+		"ID":            "id",
+		"Name":          "name",
+		"CreatedAt":     "created_at",
+		"UserID":        "user_id",
+		"HTTPServer":    "http_server",
+		"UUIDColumn":    "uuid_column",
+		"Float32Column": "float32_column",
+		"TestUserID":    "test_user_id",
+	}
+
+	for fieldName, expected := range names {
+		if columnName := SnakeCase(fieldName); columnName != expected {
+			t.Errorf("incorrect column name for %s - %s instead of %s", fieldName, columnName, expected)
+		}
+	}
+}
+
+func TestLowerCase(t *testing.T) {
+	if columnName := LowerCase("CreatedAt"); columnName != "createdat" {
+		t.Errorf("incorrect column name for CreatedAt - %s", columnName)
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	nouns := map[string]string{
+		"item":        "items",
+		"person":      "people",
+		"AdminPerson": "adminpeople",
+		"status":      "statuses",
+		"box":         "boxes",
+		"batch":       "batches",
+		"category":    "categories",
+		"day":         "days",
+		"SalesMan":    "salesmen",
+		"human":       "humans",
+		"series":      "series",
+		"German":      "germans",
+		"Talisman":    "talismans",
+		"Bluetooth":   "bluetooths",
+		"HTTPServer":  "httpservers",
+		"UserStatus":  "userstatuses",
+	}
+
+	for noun, expected := range nouns {
+		if plural := pluralize(noun); plural != expected {
+			t.Errorf("incorrect plural for %s - %s instead of %s", noun, plural, expected)
+		}
+	}
+}
+
+type TestStatus struct {
+	ID int64
+}
+
+type upperCaseNaming struct{}
+
+func (upperCaseNaming) TableName(t reflect.Type) string {
+	return strings.ToUpper(t.Name())
+}
+
+func (upperCaseNaming) ColumnName(fieldName string) string {
+	return strings.ToUpper(fieldName)
+}
+
+func TestNamingStrategy(t *testing.T) {
+	statusType := reflect.TypeOf((*TestStatus)(nil)).Elem()
+	if tableName := (DefaultNaming{TablePrefix: "app_"}).TableName(statusType); tableName != "app_teststatuses" {
+		t.Errorf("incorrect table name with prefix - %s", tableName)
+	}
+
+	if tableName := (DefaultNaming{TablePrefix: "app_"}).TableName(TestCategoryType); tableName != "testcategories" {
+		t.Errorf("prefix applied to the name set with TableNamer - %s", tableName)
+	}
+
+	statement := buildSelectStatement(statusType, "", false, 0, upperCaseNaming{})
+	if statement != `select "ID" from "TESTSTATUS" ;` {
+		t.Errorf("naming strategy not applied - %s", statement)
+	}
+}
+
+func TestTablePrefix(t *testing.T) {
+	statusType := reflect.TypeOf((*TestStatus)(nil)).Elem()
+
+	TablePrefix = "global_"
+	tableName := BuildTableName(statusType)
+	TablePrefix = ""
+	if tableName != "global_teststatuses" {
+		t.Errorf("incorrect table name with global prefix - %s", tableName)
+	}
+
+	prefixedDB := (&Database{}).WithTablePrefix("app_")
+	if tableName := prefixedDB.namingStrategy().TableName(statusType); tableName != "app_teststatuses" {
+		t.Errorf("incorrect table name with database prefix - %s", tableName)
+	}
+
+	prefixedDB = (&Database{}).WithNaming(upperCaseNaming{}).WithTablePrefix("app_")
+	if tableName := prefixedDB.namingStrategy().TableName(statusType); tableName != "app_TESTSTATUS" {
+		t.Errorf("incorrect table name with prefix and custom naming - %s", tableName)
+	}
+}
@@ -0,0 +1,85 @@
+package liteorm
+
+import (
+	"net"
+	"net/netip"
+	"reflect"
+)
+
+// ipType and ipNetType are the network types of the net package, which pgx converts from and to inet and cidr columns.
+// netipAddrType and netipPrefixType are those of the net/netip package, which liteorm scans through a netip.Prefix.
+var (
+	ipType          = reflect.TypeOf(net.IP{})
+	ipNetType       = reflect.TypeOf(net.IPNet{})
+	netipAddrType   = reflect.TypeOf(netip.Addr{})
+	netipPrefixType = reflect.TypeOf(netip.Prefix{})
+)
+
+// mapNetworkType maps the network types to their column types, i.e. addresses to inet and networks to cidr.
+func mapNetworkType(t reflect.Type) (string, bool) {
+	switch t {
+	case ipType, netipAddrType:
+		return "inet", true
+	case ipNetType, netipPrefixType:
+		return "cidr", true
+	}
+
+	return "", false
+}
+
+// isNetIPField reports whether a field holds a netip.Addr or a netip.Prefix, or a pointer to one of them.
+func isNetIPField(field reflect.StructField) bool {
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t == netipAddrType || t == netipPrefixType
+}
+
+// getNetIPValue returns the value stored for a netip field, i.e. the string form of the address or prefix. Nil pointers
+// and zero values are stored as null.
+func getNetIPValue(fieldv reflect.Value) any {
+	if fieldv.Kind() == reflect.Ptr {
+		if fieldv.IsNil() {
+			return nil
+		}
+		fieldv = fieldv.Elem()
+	}
+
+	switch value := fieldv.Interface().(type) {
+	case netip.Addr:
+		if value.IsValid() {
+			return value.String()
+		}
+	case netip.Prefix:
+		if value.IsValid() {
+			return value.String()
+		}
+	}
+
+	return nil
+}
+
+// setNetIPValue sets a netip field from the prefix scanned from its column. Null columns leave the field at its zero
+// value, i.e. a nil pointer or an invalid address or prefix.
+func setNetIPValue(fieldv reflect.Value, field reflect.StructField, prefix *netip.Prefix) {
+	fieldv.Set(reflect.Zero(field.Type))
+	if prefix == nil {
+		return
+	}
+
+	addr := prefix.Addr().Unmap()
+	value := reflect.ValueOf(addr)
+	if field.Type == netipPrefixType || field.Type == reflect.PtrTo(netipPrefixType) {
+		value = reflect.ValueOf(netip.PrefixFrom(addr, prefix.Bits()))
+	}
+
+	if field.Type.Kind() == reflect.Ptr {
+		pointer := reflect.New(value.Type())
+		pointer.Elem().Set(value)
+		value = pointer
+	}
+
+	fieldv.Set(value)
+}
@@ -0,0 +1,101 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// listenReconnectInterval is the wait before reconnecting a listener whose connection was lost.
+var listenReconnectInterval = time.Second
+
+// Listen subscribes to the notifications of a channel, see ListenCtx. It only returns if the subscription fails.
+func (db *Database) Listen(channel string, handler func(payload string)) error {
+	return db.ListenCtx(context.Background(), channel, handler)
+}
+
+// ListenCtx subscribes to the notifications of a channel and passes their payload to the handler, one at a time, until
+// the context is done. The notifications are received on a dedicated connection, which is reestablished and subscribed
+// again if it is lost, so ListenCtx is usually run in its own goroutine. Only the failure of the first subscription is
+// returned, since later ones are retried.
+func (db *Database) ListenCtx(ctx context.Context, channel string, handler func(payload string)) error {
+	errmsg := fmt.Sprintf("could not listen to channel %s", channel)
+
+	// the dedicated connection is configured like Conn, which is not set if the database was created from an executor
+	if db.Conn == nil {
+		return errors.New(fmt.Sprintf("%s - database has no connection to configure the listener", errmsg))
+	}
+
+	conn, err := connectListener(ctx, db.Conn.Config(), channel)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	for {
+		receiveNotifications(ctx, conn, handler)
+		conn.Close(context.Background())
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		conn = nil
+		for conn == nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(listenReconnectInterval):
+			}
+
+			conn, err = connectListener(ctx, db.Conn.Config(), channel)
+		}
+	}
+}
+
+// connectListener opens a connection subscribed to the channel received as argument.
+func connectListener(ctx context.Context, config *pgx.ConnConfig, channel string) (*pgx.Conn, error) {
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.Exec(ctx, fmt.Sprintf("listen %s;", quoteIdentifier(channel)))
+	if err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// receiveNotifications passes the notifications received on the connection to the handler until the connection fails
+// or the context is done. The error is not returned, since the listener reconnects in both cases.
+func receiveNotifications(ctx context.Context, conn *pgx.Conn, handler func(payload string)) {
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return
+		}
+
+		handler(notification.Payload)
+	}
+}
+
+// Notify sends a notification with the payload received as argument on a channel.
+func (db *Database) Notify(channel string, payload string) error {
+	return db.NotifyCtx(context.Background(), channel, payload)
+}
+
+func (db *Database) NotifyCtx(ctx context.Context, channel string, payload string) error {
+	return notify(ctx, db.session(), channel, payload)
+}
+
+func notify(ctx context.Context, s *session, channel string, payload string) error {
+	_, err := s.Exec(ctx, "select pg_notify($1, $2);", channel, payload)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not notify channel %s", channel))
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package liteorm
+
+import (
+	"time"
+)
+
+// Option configures a database created with NewDatabase, NewDatabaseFromConfig, NewDatabaseCluster, NewDatabaseFromSQL
+// or NewDatabaseFromExecutor. The options are equivalent to the With methods of Database, e.g. NewDatabase(dsn,
+// UseLogger(logger)) to db.WithLogger(logger), and are applied in order.
+type Option func(db *Database) *Database
+
+// UseLogger passes every statement run by the database to the logger received as argument, see Database.WithLogger.
+func UseLogger(logger Logger) Option {
+	return func(db *Database) *Database {
+		return db.WithLogger(logger)
+	}
+}
+
+// UseNaming maps types and fields to tables and columns with the naming strategy received as argument, see
+// Database.WithNaming.
+func UseNaming(naming NamingStrategy) Option {
+	return func(db *Database) *Database {
+		return db.WithNaming(naming)
+	}
+}
+
+// UseTimeout runs each statement of the database with a deadline, see Database.WithTimeout.
+func UseTimeout(timeout time.Duration) Option {
+	return func(db *Database) *Database {
+		return db.WithTimeout(timeout)
+	}
+}
+
+// UseDialect adapts the statements of the database to another engine than PostgreSQL, see Database.WithDialect.
+func UseDialect(dialect Dialect) Option {
+	return func(db *Database) *Database {
+		return db.WithDialect(dialect)
+	}
+}
+
+// UseTracer starts a span around each operation of the database, see Database.WithTracer.
+func UseTracer(tracer Tracer) Option {
+	return func(db *Database) *Database {
+		return db.WithTracer(tracer)
+	}
+}
+
+// UseMetrics observes each operation of the database, see Database.WithMetrics.
+func UseMetrics(metrics Metrics) Option {
+	return func(db *Database) *Database {
+		return db.WithMetrics(metrics)
+	}
+}
+
+// UseRetry retries the operations of the database failing with transient errors, see Database.WithRetry.
+func UseRetry(policy RetryPolicy) Option {
+	return func(db *Database) *Database {
+		return db.WithRetry(policy)
+	}
+}
+
+// applyOptions returns the database configured with the options received as argument.
+func applyOptions(db *Database, options []Option) *Database {
+	for _, option := range options {
+		db = option(db)
+	}
+
+	return db
+}
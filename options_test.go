@@ -0,0 +1,65 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	logger := LoggerFunc(func(ctx context.Context, sql string, args []any, duration time.Duration, err error) {})
+	db := NewDatabaseFromExecutor(nil, UseNaming(upperCaseNaming{}), UseTimeout(time.Second), UseLogger(logger),
+		UseDialect(SQLite{}), UseRetry(RetryPolicy{MaxAttempts: 3}))
+
+	if _, ok := db.naming.(upperCaseNaming); !ok {
+		t.Errorf("naming option not applied")
+	}
+
+	if db.timeout != time.Second || db.logger == nil || db.retryPolicy == nil || db.retryPolicy.MaxAttempts != 3 {
+		t.Errorf("options not applied")
+	}
+
+	if _, ok := db.sqlDialect().(SQLite); !ok {
+		t.Errorf("dialect option not applied")
+	}
+
+	sqlDB := stdlib.OpenDB(pgx.ConnConfig{})
+	defer sqlDB.Close()
+	fromSQL := NewDatabaseFromSQL(sqlDB, UseDialect(SQLite{}), UseTimeout(time.Second))
+	if _, ok := fromSQL.sqlDialect().(SQLite); !ok || fromSQL.timeout != time.Second {
+		t.Errorf("options not applied to database from SQL")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(UseTimeout(time.Second))
+	for _, name := range []string{"primary", "analytics"} {
+		err := registry.Register(name, NewDatabaseFromExecutor(nil))
+		if err != nil {
+			t.Fatalf("could not register database - %s", err.Error())
+		}
+	}
+
+	if err := registry.Register("primary", NewDatabaseFromExecutor(nil)); err == nil {
+		t.Errorf("name registered twice")
+	}
+
+	if db, ok := registry.Get("analytics"); !ok || db == nil {
+		t.Errorf("registered database not found")
+	}
+
+	if _, ok := registry.Get("reporting"); ok {
+		t.Errorf("unregistered database found")
+	}
+
+	if names := registry.Names(); len(names) != 2 || names[0] != "analytics" || names[1] != "primary" {
+		t.Errorf("incorrect names - %v", names)
+	}
+
+	registry.CloseAll()
+	if names := registry.Names(); len(names) != 0 {
+		t.Errorf("databases not removed - %v", names)
+	}
+}
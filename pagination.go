@@ -0,0 +1,246 @@
+package liteorm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Cursor selects a page of keyset pagination: the Limit objects following the object whose ID is After, in the order of
+// their IDs, or the first Limit objects if After is nil. Unlike offset pagination, the cost of selecting a page does not
+// grow with its depth, and objects inserted or deleted meanwhile do not shift the pages.
+type Cursor struct {
+	After any `json:"after,omitempty"`
+	Limit int `json:"limit"`
+}
+
+// Token encodes the cursor into an opaque string that can be handed to clients, e.g. in the responses of an API, and
+// decoded back with ParseCursor.
+func (c Cursor) Token() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", errors.Wrap(err, "could not encode cursor")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// ParseCursor decodes a cursor token returned by SelectPage or Token. The empty token decodes to the zero cursor, i.e.
+// the first page, whose limit is left to the caller.
+func ParseCursor(token string) (Cursor, error) {
+	errmsg := "invalid cursor token"
+	if token == "" {
+		return Cursor{}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, errors.Wrap(err, errmsg)
+	}
+
+	// numbers are decoded as integers rather than floats, so that integer IDs keep their precision
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	cursor := Cursor{}
+	err = decoder.Decode(&cursor)
+	if err != nil {
+		return Cursor{}, errors.Wrap(err, errmsg)
+	}
+
+	if number, ok := cursor.After.(json.Number); ok {
+		cursor.After, err = number.Int64()
+		if err != nil {
+			return Cursor{}, errors.Wrap(err, errmsg)
+		}
+	}
+
+	if cursor.Limit <= 0 {
+		return Cursor{}, errors.Wrap(errors.New("the limit must be positive"), errmsg)
+	}
+
+	return cursor, nil
+}
+
+// SelectPage selects the page of the cursor among the rows of the type received as argument matching the clauses, as a
+// slice of objects like Select, along with the token of the cursor of the next page, which is empty if this is the last
+// page. The clauses may not order or limit the rows.
+func (db *Database) SelectPage(t reflect.Type, cursor Cursor, clauses string, args ...any) (any, string, error) {
+	return db.SelectPageCtx(context.Background(), t, cursor, clauses, args...)
+}
+
+func (db *Database) SelectPageCtx(ctx context.Context, t reflect.Type, cursor Cursor, clauses string,
+	args ...any) (any, string, error) {
+	objects, err := retryResult(ctx, db, false, func() (any, error) {
+		return selectPage(ctx, db.readSession(), t, cursor, clauses, args...)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return nextPage(t, objects, cursor)
+}
+
+func (tx *Tx) SelectPage(t reflect.Type, cursor Cursor, clauses string, args ...any) (any, string, error) {
+	return tx.SelectPageCtx(context.Background(), t, cursor, clauses, args...)
+}
+
+func (tx *Tx) SelectPageCtx(ctx context.Context, t reflect.Type, cursor Cursor, clauses string,
+	args ...any) (any, string, error) {
+	objects, err := selectPage(ctx, tx.session(), t, cursor, clauses, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return nextPage(t, objects, cursor)
+}
+
+// selectPage selects the objects of the page of the cursor, followed by the first object of the next page if any, which
+// tells whether there is a next page.
+func selectPage(ctx context.Context, s *session, t reflect.Type, cursor Cursor, clauses string,
+	args ...any) (any, error) {
+	errmsg := fmt.Sprintf("could not select page of objects of type %s", t.Name())
+	clauses, args = expandClauses(clauses, args)
+	if cursor.Limit <= 0 {
+		return nil, errors.Wrap(errors.New("the limit of the cursor must be positive"), errmsg)
+	}
+
+	if _, ok := t.FieldByName("ID"); !ok {
+		return nil, errors.Wrap(errors.New("the type does not have an ID field"), errmsg)
+	}
+
+	first := cursor.After == nil
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	statement := buildBatchStatement(t, clauses, len(args), cursor.Limit+1, first, s.unscoped, tenantIdx,
+		s.namingStrategy())
+	if !first {
+		args = append(append(make([]any, 0, len(args)+1), args...), cursor.After)
+	}
+
+	objects, err := selectStatement(ctx, s, t, columnScanPlan(t), statement, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	return objects, nil
+}
+
+// nextPage trims the objects selected by selectPage to the page of the cursor, and returns them along with the token of
+// the cursor of the next page, after the ID of the last object of the page.
+func nextPage(t reflect.Type, objects any, cursor Cursor) (any, string, error) {
+	objectsv := reflect.ValueOf(objects)
+	if objectsv.Len() <= cursor.Limit {
+		return objects, "", nil
+	}
+
+	page := objectsv.Slice(0, cursor.Limit)
+	next := Cursor{After: page.Index(cursor.Limit - 1).FieldByName("ID").Interface(), Limit: cursor.Limit}
+	token, err := next.Token()
+	if err != nil {
+		return nil, "", errors.Wrap(err, fmt.Sprintf("could not select page of objects of type %s", t.Name()))
+	}
+
+	return page.Interface(), token, nil
+}
+
+// Page is a page of offset pagination, as returned by Paginate.
+type Page struct {
+	// Items is a slice of the objects of the page, like the result of Select.
+	Items any
+	// Total is the count of the rows matching the clauses across all pages.
+	Total int64
+	// Page is the number of the page, starting at 1.
+	Page int
+	// PerPage is the maximum number of objects of a page.
+	PerPage int
+	// Pages is the number of pages holding the rows matching the clauses.
+	Pages int
+}
+
+// Paginate selects the page of the given number, starting at 1, among the rows of the type received as argument
+// matching the clauses, with perPage objects per page, along with the total count of the rows matching the clauses.
+// Both are selected by a single statement, using a window function, unless the page is past the last one. The clauses
+// should order the rows, so that the pages are stable, but may not limit them. For deep pages, see SelectPage.
+func (db *Database) Paginate(t reflect.Type, page int, perPage int, clauses string, args ...any) (*Page, error) {
+	return db.PaginateCtx(context.Background(), t, page, perPage, clauses, args...)
+}
+
+func (db *Database) PaginateCtx(ctx context.Context, t reflect.Type, page int, perPage int, clauses string,
+	args ...any) (*Page, error) {
+	return retryResult(ctx, db, false, func() (*Page, error) {
+		return paginate(ctx, db.readSession(), t, page, perPage, clauses, args...)
+	})
+}
+
+func (tx *Tx) Paginate(t reflect.Type, page int, perPage int, clauses string, args ...any) (*Page, error) {
+	return tx.PaginateCtx(context.Background(), t, page, perPage, clauses, args...)
+}
+
+func (tx *Tx) PaginateCtx(ctx context.Context, t reflect.Type, page int, perPage int, clauses string,
+	args ...any) (*Page, error) {
+	return paginate(ctx, tx.session(), t, page, perPage, clauses, args...)
+}
+
+func paginate(ctx context.Context, s *session, t reflect.Type, page int, perPage int, clauses string,
+	args ...any) (_ *Page, err error) {
+	errmsg := fmt.Sprintf("could not paginate objects of type %s", t.Name())
+	clauses, args = expandClauses(clauses, args)
+	if page < 1 || perPage < 1 {
+		return nil, errors.Wrap(errors.New("the page and the number of objects per page must be positive"), errmsg)
+	}
+
+	ctx, span := s.startSpan(ctx, "paginate", s.namingStrategy().TableName(t))
+	defer func() { span.End(err) }()
+
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	statement := buildPaginateStatement(t, clauses, perPage, (page-1)*perPage, s.unscoped, tenantIdx,
+		s.namingStrategy())
+	rows, err := s.Query(ctx, statement, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+	defer rows.Close()
+
+	result := Page{Page: page, PerPage: perPage}
+	items := reflect.MakeSlice(reflect.SliceOf(t), 0, perPage)
+	plan := columnScanPlan(t)
+	columnValues := make([]any, len(plan.fields)+1)
+	for rows.Next() {
+		// the total count is scanned from the last column of every row
+		object := reflect.New(t)
+		plan.destinations(object.Elem(), columnValues)
+		columnValues[len(plan.fields)] = &result.Total
+		err = rows.Scan(columnValues...)
+		if err == nil {
+			err = plan.setConverted(s.settings, object.Elem(), columnValues)
+		}
+		if err == nil {
+			err = afterSelect(ctx, object.Interface())
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errmsg)
+		}
+
+		items = reflect.Append(items, object.Elem())
+	}
+
+	if rows.Err() != nil {
+		return nil, errors.Wrap(rows.Err(), errmsg)
+	}
+
+	// past the last page, no row holds the total count, which is then counted separately
+	if items.Len() == 0 && page > 1 {
+		statement = buildPaginateCountStatement(t, clauses, s.unscoped, tenantIdx, s.namingStrategy())
+		err = s.QueryRow(ctx, statement, args...).Scan(&result.Total)
+		if err != nil {
+			return nil, errors.Wrap(err, errmsg)
+		}
+	}
+
+	result.Items = items.Interface()
+	result.Pages = int((result.Total + int64(perPage) - 1) / int64(perPage))
+	return &result, nil
+}
@@ -0,0 +1,32 @@
+package liteorm
+
+import (
+	"testing"
+)
+
+func TestCursorToken(t *testing.T) {
+	for _, cursor := range []Cursor{{After: int64(9007199254740993), Limit: 10}, {After: "b7a1", Limit: 5},
+		{Limit: 1}} {
+		token, err := cursor.Token()
+		if err != nil {
+			t.Fatalf("could not encode cursor - %s", err.Error())
+		}
+
+		parsed, err := ParseCursor(token)
+		if err != nil || parsed != cursor {
+			t.Errorf("incorrect parsed cursor - %v, %v", parsed, err)
+		}
+	}
+
+	cursor, err := ParseCursor("")
+	if err != nil || cursor != (Cursor{}) {
+		t.Errorf("incorrect cursor of empty token - %v, %v", cursor, err)
+	}
+
+	for _, token := range []string{"!", "bm90IGpzb24", "eyJsaW1pdCI6MH0"} {
+		_, err = ParseCursor(token)
+		if err == nil {
+			t.Errorf("parsed invalid cursor token %s", token)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// partitionMethods are the partitioning methods of PostgreSQL, which the "partition" tag accepts.
+var partitionMethods = map[string]bool{"range": true, "list": true, "hash": true}
+
+// buildPartitionClause builds the partition by clause of the table of a type whose fields have the "partition" tag,
+// e.g. `partition:"range"`, which partitions the table by the columns of these fields with the method of the tag. It
+// returns false if the table is not partitioned.
+func buildPartitionClause(argt reflect.Type, naming NamingStrategy) (string, bool, error) {
+	method := ""
+	columnNames := make([]string, 0)
+	for _, field := range columnFields(argt) {
+		fieldMethod := field.Tag.Get("partition")
+		if fieldMethod == "" {
+			continue
+		}
+
+		if !partitionMethods[fieldMethod] {
+			return "", false, errors.New(fmt.Sprintf("unsupported partition method %q of field %s", fieldMethod,
+				field.Name))
+		}
+
+		if method != "" && method != fieldMethod {
+			return "", false, errors.New(fmt.Sprintf("type %s is partitioned by more than one method", argt.Name()))
+		}
+
+		method = fieldMethod
+		columnNames = append(columnNames, quoteIdentifier(columnName(field, naming)))
+	}
+
+	if method == "" {
+		return "", false, nil
+	}
+
+	return fmt.Sprintf("partition by %s (%s)", method, strings.Join(columnNames, ",")), true, nil
+}
+
+// partitionName returns the name of the partition of a table holding the rows from the time received as argument.
+func partitionName(tableName string, from time.Time) string {
+	return fmt.Sprintf("%s_%s", tableName, from.UTC().Format("20060102"))
+}
+
+// buildCreatePartitionStatement builds the statement that creates the partition of the table of a range partitioned
+// type holding the rows from the time from, inclusive, to the time to, exclusive, unless it already exists.
+func buildCreatePartitionStatement(argt reflect.Type, from time.Time, to time.Time, naming NamingStrategy) (string,
+	error) {
+	clause, ok, err := buildPartitionClause(argt, naming)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok || !strings.HasPrefix(clause, "partition by range ") {
+		return "", errors.New(fmt.Sprintf("type %s is not partitioned by range", argt.Name()))
+	}
+
+	if !from.Before(to) {
+		return "", errors.New(fmt.Sprintf("partition from %s to %s is empty", from, to))
+	}
+
+	// timestamps are stored in UTC, see setClientTimestamps
+	const layout = "2006-01-02 15:04:05.999999"
+	tableName := naming.TableName(argt)
+	return fmt.Sprintf("create table if not exists %s partition of %s for values from ('%s') to ('%s');",
+		quoteIdentifier(partitionName(tableName, from)), quoteIdentifier(tableName), from.UTC().Format(layout),
+		to.UTC().Format(layout)), nil
+}
+
+// EnsurePartition creates the partition of the table of a type partitioned by range, with a tag like
+// `partition:"range"` on a time field, that holds the rows from the time from, inclusive, to the time to, exclusive.
+// The partition is named after the table and the start of its range, e.g. events_20240101, and is left unchanged if it
+// already exists. The primary key of a partitioned table must include its partition columns.
+func (db *Database) EnsurePartition(t reflect.Type, from time.Time, to time.Time) error {
+	return db.EnsurePartitionCtx(context.Background(), t, from, to)
+}
+
+func (db *Database) EnsurePartitionCtx(ctx context.Context, t reflect.Type, from time.Time, to time.Time) error {
+	errmsg := fmt.Sprintf("could not create partition of table %s", db.namingStrategy().TableName(t))
+
+	statement, err := buildCreatePartitionStatement(t, from, to, db.namingStrategy())
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	_, err = db.session().Exec(ctx, statement)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	return nil
+}
+
+// EnsureMonthlyPartitions creates the partitions of the table of a type partitioned by range that hold the rows of
+// count consecutive months, starting with the month of the time received as argument in UTC, like EnsurePartition.
+// It is meant to run periodically, e.g. to create the partitions of the next months ahead of time.
+func (db *Database) EnsureMonthlyPartitions(t reflect.Type, month time.Time, count int) error {
+	return db.EnsureMonthlyPartitionsCtx(context.Background(), t, month, count)
+}
+
+func (db *Database) EnsureMonthlyPartitionsCtx(ctx context.Context, t reflect.Type, month time.Time,
+	count int) error {
+	month = month.UTC()
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < count; i++ {
+		to := from.AddDate(0, 1, 0)
+		err := db.EnsurePartitionCtx(ctx, t, from, to)
+		if err != nil {
+			return err
+		}
+		from = to
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package liteorm
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// The methods below return the statements that the corresponding operations would run, together with their arguments,
+// without executing them. They apply the settings of the database, e.g. soft delete and the naming strategy, but do not
+// call hooks.
+
+func (db *Database) CreateTableSQL(t reflect.Type) (string, error) {
+	statement, err := buildCreateStatement(t, db.namingStrategy(), db.sqlDialect())
+	if err != nil {
+		return "", errors.Wrap(err, "could not build create statement")
+	}
+
+	return statement, nil
+}
+
+func (db *Database) InsertSQL(arg any) (string, []any, error) {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "could not build insert statement")
+	}
+
+	values, err := buildStatementValues(arg)
+	if err == nil {
+		err = db.encryptValues(values)
+	}
+	if err != nil {
+		return "", nil, errors.Wrap(err, "could not build insert statement")
+	}
+
+	return buildInsertStatement(argt, db.namingStrategy(), db.sqlDialect()), values, nil
+}
+
+func (db *Database) SelectSQL(t reflect.Type, clauses string, args ...any) (string, []any) {
+	tenantIdx, args := tenantArgs(t, db.tenant, args)
+	return buildSelectStatement(t, clauses, db.unscoped, tenantIdx, db.namingStrategy()), args
+}
+
+func (db *Database) UpdateSQL(arg any, columns ...string) (string, []any, error) {
+	statement, values, err := buildUpdateOneStatement(arg, columns, db.tenant, db.namingStrategy(), db.sqlDialect())
+	if err == nil {
+		err = db.encryptValues(values)
+	}
+	if err != nil {
+		return "", nil, errors.Wrap(err, "could not build update statement")
+	}
+
+	return statement, values, nil
+}
+
+func (db *Database) DeleteSQL(t reflect.Type, clauses string, args ...any) (string, []any) {
+	tenantIdx, args := tenantArgs(t, db.tenant, args)
+	return buildScopedDeleteStatement(t, clauses, db.unscoped, tenantIdx, db.namingStrategy(), db.sqlDialect()), args
+}
@@ -0,0 +1,61 @@
+package liteorm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	previewDB := &Database{}
+
+	statement, values, err := previewDB.InsertSQL(&TestColumnItem{Email: "contact@lashbits.tech", Name: "lashbits.tech"})
+	if err != nil {
+		t.Fatalf("could not build insert statement - %s", err.Error())
+	}
+
+	expected := `insert into "testcolumnitems" ("email_address","name","deleted_at") values ($1,$2,$3) returning "item_id","email_address","name","deleted_at";`
+	if statement != expected || len(values) != 3 || values[0] != "contact@lashbits.tech" {
+		t.Errorf("incorrect insert statement - %s %v", statement, values)
+	}
+
+	statement, values, err = previewDB.UpdateSQL(&TestColumnItem{ID: 7, Name: "lashbits.tech"}, "Name")
+	if err != nil {
+		t.Fatalf("could not build update statement - %s", err.Error())
+	}
+
+	expected = `update "testcolumnitems" set "name" = $2 where "item_id" = $1;`
+	if statement != expected || len(values) != 2 || values[0] != int64(7) {
+		t.Errorf("incorrect update statement - %s %v", statement, values)
+	}
+
+	statement, _ = previewDB.DeleteSQL(TestColumnItemType, "where name = $1", "lashbits.tech")
+	expected = `update "testcolumnitems" set "deleted_at" = (now() at time zone 'utc') where "item_id" in (select "item_id" from (select * from "testcolumnitems" where "deleted_at" is null) "testcolumnitems" where name = $1);`
+	if statement != expected {
+		t.Errorf("incorrect delete statement - %s", statement)
+	}
+
+	statement, _ = previewDB.Unscoped().DeleteSQL(TestColumnItemType, "where name = $1", "lashbits.tech")
+	if statement != `delete from "testcolumnitems" where name = $1;` {
+		t.Errorf("incorrect unscoped delete statement - %s", statement)
+	}
+
+	statement, args := previewDB.SelectSQL(TestSkipItemType, "where id = $1", 1)
+	if statement != `select "id","name" from "testskipitems" where id = $1;` || len(args) != 1 {
+		t.Errorf("incorrect select statement - %s", statement)
+	}
+}
+
+func TestPreviewWithSchema(t *testing.T) {
+	tenantDB := (&Database{}).WithSchema("tenant_42")
+
+	statement, _ := tenantDB.SelectSQL(TestColumnItemType, "where name = $1", "lashbits.tech")
+	expected := `select "item_id","email_address","name","deleted_at" from (select * from "tenant_42"."testcolumnitems" where "deleted_at" is null) "testcolumnitems" where name = $1;`
+	if statement != expected {
+		t.Errorf("incorrect select statement - %s", statement)
+	}
+
+	statement, _, err := tenantDB.InsertSQL(&TestColumnItem{Email: "contact@lashbits.tech", Name: "lashbits.tech"})
+	if err != nil || !strings.HasPrefix(statement, `insert into "tenant_42"."testcolumnitems"`) {
+		t.Errorf("incorrect insert statement - %s", statement)
+	}
+}
@@ -0,0 +1,274 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"time"
+)
+
+// The statuses of the jobs of a Queue.
+const (
+	// JobPending is the status of the jobs waiting to be dequeued, including those retried after a failure
+	JobPending = "pending"
+
+	// JobRunning is the status of the jobs held by a worker until their visibility timeout expires
+	JobRunning = "running"
+
+	// JobDone is the status of the jobs completed by a worker
+	JobDone = "done"
+
+	// JobFailed is the status of the jobs that failed as many times as allowed by the queue
+	JobFailed = "failed"
+)
+
+// ErrJobNotHeld is returned when completing or failing a job that is no longer held by the worker, because its
+// visibility timeout expired and it was dequeued again, or because it was already completed or failed.
+var ErrJobNotHeld = errors.New("job is not held by the worker")
+
+// JobState holds the state of a job of a Queue. It is embedded into the models of the jobs, whose table then holds the
+// queue, e.g.
+//
+//	type EmailJob struct {
+//		ID int64
+//		To string `pglen:"255"`
+//		liteorm.JobState
+//	}
+type JobState struct {
+	// Status is one of JobPending, JobRunning, JobDone and JobFailed
+	Status string `pglen:"10"`
+	// Attempts is the number of times the job was dequeued
+	Attempts int
+	// VisibleAt is the time from which the job can be dequeued, i.e. its scheduled time while it is pending and the
+	// expiry of its visibility timeout while it is running
+	VisibleAt time.Time
+	// LastError is the error of the last failure of the job, truncated to 1000 characters
+	LastError string `pglen:"1000"`
+}
+
+// maxLastErrorLength is the length of the last_error column of the jobs.
+const maxLastErrorLength = 1000
+
+var jobStateType = reflect.TypeOf(JobState{})
+
+// Queue is a job queue stored in the table of the type T, which embeds JobState, e.g. NewQueue[EmailJob](db). Workers
+// dequeue jobs with "for update skip locked", so that concurrent workers claim distinct jobs without waiting for each
+// other, and hold them for the visibility timeout of the queue. Jobs held by a worker that neither completes nor fails
+// them in time, e.g. because it crashed, are dequeued again once their visibility timeout expires.
+type Queue[T any] struct {
+	db                *Database
+	visibilityTimeout time.Duration
+	maxAttempts       int
+}
+
+// NewQueue returns the queue of the jobs of type T stored in the database received as argument, whose table must have
+// been created, e.g. with CreateTable. The jobs are held for 5 minutes once dequeued, and retried until they succeed,
+// see WithVisibilityTimeout and WithMaxAttempts.
+func NewQueue[T any](db *Database) *Queue[T] {
+	return &Queue[T]{db: db, visibilityTimeout: 5 * time.Minute}
+}
+
+// WithVisibilityTimeout returns a copy of the queue that holds the jobs it dequeues for the duration received as
+// argument, which should exceed the time it takes to process them.
+func (q *Queue[T]) WithVisibilityTimeout(d time.Duration) *Queue[T] {
+	configured := *q
+	configured.visibilityTimeout = d
+	return &configured
+}
+
+// WithMaxAttempts returns a copy of the queue that marks the jobs as failed for good once they failed the number of
+// times received as argument, rather than retrying them. A job whose visibility timeout expired counts as an attempt
+// when it is dequeued again, but is only marked as failed by Fail.
+func (q *Queue[T]) WithMaxAttempts(attempts int) *Queue[T] {
+	configured := *q
+	configured.maxAttempts = attempts
+	return &configured
+}
+
+// Enqueue inserts the job received as argument, a pointer to a T, as pending. The job can be dequeued from its
+// VisibleAt field on, which defaults to the current time, so that jobs can be scheduled for later in any location.
+func (q *Queue[T]) Enqueue(job *T) error {
+	return q.EnqueueCtx(context.Background(), job)
+}
+
+func (q *Queue[T]) EnqueueCtx(ctx context.Context, job *T) error {
+	state, err := getJobState(job)
+	if err != nil {
+		return errors.Wrap(err, "could not enqueue job")
+	}
+
+	state.Status = JobPending
+	state.Attempts = 0
+	state.LastError = ""
+	if state.VisibleAt.IsZero() {
+		state.VisibleAt = time.Now()
+	}
+
+	// the column has no time zone and is compared with the current time in UTC, so the wall clock must be in UTC
+	state.VisibleAt = state.VisibleAt.UTC()
+
+	return q.db.InsertCtx(ctx, job)
+}
+
+// Dequeue claims up to n visible jobs, i.e. pending jobs whose scheduled time passed and running jobs whose visibility
+// timeout expired, in the order of their visibility, and holds them for the visibility timeout of the queue. It
+// returns the claimed jobs, which may be fewer than n, or none if no job is visible.
+func (q *Queue[T]) Dequeue(n int) ([]T, error) {
+	return q.DequeueCtx(context.Background(), n)
+}
+
+func (q *Queue[T]) DequeueCtx(ctx context.Context, n int) ([]T, error) {
+	t := typeOf[T]()
+	errmsg := fmt.Sprintf("could not dequeue jobs of type %s", t.Name())
+	if err := checkJobType(t); err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	s := q.db.session()
+	statement := buildDequeueStatement(t, q.visibilityTimeout, s.namingStrategy(), s.sqlDialect())
+	jobs, err := selectStatement(ctx, s, t, columnScanPlan(t), statement, n)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	return jobs.([]T), nil
+}
+
+// Complete marks the job received as argument, which must be held by the worker, as done.
+func (q *Queue[T]) Complete(job *T) error {
+	return q.CompleteCtx(context.Background(), job)
+}
+
+func (q *Queue[T]) CompleteCtx(ctx context.Context, job *T) error {
+	return q.finish(ctx, job, nil)
+}
+
+// Fail records the error received as argument as the last error of the job, which must be held by the worker, and
+// makes the job visible again to be retried, unless it reached the maximum number of attempts of the queue, in which
+// case it is marked as failed.
+func (q *Queue[T]) Fail(job *T, cause error) error {
+	return q.FailCtx(context.Background(), job, cause)
+}
+
+func (q *Queue[T]) FailCtx(ctx context.Context, job *T, cause error) error {
+	if cause == nil {
+		cause = errors.New("job failed")
+	}
+
+	return q.finish(ctx, job, cause)
+}
+
+// finish ends the attempt of the job received as argument, which is completed unless cause is set, and sets the status
+// of the job to its new status.
+func (q *Queue[T]) finish(ctx context.Context, job *T, cause error) error {
+	t := typeOf[T]()
+	errmsg := fmt.Sprintf("could not complete job of type %s", t.Name())
+	if cause != nil {
+		errmsg = fmt.Sprintf("could not fail job of type %s", t.Name())
+	}
+
+	state, err := getJobState(job)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	id, err := getIDValue(job)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	s := q.db.session()
+	statement := buildFinishJobStatement(t, s.namingStrategy(), s.sqlDialect())
+	lastError := state.LastError
+	if cause != nil {
+		lastError = cause.Error()
+		if runes := []rune(lastError); len(runes) > maxLastErrorLength {
+			lastError = string(runes[:maxLastErrorLength])
+		}
+	}
+
+	var status string
+	err = s.QueryRow(ctx, statement, id, state.Attempts, cause != nil, q.maxAttempts, lastError).Scan(&status)
+	if errors.Is(err, ErrNotFound) {
+		return errors.Wrap(ErrJobNotHeld, errmsg)
+	}
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	state.Status = status
+	state.LastError = lastError
+	return nil
+}
+
+// getJobState returns the JobState embedded into the job received as argument.
+func getJobState(job any) (*JobState, error) {
+	argv, err := getObjectValue(job)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkJobType(argv.Type()); err != nil {
+		return nil, err
+	}
+
+	field, _ := argv.Type().FieldByName(jobStateType.Name())
+	return argv.FieldByIndex(field.Index).Addr().Interface().(*JobState), nil
+}
+
+// checkJobType reports an error unless the type received as argument is a job type, i.e. a struct with an ID field
+// that embeds JobState.
+func checkJobType(t reflect.Type) error {
+	if _, ok := t.FieldByName("ID"); !ok {
+		return errors.New(fmt.Sprintf("job type %s does not have an ID field", t.Name()))
+	}
+
+	field, ok := t.FieldByName(jobStateType.Name())
+	if !ok || !field.Anonymous || field.Type != jobStateType {
+		return errors.New(fmt.Sprintf("job type %s does not embed JobState", t.Name()))
+	}
+
+	return nil
+}
+
+// buildDequeueStatement builds the statement claiming the first visible jobs, as many as the first argument of the
+// statement, and returning their columns. The jobs are locked with "for update skip locked" while they are claimed, so
+// that concurrent statements claim distinct jobs. Like the timestamps set by the database, see
+// Dialect.CurrentTimestamp, the times of the jobs are in UTC.
+func buildDequeueStatement(t reflect.Type, visibilityTimeout time.Duration, naming NamingStrategy,
+	dialect Dialect) string {
+	tableName := quoteIdentifier(naming.TableName(t))
+	idColumnName := quoteIdentifier(fieldColumnName(t, "ID", naming))
+	statusColumnName := quoteIdentifier(fieldColumnName(t, "Status", naming))
+	attemptsColumnName := quoteIdentifier(fieldColumnName(t, "Attempts", naming))
+	visibleAtColumnName := quoteIdentifier(fieldColumnName(t, "VisibleAt", naming))
+	now := dialect.CurrentTimestamp()
+
+	visible := fmt.Sprintf("select %s from %s where %s in ('%s','%s') and %s <= %s order by %s, %s limit $1 "+
+		"for update skip locked", idColumnName, tableName, statusColumnName, JobPending, JobRunning,
+		visibleAtColumnName, now, visibleAtColumnName, idColumnName)
+	return fmt.Sprintf("update %s set %s = '%s', %s = %s + 1, %s = %s + interval '%d milliseconds' where %s in (%s) "+
+		"returning %s;", tableName, statusColumnName, JobRunning, attemptsColumnName, attemptsColumnName,
+		visibleAtColumnName, now, visibilityTimeout.Milliseconds(), idColumnName, visible,
+		buildSelectColumnNames(columnFields(t), naming))
+}
+
+// buildFinishJobStatement builds the statement ending the attempt of a job, given by its ID and number of attempts, as
+// long as the job is held by the worker. The job is done unless the third argument is set, in which case it is retried,
+// unless it reached the maximum number of attempts given by the fourth argument, if positive. The last error of the job
+// is set to the fifth argument, and the statement returns the new status of the job.
+func buildFinishJobStatement(t reflect.Type, naming NamingStrategy, dialect Dialect) string {
+	tableName := quoteIdentifier(naming.TableName(t))
+	idColumnName := quoteIdentifier(fieldColumnName(t, "ID", naming))
+	statusColumnName := quoteIdentifier(fieldColumnName(t, "Status", naming))
+	attemptsColumnName := quoteIdentifier(fieldColumnName(t, "Attempts", naming))
+	visibleAtColumnName := quoteIdentifier(fieldColumnName(t, "VisibleAt", naming))
+	lastErrorColumnName := quoteIdentifier(fieldColumnName(t, "LastError", naming))
+
+	status := fmt.Sprintf("case when not $3 then '%s' when $4 > 0 and %s >= $4 then '%s' else '%s' end", JobDone,
+		attemptsColumnName, JobFailed, JobPending)
+	return fmt.Sprintf("update %s set %s = %s, %s = %s, %s = $5 where %s = $1 and %s = $2 and %s = '%s' returning %s;",
+		tableName, statusColumnName, status, visibleAtColumnName, dialect.CurrentTimestamp(), lastErrorColumnName,
+		idColumnName, attemptsColumnName, statusColumnName, JobRunning, statusColumnName)
+}
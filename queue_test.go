@@ -0,0 +1,28 @@
+package liteorm
+
+import (
+	"github.com/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestEnqueueVisibleAt(t *testing.T) {
+	queue := NewQueue[TestJob](NewDatabaseFromExecutor(nilRowsExecutor{err: errors.New("insert failed")}))
+	scheduled := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("UTC+5", 5*60*60))
+	job := &TestJob{Recipient: "scheduled", JobState: JobState{VisibleAt: scheduled}}
+	_ = queue.Enqueue(job)
+
+	if job.VisibleAt.Location() != time.UTC || !job.VisibleAt.Equal(scheduled) || job.VisibleAt.Hour() != 4 {
+		t.Errorf("scheduled time not converted to UTC - %v", job.VisibleAt)
+	}
+
+	job = &TestJob{Recipient: "now"}
+	_ = queue.Enqueue(job)
+	if job.VisibleAt.Location() != time.UTC || time.Since(job.VisibleAt) > time.Minute {
+		t.Errorf("incorrect default visibility - %v", job.VisibleAt)
+	}
+
+	if job.Status != JobPending {
+		t.Errorf("incorrect status of enqueued job - %s", job.Status)
+	}
+}
@@ -0,0 +1,168 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Query runs an arbitrary statement and scans its result into dest, which is either a pointer to a struct that receives
+// the first row, or a pointer to a slice of structs or pointers to structs that receives all rows. The columns of the
+// result are mapped onto the fields of the struct by column name, so joins and reporting queries keep the convenience
+// of the struct mapping. Result columns without a matching field are an error. As the statement may modify rows, it is
+// retried like a write, i.e. only if the retry policy sets RetryWrites.
+func (db *Database) Query(dest any, sql string, args ...any) error {
+	return db.QueryCtx(context.Background(), dest, sql, args...)
+}
+
+func (db *Database) QueryCtx(ctx context.Context, dest any, sql string, args ...any) error {
+	return db.retry(ctx, true, func() error {
+		return queryInto(ctx, db.session(), dest, sql, args...)
+	})
+}
+
+func queryInto(ctx context.Context, s *session, dest any, sql string, args ...any) error {
+	destv := reflect.ValueOf(dest)
+	if destv.Kind() != reflect.Ptr || destv.IsNil() {
+		return errors.New("could not run query - provided argument is not a pointer")
+	}
+	destv = destv.Elem()
+
+	elemt := destv.Type()
+	many := elemt.Kind() == reflect.Slice
+	if many {
+		elemt = elemt.Elem()
+	}
+
+	pointerElems := elemt.Kind() == reflect.Ptr
+	if pointerElems {
+		elemt = elemt.Elem()
+	}
+
+	if elemt.Kind() != reflect.Struct || (pointerElems && !many) {
+		return errors.New("could not run query - provided argument is not a pointer to a struct or slice of structs")
+	}
+
+	errmsg := fmt.Sprintf("could not query objects of type %s", elemt.Name())
+
+	rows, err := s.Query(ctx, sql, args...)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+	defer rows.Close()
+
+	columnNames := make([]string, 0)
+	for _, description := range rows.FieldDescriptions() {
+		columnNames = append(columnNames, description.Name)
+	}
+
+	fields, err := buildResultFields(elemt, columnNames, s.namingStrategy())
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	var result reflect.Value
+	if many {
+		result = reflect.MakeSlice(destv.Type(), 0, 0)
+	}
+
+	found := false
+	for !found && rows.Next() {
+		columnValues := buildSliceFromFieldList(fields)
+		err = rows.Scan(columnValues...)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		newelem := reflect.New(elemt)
+		err = s.decryptValues(columnValues)
+		if err == nil {
+			err = setObjectFieldList(newelem.Interface(), fields, columnValues...)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		err = afterSelect(ctx, newelem.Interface())
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		switch {
+		case !many:
+			destv.Set(newelem.Elem())
+			found = true
+		case pointerElems:
+			result = reflect.Append(result, newelem)
+		default:
+			result = reflect.Append(result, newelem.Elem())
+		}
+	}
+
+	if rows.Err() != nil {
+		return errors.Wrap(rows.Err(), errmsg)
+	}
+
+	if !many {
+		if !found {
+			return errors.Wrap(ErrNotFound, errmsg)
+		}
+		return nil
+	}
+
+	destv.Set(result)
+	return nil
+}
+
+// buildResultFields returns the column fields of the type that receive the result columns received as argument, in the
+// order of the columns.
+func buildResultFields(argt reflect.Type, columnNames []string, naming NamingStrategy) ([]reflect.StructField, error) {
+	fieldsByColumn := make(map[string]reflect.StructField)
+	for _, field := range columnFields(argt) {
+		fieldsByColumn[columnName(field, naming)] = field
+	}
+
+	fields := make([]reflect.StructField, len(columnNames))
+	for i, columnName := range columnNames {
+		field, ok := fieldsByColumn[columnName]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("type %s does not have a field for column %s", argt.Name(), columnName))
+		}
+
+		fields[i] = field
+	}
+
+	return fields, nil
+}
+
+// QueryMaps runs an arbitrary statement and returns each row of its result as a map from column name to value, for
+// ad-hoc queries without a matching struct. The values are of the types pgx decodes the columns to. The statement is
+// retried like a write, see Query.
+func (db *Database) QueryMaps(sql string, args ...any) ([]map[string]any, error) {
+	return db.QueryMapsCtx(context.Background(), sql, args...)
+}
+
+func (db *Database) QueryMapsCtx(ctx context.Context, sql string, args ...any) ([]map[string]any, error) {
+	return retryResult(ctx, db, true, func() ([]map[string]any, error) {
+		return queryMaps(ctx, db.session(), sql, args...)
+	})
+}
+
+func queryMaps(ctx context.Context, s *session, sql string, args ...any) ([]map[string]any, error) {
+	errmsg := "could not query rows as maps"
+
+	rows, err := s.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+	defer rows.Close()
+
+	result, err := pgx.CollectRows(rows, pgx.RowToMap)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	return result, nil
+}
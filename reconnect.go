@@ -0,0 +1,113 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// ErrConnClosed is returned by the operations of a database whose connection was lost and could not be reestablished,
+// e.g. while PostgreSQL is restarting. The next operation tries to reconnect again.
+var ErrConnClosed = errors.New("connection closed")
+
+// connClosedError is the error of the operations run while the connection cannot be reestablished, which matches
+// ErrConnClosed and wraps the error of the last reconnection attempt.
+type connClosedError struct {
+	err error
+}
+
+func (e connClosedError) Error() string {
+	return ErrConnClosed.Error() + ": " + e.err.Error()
+}
+
+func (e connClosedError) Is(target error) bool {
+	return target == ErrConnClosed
+}
+
+func (e connClosedError) Unwrap() error {
+	return e.err
+}
+
+// reconnector holds the connection of a database created with NewDatabase, and replaces it with a new connection with
+// the same configuration once it is closed, e.g. after a network failure. The statement that was running when the
+// connection was lost fails, while the next one runs on the new connection. It is shared by the copies of the database,
+// so that they all use the new connection, whereas the Conn field keeps the original one.
+type reconnector struct {
+	mutex  sync.Mutex
+	conn   *pgx.Conn
+	closed bool
+}
+
+// connection returns the connection, after reconnecting if it was lost. If reconnecting fails, it returns an executor
+// whose statements fail with ErrConnClosed.
+func (r *reconnector) connection() Executor {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed || !r.conn.IsClosed() {
+		return r.conn
+	}
+
+	conn, err := pgx.ConnectConfig(context.Background(), r.conn.Config())
+	if err != nil {
+		return closedExecutor{err: connClosedError{err: err}}
+	}
+
+	r.conn = conn
+	return conn
+}
+
+// close closes the connection and stops reconnecting.
+func (r *reconnector) close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.closed = true
+	r.conn.Close(context.Background())
+}
+
+// closedExecutor is the executor of a lost connection, whose statements and transactions fail with the error it holds.
+type closedExecutor struct {
+	err error
+}
+
+func (e closedExecutor) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, e.err
+}
+
+func (e closedExecutor) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return errorRows{err: e.err}, e.err
+}
+
+func (e closedExecutor) QueryRow(context.Context, string, ...any) pgx.Row {
+	return errorRow{err: e.err}
+}
+
+func (e closedExecutor) SendBatch(context.Context, *pgx.Batch) pgx.BatchResults {
+	return errorBatchResults{err: e.err}
+}
+
+func (e closedExecutor) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, e.err
+}
+
+func (e closedExecutor) Begin(context.Context) (pgx.Tx, error) {
+	return nil, e.err
+}
+
+// Ping checks that the database is reachable by running an empty statement, e.g. for the health checks of a service.
+// If the connection was lost, Ping reconnects first and fails with ErrConnClosed if it cannot.
+func (db *Database) Ping() error {
+	return db.PingCtx(context.Background())
+}
+
+func (db *Database) PingCtx(ctx context.Context) error {
+	_, err := db.session().Exec(ctx, ";")
+	if err != nil {
+		return errors.Wrap(err, "could not ping database")
+	}
+
+	return nil
+}
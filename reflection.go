@@ -1,11 +1,17 @@
 package liteorm
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pkg/errors"
+	"net/netip"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // getObjectValue receives either a struct or pointer to struct type as argument, and returns its reflect.Value. If
@@ -40,6 +46,11 @@ func getObjectType(arg any) (reflect.Type, error) {
 	return argt, nil
 }
 
+// typeOf returns the reflect.Type of the type parameter T.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
 // getSliceValue receives a pointer to a slice type as argument (of type any) and returns the value (of type
 // reflect.Value).
 func getSliceValue(arg any) (reflect.Value, error) {
@@ -57,6 +68,32 @@ func getSliceValue(arg any) (reflect.Value, error) {
 	}
 }
 
+// getSliceObjects receives a slice (or pointer to slice) of structs or pointers to structs as argument, and returns a
+// pointer to each of its elements, so that the fields of the elements can be set.
+func getSliceObjects(arg any) ([]any, error) {
+	slice := reflect.Indirect(reflect.ValueOf(arg))
+	if slice.Kind() != reflect.Slice {
+		return nil, errors.New("provided argument is not a slice or pointer to slice")
+	}
+
+	objects := make([]any, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		elem := slice.Index(i)
+		if elem.Kind() != reflect.Ptr {
+			// slice elements are addressable, so taking their address allows setting the fields in place
+			elem = elem.Addr()
+		}
+
+		if elem.Elem().Kind() != reflect.Struct {
+			return nil, errors.New("provided argument is not a slice of structs or pointers to struct")
+		}
+
+		objects[i] = elem.Interface()
+	}
+
+	return objects, nil
+}
+
 // getSliceElemType receives a pointer to a slice type as argument and returns the type of the slice elements.
 func getSliceElemType(arg any) (reflect.Type, error) {
 	if reflect.TypeOf(arg).Kind() != reflect.Ptr {
@@ -76,14 +113,223 @@ func getSliceElemType(arg any) (reflect.Type, error) {
 	return slice.Type().Elem().Elem(), nil
 }
 
-// idColumnType is the PostgreSQL column type for ID columns.
+// parseTag parses the "liteorm" tag of a struct field. The tag is a comma separated list of options, each either a
+// single key (e.g. "hasmany") or a key and value separated by a colon (e.g. "fk:userid"). Keys without a value are
+// mapped to the empty string.
+func parseTag(field reflect.StructField) map[string]string {
+	options := make(map[string]string)
+	tag := field.Tag.Get("liteorm")
+	if tag == "" {
+		return options
+	}
+
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), ":")
+		options[key] = value
+	}
+
+	return options
+}
+
+// isColumn reports whether a struct field maps to a column of the table. Relation fields are not columns, since their
+// values are loaded from other tables by Preload, and neither are fields tagged with liteorm:"-".
+func isColumn(field reflect.StructField) bool {
+	if _, skip := parseTag(field)["-"]; skip {
+		return false
+	}
+
+	_, isRelation := getRelationKind(field)
+	return !isRelation
+}
+
+// columnName returns the name of the column a field maps to, which is the value of the "column" tag if present, and
+// the column name derived by the naming strategy otherwise.
+func columnName(field reflect.StructField, naming NamingStrategy) string {
+	if name := field.Tag.Get("column"); name != "" {
+		return name
+	}
+
+	return naming.ColumnName(field.Name)
+}
+
+// fieldColumnName returns the name of the column the named field of the type maps to. If the type does not have such a
+// field, the name is derived by the naming strategy.
+func fieldColumnName(argt reflect.Type, fieldName string, naming NamingStrategy) string {
+	if field, ok := argt.FieldByName(fieldName); ok {
+		return columnName(field, naming)
+	}
+
+	return naming.ColumnName(fieldName)
+}
+
+// columnFields returns the fields of the type received as argument that map to columns, in declaration order. The
+// fields of embedded structs are flattened into the columns of the type, with their index set to the full path from the
+// type so that they can be accessed with FieldByIndex. The fields are cached per type, see columnFieldsCache.
+func columnFields(argt reflect.Type) []reflect.StructField {
+	cached, ok := columnFieldsCache.Load(argt)
+	if !ok {
+		cached, _ = columnFieldsCache.LoadOrStore(argt, buildColumnFields(argt))
+	}
+
+	// the callers get their own copy, which they may modify
+	fields := cached.([]reflect.StructField)
+	return append(make([]reflect.StructField, 0, len(fields)), fields...)
+}
+
+// buildColumnFields builds the column fields of a type, see columnFields.
+func buildColumnFields(argt reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, argt.NumField())
+	for i := 0; i < argt.NumField(); i++ {
+		field := argt.Field(i)
+		if !isColumn(field) {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			for _, embeddedField := range columnFields(field.Type) {
+				embeddedField.Index = append([]int{i}, embeddedField.Index...)
+				fields = append(fields, embeddedField)
+			}
+
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// isEmbeddedStruct reports whether a field is an embedded struct whose fields are flattened into the columns of the
+// parent type. Embedded structs that map to a single column themselves, such as time.Time, UUIDs, custom Scanner types
+// or structs with an explicit column type, are not flattened.
+func isEmbeddedStruct(field reflect.StructField) bool {
+	if !field.Anonymous || field.Type.Kind() != reflect.Struct {
+		return false
+	}
+
+	options := parseTag(field)
+	if _, ok := options["jsonb"]; ok || options["type"] != "" {
+		return false
+	}
+
+	return !field.Type.ConvertibleTo(timeType) && !isUUIDType(field.Type) &&
+		!reflect.PtrTo(field.Type).Implements(scannerType)
+}
+
+// idColumnType is the PostgreSQL column type for the ID columns of IDSerial, the default ID strategy of integer IDs.
 var idColumnType = "bigserial"
 
-// mapColumnType maps a reflect.StructField object to a PostgreSQL column type.
+// uuidIDColumnType is the PostgreSQL column type for UUID ID columns. Before PostgreSQL 13, gen_random_uuid requires
+// the pgcrypto extension.
+var uuidIDColumnType = "uuid default gen_random_uuid()"
+
+// timeType, uuidType and numericType are the struct types that map to a dedicated PostgreSQL column type. Struct fields
+// are matched against them by convertibility, so that named types declared over them (e.g. type Timestamp time.Time)
+// are supported.
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	uuidType    = reflect.TypeOf(pgtype.UUID{})
+	numericType = reflect.TypeOf(pgtype.Numeric{})
+)
+
+// decimalPackagePath is the import path of github.com/shopspring/decimal, whose Decimal type is stored as numeric. It
+// is matched by name so that liteorm does not depend on the package.
+const decimalPackagePath = "github.com/shopspring/decimal"
+
+// scannerType and valuerType are the interfaces implemented by types that convert themselves from and to column
+// values, such as sql.NullString.
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// mapColumnType maps a reflect.StructField object to a PostgreSQL column type. The column type can be set explicitly
+// with the "type" option of the liteorm tag, e.g. `liteorm:"type:jsonb"`, which is required for custom types
+// implementing sql.Scanner and driver.Valuer that are not nullable wrappers of a supported type.
 func mapColumnType(field reflect.StructField) (string, error) {
-	switch field.Type.Kind() {
+	if columnType := parseTag(field)["type"]; columnType != "" {
+		return columnType, nil
+	}
+
+	if isEncryptedField(field) {
+		return mapEncryptedColumnType(field)
+	}
+
+	if isJSONField(field) {
+		return "jsonb", nil
+	}
+
+	if field.Type == tsvectorType {
+		return "tsvector", nil
+	}
+
+	return mapType(field, field.Type)
+}
+
+// isGeneratedField reports whether the value of a field is generated by the database, i.e. whether it has the
+// "generated" option in its liteorm tag, e.g. for columns with a default or generated columns, or whether it is a
+// full-text search field. Generated fields are left out of inserts and updates, and set from the row returned by
+// inserts.
+func isGeneratedField(field reflect.StructField) bool {
+	_, ok := parseTag(field)["generated"]
+	return ok || field.Type == tsvectorType
+}
+
+// isJSONField reports whether a field is stored as a jsonb column, i.e. whether it is a map with string keys or has the
+// "jsonb" option in its liteorm tag (e.g. for nested structs). The values of json fields are marshalled on insert and
+// update, and unmarshalled on select.
+func isJSONField(field reflect.StructField) bool {
+	if _, ok := parseTag(field)["jsonb"]; ok {
+		return true
+	}
+
+	return field.Type.Kind() == reflect.Map && field.Type.Key().Kind() == reflect.String
+}
+
+// getNullValueType returns the type of the value wrapped by a nullable type such as sql.NullString or sql.NullInt64,
+// i.e. a struct implementing sql.Scanner and driver.Valuer with a Valid bool field and a single other field.
+func getNullValueType(t reflect.Type) (reflect.Type, bool) {
+	if !reflect.PtrTo(t).Implements(scannerType) || !reflect.PtrTo(t).Implements(valuerType) || t.NumField() != 2 {
+		return nil, false
+	}
+
+	for i := 0; i < 2; i++ {
+		validField, otherField := t.Field(i), t.Field(1-i)
+		if validField.Name == "Valid" && validField.Type.Kind() == reflect.Bool {
+			return otherField.Type, true
+		}
+	}
+
+	return nil, false
+}
+
+// mapType maps a reflect.Type to a PostgreSQL column type. The type is classified by its kind, so named types over
+// supported kinds map in the same way as their underlying types. The struct field is used to read the tags (e.g. the
+// length of strings).
+func mapType(field reflect.StructField, t reflect.Type) (string, error) {
+	if _, ok := getEnumValues(t); ok {
+		return enumTypeName(t), nil
+	}
+
+	// network and duration types are checked first, since net.IP is a byte slice and time.Duration an int64
+	if columnType, ok := mapNetworkType(t); ok {
+		return columnType, nil
+	}
+
+	if columnType, ok := mapDurationType(field, t); ok {
+		return columnType, nil
+	}
+
+	switch t.Kind() {
 	// basic types
-	case reflect.Int:
+	case reflect.Bool:
+		return "boolean", nil
+
+	case reflect.Int16:
+		return "smallint", nil
+
+	case reflect.Int, reflect.Int32:
 		return "int", nil
 
 	case reflect.Int64:
@@ -105,27 +351,96 @@ func mapColumnType(field reflect.StructField) (string, error) {
 
 	// composite types
 	case reflect.Struct:
-		switch fieldTypeName := fmt.Sprintf("%s.%s", field.Type.PkgPath(), field.Type.Name()); fieldTypeName {
-		case "time.Time":
+		switch {
+		case t.ConvertibleTo(timeType):
 			return "timestamp", nil
-		default:
-			return "", errors.New(fmt.Sprintf("unsupported struct type - %s", fieldTypeName))
+		case isUUIDType(t):
+			return "uuid", nil
+		case isNumericType(t):
+			return buildNumericType(field)
+		}
+
+		// nullable wrappers map to the column type of the value they wrap
+		if valueType, ok := getNullValueType(t); ok {
+			return mapType(field, valueType)
 		}
 
-	// slice types
+		return "", errors.New(fmt.Sprintf("unsupported struct type - %s", t))
+
+	// array types; only 16 byte arrays are supported, as UUIDs
+	case reflect.Array:
+		if isUUIDType(t) {
+			return "uuid", nil
+		}
+		return "", errors.New(fmt.Sprintf("unsupported array type - %s", t))
+
+	// pointer types map to the column type of the value they point to; nil pointers are stored as null, and null
+	// columns are selected as nil pointers
+	case reflect.Ptr:
+		return mapType(field, t.Elem())
+
+	// slice types; byte slices are stored as binary data, any other slice as an array of its element type
 	case reflect.Slice:
-		switch fieldSliceKind := field.Type.Elem().Kind(); fieldSliceKind {
-		case reflect.Uint8:
+		if t.Elem().Kind() == reflect.Uint8 {
 			return "bytea", nil
-		default:
-			return "", errors.New(fmt.Sprintf("unsupported slice kind - %s", fieldSliceKind))
 		}
 
+		elemType, err := mapType(field, t.Elem())
+		if err != nil {
+			return "", errors.Wrap(err, fmt.Sprintf("unsupported slice type - %s", t))
+		}
+		return fmt.Sprintf("%s[]", elemType), nil
+
 	default:
-		return "", errors.New(fmt.Sprintf("unsupported field kind - %s", field.Type.Kind()))
+		return "", errors.New(fmt.Sprintf("unsupported field kind - %s", t.Kind()))
 	}
 }
 
+// isSoftDeleted reports whether objects of the type received as argument are soft deleted, i.e. whether the type has
+// a DeletedAt field of type *time.Time.
+func isSoftDeleted(argt reflect.Type) bool {
+	field, ok := argt.FieldByName("DeletedAt")
+	return ok && field.Type == reflect.PtrTo(timeType)
+}
+
+// isVersioned reports whether the type received as argument uses optimistic locking, i.e. whether it has a Version
+// field of type int64.
+func isVersioned(argt reflect.Type) bool {
+	field, ok := argt.FieldByName("Version")
+	return ok && field.Type.Kind() == reflect.Int64
+}
+
+// getVersionValue gets the Version field of the object received as argument.
+func getVersionValue(arg any) (int64, error) {
+	argv, err := getObjectValue(arg)
+	if err != nil {
+		return -1, err
+	}
+
+	versionField := argv.FieldByName("Version")
+	if versionField.IsValid() == false {
+		return -1, errors.New("could not get the Version field of the object")
+	}
+
+	return versionField.Int(), nil
+}
+
+// setVersionValue sets the Version field of the object received as argument.
+func setVersionValue(arg any, value int64) error {
+	argv, err := getObjectValue(arg)
+	if err != nil {
+		return err
+	}
+
+	versionField := argv.FieldByName("Version")
+	if versionField.IsValid() == false || versionField.CanSet() == false {
+		return errors.New("could not set the Version field after updating the object")
+	}
+	versionField.SetInt(value)
+
+	return nil
+}
+
 // getLengthTag returns the integer associated with the "pglen" tag of a reflect.StructField.
 func getLengthTag(field reflect.StructField) (int, error) {
 	stag := field.Tag.Get("pglen")
@@ -141,24 +456,80 @@ func getLengthTag(field reflect.StructField) (int, error) {
 	return itag, nil
 }
 
-// setIDValue sets the ID field of the object received as argument.
-func setIDValue(arg any, value int64) error {
+// isUUIDType reports whether the type received as argument is a UUID, i.e. either pgtype.UUID or a 16 byte array such
+// as github.com/google/uuid.UUID, or a named type over them.
+func isUUIDType(t reflect.Type) bool {
+	if t.Kind() == reflect.Array {
+		return t.Len() == 16 && t.Elem().Kind() == reflect.Uint8
+	}
+
+	return t.Kind() == reflect.Struct && t.ConvertibleTo(uuidType)
+}
+
+// isNumericType reports whether the type received as argument is an arbitrary precision number, i.e. either
+// pgtype.Numeric or the Decimal type of github.com/shopspring/decimal, or a named type over them.
+func isNumericType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	return t.ConvertibleTo(numericType) || (t.PkgPath() == decimalPackagePath && t.Name() == "Decimal")
+}
+
+// buildNumericType returns the numeric column type of a field, with the precision and scale of its "numeric" tag, e.g.
+// `numeric:"12,2"` for numeric(12,2). The scale defaults to 0, and fields without the tag store any precision.
+func buildNumericType(field reflect.StructField) (string, error) {
+	tag := field.Tag.Get("numeric")
+	if tag == "" {
+		return "numeric", nil
+	}
+
+	precisionTag, scaleTag, _ := strings.Cut(tag, ",")
+	if scaleTag == "" {
+		scaleTag = "0"
+	}
+
+	precision, err := strconv.Atoi(strings.TrimSpace(precisionTag))
+	if err != nil || precision <= 0 {
+		return "", errors.New(fmt.Sprintf("invalid precision in numeric tag of field %s", field.Name))
+	}
+
+	scale, err := strconv.Atoi(strings.TrimSpace(scaleTag))
+	if err != nil || scale < 0 || scale > precision {
+		return "", errors.New(fmt.Sprintf("invalid scale in numeric tag of field %s", field.Name))
+	}
+
+	return fmt.Sprintf("numeric(%d,%d)", precision, scale), nil
+}
+
+// isIntegerType reports whether the type received as argument is of an integer kind. Integer ID columns are generated
+// by the database as a bigserial, unless the ID field selects another strategy.
+func isIntegerType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// getIDValue gets the ID field of the object received as argument.
+func getIDValue(arg any) (any, error) {
 	argv, err := getObjectValue(arg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	idField := argv.FieldByName("ID")
-	if idField.IsValid() == false || idField.CanSet() == false {
-		return errors.New("could not set the ID field after inserting the object")
+	if idField.IsValid() == false {
+		return nil, errors.New("could not get the ID field of the object")
 	}
-	idField.SetInt(value)
 
-	return nil
+	return idField.Interface(), nil
 }
 
-// getIDValue gets the ID field of the object received as argument.
-func getIDValue(arg any) (int64, error) {
+// getIntegerIDValue gets the ID field of the object received as argument, which must be of an integer kind.
+func getIntegerIDValue(arg any) (int64, error) {
 	argv, err := getObjectValue(arg)
 	if err != nil {
 		return -1, err
@@ -166,48 +537,208 @@ func getIDValue(arg any) (int64, error) {
 
 	idField := argv.FieldByName("ID")
 	if idField.IsValid() == false {
-		return -1, errors.New("could not set the ID field after inserting the object")
+		return -1, errors.New("could not get the ID field of the object")
+	}
+
+	if !isIntegerType(idField.Type()) {
+		return -1, errors.New("the ID field of the object is not an integer")
 	}
 
 	return idField.Int(), nil
 }
 
-// buildTableName generates the table name from the type name. It sets all characters to lower and adds an extra "s" for
-// the plural form of the noun.
+// TableNamer is implemented by types whose table name differs from the one generated by the naming strategy.
+type TableNamer interface {
+	TableName() string
+}
+
+var tableNamerType = reflect.TypeOf((*TableNamer)(nil)).Elem()
+
+// getTableNamerName returns the table name of types implementing TableNamer, with either a value or a pointer receiver.
+func getTableNamerName(t reflect.Type) (string, bool) {
+	if t.Implements(tableNamerType) {
+		return reflect.Zero(t).Interface().(TableNamer).TableName(), true
+	}
+
+	if reflect.PtrTo(t).Implements(tableNamerType) {
+		return reflect.New(t).Interface().(TableNamer).TableName(), true
+	}
+
+	return "", false
+}
+
+// BuildTableName generates the table name from the type name with the default naming strategy, i.e. the plural form of
+// the lower case type name. Types implementing TableNamer override the generated name.
 func BuildTableName(t reflect.Type) string {
-	return fmt.Sprintf("%ss", strings.ToLower(t.Name()))
+	return DefaultNaming{}.TableName(t)
 }
 
-// buildSliceFromFields generates an slice of type []any, where each element is of the same type as the fields of
-// the first argument.
+// buildSliceFromFields generates an slice of type []any, where each element is of the same type as the column fields
+// of the first argument.
 func buildSliceFromFields(arg reflect.Type) []any {
-	slice := make([]any, arg.NumField())
-	for i := 0; i < arg.NumField(); i++ {
+	return buildSliceFromFieldList(columnFields(arg))
+}
+
+// buildSliceFromFieldList generates an slice of type []any, where each element is of the same type as the field at the
+// same position of the list received as argument.
+func buildSliceFromFieldList(fields []reflect.StructField) []any {
+	slice := make([]any, len(fields))
+	for i, field := range fields {
 		// in the line below, we are creating a new object of the type of the field; this is a pointer stored as a
 		// reflect.Value object; we then use the .Interface() method to obtain the pointer to the newly created object
-		slice[i] = reflect.New(arg.Field(i).Type).Interface()
+		slice[i] = reflect.New(field.Type).Interface()
+
+		// json columns are scanned as raw bytes, which are unmarshalled into the field by setColumnValue
+		if isJSONField(field) {
+			slice[i] = new([]byte)
+		}
+
+		// netip fields are scanned through a nullable netip.Prefix, and converted by setColumnValue
+		if isNetIPField(field) {
+			slice[i] = new(*netip.Prefix)
+		}
+
+		// durations stored as microseconds are scanned as nullable integers, and converted by setColumnValue
+		if isMicrosecondsField(field) {
+			slice[i] = new(*int64)
+		}
+
+		// named time fields are scanned through a nullable time.Time, and converted by setColumnValue
+		if isNamedTimeField(field) {
+			slice[i] = new(*time.Time)
+		}
+
+		// encrypted fields are scanned as ciphertext, which is decrypted before setColumnValue
+		if isEncryptedField(field) {
+			slice[i] = &encryptedColumn{}
+		}
 	}
 	return slice
 }
 
-// setObjectFields sets the values for each field of the object passed as first argument.
+// setObjectFields sets the values for each column field of the object passed as first argument.
 func setObjectFields(arg any, values ...any) error {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return err
+	}
+
+	return setObjectFieldList(arg, columnFields(argt), values...)
+}
+
+// setObjectFieldList sets the values for the fields received as argument of the object passed as first argument.
+func setObjectFieldList(arg any, fields []reflect.StructField, values ...any) error {
 	argv, err := getObjectValue(arg)
 	if err != nil {
 		return err
 	}
 
-	if len(values) != argv.NumField() {
+	if len(values) != len(fields) {
 		return errors.New("mismatch between number of fields and number of values")
 	}
 
-	for i := 0; i < argv.NumField(); i++ {
-		// in the line below, we are taking one any which is actually a pointer to a specific object
-		// and turning that into a reflect.Value object via reflect.ValueOf; afterwards, the .Elem() method
-		// is called to dereference the pointer and get the underlying value
-		argv.Field(i).Set(reflect.ValueOf(values[i]).Elem())
+	for i, field := range fields {
+		err = setColumnValue(argv, field, values[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getColumnValue returns the value of a column field of the object, converted to the value stored in the column.
+func getColumnValue(argv reflect.Value, field reflect.StructField) (any, error) {
+	fieldv := argv.FieldByIndex(field.Index)
+
+	if isJSONField(field) {
+		// nil maps, slices, and pointers are stored as null rather than as the json null literal
+		switch fieldv.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
+			if fieldv.IsNil() {
+				return nil, nil
+			}
+		}
+
+		value, err := json.Marshal(fieldv.Interface())
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("could not marshal field %s", field.Name))
+		}
+		return value, nil
+	}
+
+	if isNetIPField(field) {
+		return getNetIPValue(fieldv), nil
+	}
+
+	if isMicrosecondsField(field) {
+		return getMicrosecondsValue(fieldv), nil
+	}
+
+	if isEncryptedField(field) {
+		return getEncryptedValue(fieldv, field), nil
+	}
+
+	if isNamedTimeField(field) {
+		return getNamedTimeValue(fieldv), nil
+	}
+
+	if err := validateEnumValue(field, fieldv); err != nil {
+		return nil, err
+	}
+
+	// zero timestamps are set by the database
+	if isTimestampField(field) && fieldv.Interface().(time.Time).IsZero() {
+		return nil, nil
+	}
+
+	return fieldv.Interface(), nil
+}
+
+// setColumnValue sets a column field of the object from the pointer to the value scanned from the column, as created
+// by buildSliceFromFields.
+func setColumnValue(argv reflect.Value, field reflect.StructField, value any) error {
+	fieldv := argv.FieldByIndex(field.Index)
+
+	if isJSONField(field) {
+		// reset the field first, since unmarshalling into an existing map merges the keys
+		fieldv.Set(reflect.Zero(field.Type))
+
+		data := *value.(*[]byte)
+		if data == nil {
+			return nil
+		}
+
+		err := json.Unmarshal(data, fieldv.Addr().Interface())
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not unmarshal field %s", field.Name))
+		}
+		return nil
+	}
+
+	if isNetIPField(field) {
+		setNetIPValue(fieldv, field, *value.(**netip.Prefix))
+		return nil
+	}
+
+	if isMicrosecondsField(field) {
+		setMicrosecondsValue(fieldv, field, *value.(**int64))
+		return nil
+	}
+
+	if isEncryptedField(field) {
+		return setEncryptedValue(fieldv, field, value.(*encryptedColumn))
+	}
+
+	if isNamedTimeField(field) {
+		setNamedTimeValue(fieldv, field, *value.(**time.Time))
+		return nil
 	}
 
+	// in the line below, we are taking one any which is actually a pointer to a specific object
+	// and turning that into a reflect.Value object via reflect.ValueOf; afterwards, the .Elem() method
+	// is called to dereference the pointer and get the underlying value
+	fieldv.Set(reflect.ValueOf(value).Elem())
 	return nil
 }
 
@@ -0,0 +1,633 @@
+package liteorm
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Timestamp time.Time
+
+type IDs []int64
+
+type TestNamedTypesItem struct {
+	ID         int64
+	TimeColumn Timestamp
+	IDsColumn  IDs
+}
+
+func TestMapColumnTypeNamedTypes(t *testing.T) {
+	argt := reflect.TypeOf(TestNamedTypesItem{})
+
+	expected := map[string]string{
+		"TimeColumn": "timestamp",
+		"IDsColumn":  "bigint[]",
+	}
+
+	for fieldName, expectedType := range expected {
+		field, _ := argt.FieldByName(fieldName)
+		columnType, err := mapColumnType(field)
+		if err != nil {
+			t.Errorf("could not map field %s - %s", fieldName, err.Error())
+		}
+
+		if columnType != expectedType {
+			t.Errorf("incorrect column type for field %s - %s instead of %s", fieldName, columnType, expectedType)
+		}
+	}
+}
+
+func TestNamedTimeValues(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	item := &TestNamedTypesItem{TimeColumn: Timestamp(now)}
+
+	// the values follow the fields, without the ID
+	values, err := buildStatementValues(item)
+	if err != nil {
+		t.Fatalf("could not build values - %s", err.Error())
+	}
+
+	if value, ok := values[0].(time.Time); !ok || !value.Equal(now) {
+		t.Errorf("incorrect named time value - %v", values[0])
+	}
+
+	scanned := buildSliceFromFields(reflect.TypeOf(TestNamedTypesItem{}))
+	*scanned[1].(**time.Time) = &now
+
+	result := &TestNamedTypesItem{}
+	err = setObjectFields(result, scanned...)
+	if err != nil {
+		t.Fatalf("could not set fields - %s", err.Error())
+	}
+
+	if !time.Time(result.TimeColumn).Equal(now) {
+		t.Errorf("incorrect named time field - %v", time.Time(result.TimeColumn))
+	}
+
+	plan := newScanPlan(columnFields(reflect.TypeOf(TestNamedTypesItem{})))
+	if len(plan.converted) != 1 || plan.converted[0] != 1 {
+		t.Errorf("named time field not converted by the scan plan - %v", plan.converted)
+	}
+}
+
+type TestNumericItem struct {
+	ID        int64
+	Price     pgtype.Numeric  `numeric:"12,2"`
+	Quantity  *pgtype.Numeric `numeric:"8"`
+	Ratio     pgtype.Numeric
+	BadScale  pgtype.Numeric `numeric:"2,3"`
+	BadFormat pgtype.Numeric `numeric:"twelve"`
+}
+
+func TestMapColumnTypeNumeric(t *testing.T) {
+	argt := reflect.TypeOf(TestNumericItem{})
+
+	expected := map[string]string{
+		"Price":    "numeric(12,2)",
+		"Quantity": "numeric(8,0)",
+		"Ratio":    "numeric",
+	}
+
+	for fieldName, expectedType := range expected {
+		field, _ := argt.FieldByName(fieldName)
+		columnType, err := mapColumnType(field)
+		if err != nil {
+			t.Errorf("could not map field %s - %s", fieldName, err.Error())
+		}
+
+		if columnType != expectedType {
+			t.Errorf("incorrect column type for field %s - %s instead of %s", fieldName, columnType, expectedType)
+		}
+	}
+
+	for _, fieldName := range []string{"BadScale", "BadFormat"} {
+		field, _ := argt.FieldByName(fieldName)
+		if _, err := mapColumnType(field); err == nil {
+			t.Errorf("expected error on invalid numeric tag of field %s", fieldName)
+		}
+	}
+
+	if columnType := mapUDTColumnType("numeric", 0, 12, 2); columnType != "numeric(12,2)" {
+		t.Errorf("incorrect column type for udt numeric - %s", columnType)
+	}
+}
+
+type TestNetworkItem struct {
+	ID        int64
+	IP        net.IP
+	Network   *net.IPNet
+	Addr      netip.Addr
+	Prefix    *netip.Prefix
+	NoAddress *netip.Addr
+}
+
+func TestMapColumnTypeNetwork(t *testing.T) {
+	argt := reflect.TypeOf(TestNetworkItem{})
+
+	expected := map[string]string{
+		"IP":      "inet",
+		"Network": "cidr",
+		"Addr":    "inet",
+		"Prefix":  "cidr",
+	}
+
+	for fieldName, expectedType := range expected {
+		field, _ := argt.FieldByName(fieldName)
+		columnType, err := mapColumnType(field)
+		if err != nil {
+			t.Errorf("could not map field %s - %s", fieldName, err.Error())
+		}
+
+		if columnType != expectedType {
+			t.Errorf("incorrect column type for field %s - %s instead of %s", fieldName, columnType, expectedType)
+		}
+	}
+}
+
+func TestNetIPValues(t *testing.T) {
+	prefix := netip.MustParsePrefix("10.1.0.0/16")
+	item := &TestNetworkItem{Addr: netip.MustParseAddr("2001:db8::1"), Prefix: &prefix}
+
+	values, err := buildStatementValues(item)
+	if err != nil {
+		t.Fatalf("could not build values - %s", err.Error())
+	}
+
+	// the values follow the fields, without the ID
+	if values[2] != "2001:db8::1" || values[3] != "10.1.0.0/16" || values[4] != nil {
+		t.Errorf("incorrect netip values - %v", values)
+	}
+
+	scanned := buildSliceFromFields(reflect.TypeOf(TestNetworkItem{}))
+	addr := netip.MustParsePrefix("2001:db8::1/128")
+	*scanned[3].(**netip.Prefix) = &addr
+	*scanned[4].(**netip.Prefix) = &prefix
+	*scanned[5].(**netip.Prefix) = nil
+
+	result := &TestNetworkItem{}
+	err = setObjectFields(result, scanned...)
+	if err != nil {
+		t.Fatalf("could not set fields - %s", err.Error())
+	}
+
+	if result.Addr != item.Addr || result.Prefix == nil || *result.Prefix != prefix || result.NoAddress != nil {
+		t.Errorf("incorrect netip fields - %v", result)
+	}
+}
+
+type TestDurationItem struct {
+	ID            int64
+	Retention     time.Duration
+	Timeout       *time.Duration
+	Elapsed       time.Duration  `liteorm:"microseconds"`
+	MaybeElapsed  *time.Duration `liteorm:"microseconds"`
+	NotAnInterval int64          `liteorm:"microseconds"`
+}
+
+func TestMapColumnTypeDuration(t *testing.T) {
+	argt := reflect.TypeOf(TestDurationItem{})
+
+	expected := map[string]string{
+		"Retention":     "interval",
+		"Timeout":       "interval",
+		"Elapsed":       "bigint",
+		"MaybeElapsed":  "bigint",
+		"NotAnInterval": "bigint",
+	}
+
+	for fieldName, expectedType := range expected {
+		field, _ := argt.FieldByName(fieldName)
+		columnType, err := mapColumnType(field)
+		if err != nil {
+			t.Errorf("could not map field %s - %s", fieldName, err.Error())
+		}
+
+		if columnType != expectedType {
+			t.Errorf("incorrect column type for field %s - %s instead of %s", fieldName, columnType, expectedType)
+		}
+	}
+}
+
+func TestMicrosecondsValues(t *testing.T) {
+	item := &TestDurationItem{Retention: time.Hour, Elapsed: 1500 * time.Microsecond, NotAnInterval: 7}
+
+	values, err := buildStatementValues(item)
+	if err != nil {
+		t.Fatalf("could not build values - %s", err.Error())
+	}
+
+	// the values follow the fields, without the ID
+	if values[0] != time.Hour || values[2] != int64(1500) || values[3] != nil || values[4] != int64(7) {
+		t.Errorf("incorrect duration values - %v", values)
+	}
+
+	scanned := buildSliceFromFields(reflect.TypeOf(TestDurationItem{}))
+	elapsed, maybeElapsed := int64(2500), int64(10)
+	*scanned[3].(**int64) = &elapsed
+	*scanned[4].(**int64) = &maybeElapsed
+
+	result := &TestDurationItem{}
+	err = setObjectFields(result, scanned...)
+	if err != nil {
+		t.Fatalf("could not set fields - %s", err.Error())
+	}
+
+	if result.Elapsed != 2500*time.Microsecond || result.MaybeElapsed == nil ||
+		*result.MaybeElapsed != 10*time.Microsecond {
+		t.Errorf("incorrect duration fields - %v", result)
+	}
+}
+
+type TestEncryptedItem struct {
+	ID     int64
+	Email  string  `encrypt:"aes"`
+	Phone  *string `encrypt:"aes"`
+	Secret []byte  `encrypt:"aes"`
+}
+
+type testKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+func (k *testKeys) CurrentKey() (string, []byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+func (k *testKeys) Key(id string) ([]byte, error) {
+	return k.keys[id], nil
+}
+
+func TestEncryptedValues(t *testing.T) {
+	keys := &testKeys{current: "v1", keys: map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32)}}
+	s := settings{keyProvider: keys}
+	item := &TestEncryptedItem{Email: "someone@example.com", Secret: []byte("secret")}
+
+	values, err := buildStatementValues(item)
+	if err != nil {
+		t.Fatalf("could not build values - %s", err.Error())
+	}
+
+	err = s.encryptValues(values)
+	if err != nil {
+		t.Fatalf("could not encrypt values - %s", err.Error())
+	}
+
+	ciphertext, ok := values[0].([]byte)
+	if !ok || bytes.Contains(ciphertext, []byte(item.Email)) || values[1] != nil {
+		t.Errorf("incorrect encrypted values - %v", values)
+	}
+
+	// rotating the key leaves the values encrypted with the previous one readable
+	keys.current, keys.keys["v2"] = "v2", bytes.Repeat([]byte{2}, 32)
+
+	scanned := buildSliceFromFields(reflect.TypeOf(TestEncryptedItem{}))
+	for i, value := range values {
+		scanned[i+1].(*encryptedColumn).Scan(value)
+	}
+
+	err = s.decryptValues(scanned)
+	if err != nil {
+		t.Fatalf("could not decrypt values - %s", err.Error())
+	}
+
+	result := &TestEncryptedItem{}
+	err = setObjectFields(result, scanned...)
+	if err != nil {
+		t.Fatalf("could not set fields - %s", err.Error())
+	}
+
+	if result.Email != item.Email || result.Phone != nil || string(result.Secret) != "secret" {
+		t.Errorf("incorrect decrypted fields - %v", result)
+	}
+
+	if err = (settings{}).encryptValues(values[:1]); err != nil {
+		t.Errorf("could not skip values that are already encrypted - %s", err.Error())
+	}
+
+	if values, _ = buildStatementValues(item); (settings{}).encryptValues(values) == nil {
+		t.Errorf("expected error on encryption without a key provider")
+	}
+}
+
+func TestMapColumnTypePointers(t *testing.T) {
+	expected := map[string]string{
+		"StringColumn":  "varchar(25)",
+		"Int64Column":   "bigint",
+		"TimeColumn":    "timestamp",
+		"Float64Column": "float8",
+	}
+
+	for fieldName, expectedType := range expected {
+		field, _ := TestNullableItemType.FieldByName(fieldName)
+		columnType, err := mapColumnType(field)
+		if err != nil {
+			t.Errorf("could not map field %s - %s", fieldName, err.Error())
+		}
+
+		if columnType != expectedType {
+			t.Errorf("incorrect column type for field %s - %s instead of %s", fieldName, columnType, expectedType)
+		}
+	}
+}
+
+// Point is a custom type stored as text, e.g. "(1,2)".
+type Point struct {
+	X, Y int
+}
+
+func (p *Point) Scan(src any) error {
+	_, err := fmt.Sscanf(src.(string), "(%d,%d)", &p.X, &p.Y)
+	return err
+}
+
+func (p Point) Value() (driver.Value, error) {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y), nil
+}
+
+type TestNullTypesItem struct {
+	ID            int64
+	StringColumn  sql.NullString `pglen:"25"`
+	Int64Column   sql.NullInt64
+	Int32Column   sql.NullInt32
+	BoolColumn    sql.NullBool
+	TimeColumn    sql.NullTime
+	Float64Column sql.NullFloat64
+	PointColumn   Point `liteorm:"type:text"`
+}
+
+var TestNullTypesItemType reflect.Type = reflect.TypeOf((*TestNullTypesItem)(nil)).Elem()
+
+func TestMapColumnTypeNullTypes(t *testing.T) {
+	expected := map[string]string{
+		"StringColumn":  "varchar(25)",
+		"Int64Column":   "bigint",
+		"Int32Column":   "int",
+		"BoolColumn":    "boolean",
+		"TimeColumn":    "timestamp",
+		"Float64Column": "float8",
+		"PointColumn":   "text",
+	}
+
+	for fieldName, expectedType := range expected {
+		field, _ := TestNullTypesItemType.FieldByName(fieldName)
+		columnType, err := mapColumnType(field)
+		if err != nil {
+			t.Errorf("could not map field %s - %s", fieldName, err.Error())
+		}
+
+		if columnType != expectedType {
+			t.Errorf("incorrect column type for field %s - %s instead of %s", fieldName, columnType, expectedType)
+		}
+	}
+
+	field, _ := reflect.TypeOf(struct{ PointColumn Point }{}).FieldByName("PointColumn")
+	if _, err := mapColumnType(field); err == nil {
+		t.Errorf("expected error when mapping a custom type without a type tag")
+	}
+}
+
+type TestMetadata struct {
+	Source string   `json:"source"`
+	Tags   []string `json:"tags"`
+}
+
+type TestJSONItem struct {
+	ID             int64
+	MapColumn      map[string]any
+	MetadataColumn TestMetadata `liteorm:"jsonb"`
+}
+
+var TestJSONItemType reflect.Type = reflect.TypeOf((*TestJSONItem)(nil)).Elem()
+
+func TestMapColumnTypeJSON(t *testing.T) {
+	for _, fieldName := range []string{"MapColumn", "MetadataColumn"} {
+		field, _ := TestJSONItemType.FieldByName(fieldName)
+		columnType, err := mapColumnType(field)
+		if err != nil {
+			t.Errorf("could not map field %s - %s", fieldName, err.Error())
+		}
+
+		if columnType != "jsonb" {
+			t.Errorf("incorrect column type for field %s - %s instead of jsonb", fieldName, columnType)
+		}
+	}
+}
+
+type TestCategory struct {
+	ID   int64  `pgsql:"primary key"`
+	Name string `pglen:"25"`
+}
+
+var TestCategoryType reflect.Type = reflect.TypeOf((*TestCategory)(nil)).Elem()
+
+func (TestCategory) TableName() string {
+	return "testcategories"
+}
+
+type TestPerson struct {
+	ID int64
+}
+
+func (*TestPerson) TableName() string {
+	return "testpeople"
+}
+
+func TestBuildTableName(t *testing.T) {
+	expected := map[reflect.Type]string{
+		reflect.TypeOf(TestNullableItem{}): "testnullableitems",
+		TestCategoryType:                   "testcategories",
+		reflect.TypeOf(TestPerson{}):       "testpeople",
+	}
+
+	for argt, expectedName := range expected {
+		if tableName := BuildTableName(argt); tableName != expectedName {
+			t.Errorf("incorrect table name for type %s - %s instead of %s", argt.Name(), tableName, expectedName)
+		}
+	}
+}
+
+type TestSnowflakeItem struct {
+	ID   int64  `liteorm:"id:snowflake"`
+	Name string `pglen:"100"`
+}
+
+var TestSnowflakeItemType reflect.Type = reflect.TypeOf((*TestSnowflakeItem)(nil)).Elem()
+
+type TestKSUIDItem struct {
+	ID   string `liteorm:"id:ksuid"`
+	Name string `pglen:"100"`
+}
+
+var TestKSUIDItemType reflect.Type = reflect.TypeOf((*TestKSUIDItem)(nil)).Elem()
+
+type TestIdentityItem struct {
+	ID   int64  `liteorm:"id:identity"`
+	Name string `pglen:"100"`
+}
+
+var TestIdentityItemType reflect.Type = reflect.TypeOf((*TestIdentityItem)(nil)).Elem()
+
+func TestIDStrategies(t *testing.T) {
+	expected := map[reflect.Type]string{
+		TestItemType:          "bigserial",
+		TestIdentityItemType:  "bigint generated always as identity",
+		TestSnowflakeItemType: "bigint",
+		TestKSUIDItemType:     "char(27)",
+	}
+
+	for argt, expectedType := range expected {
+		field, _ := argt.FieldByName("ID")
+		if columnType, err := buildColumnType(field); err != nil || columnType != expectedType {
+			t.Errorf("incorrect ID column type for type %s - %s instead of %s", argt.Name(), columnType, expectedType)
+		}
+	}
+
+	field := reflect.StructField{Name: "ID", Type: reflect.TypeOf(""), Tag: `liteorm:"id:snowflake"`}
+	if _, err := buildColumnType(field); err == nil {
+		t.Errorf("snowflake strategy accepted for a string ID")
+	}
+
+	field = reflect.StructField{Name: "ID", Type: reflect.TypeOf(int64(0)), Tag: `liteorm:"id:unknown"`}
+	if _, err := buildColumnType(field); err == nil {
+		t.Errorf("unknown ID strategy accepted")
+	}
+
+	object := &TestSnowflakeItem{}
+	if err := setGeneratedID(object); err != nil || object.ID <= 0 {
+		t.Errorf("snowflake ID not generated - %v", object)
+	}
+
+	values, err := buildStatementValues(object)
+	if err != nil || len(values) != 2 || values[0] != object.ID {
+		t.Errorf("generated ID not inserted - %v", values)
+	}
+
+	ksuidObject := &TestKSUIDItem{}
+	if err := setGeneratedID(ksuidObject); err != nil || len(ksuidObject.ID) != 27 {
+		t.Errorf("KSUID not generated - %v", ksuidObject)
+	}
+
+	serialObject := &TestItem{}
+	if err := setGeneratedID(serialObject); err != nil || serialObject.ID != 0 {
+		t.Errorf("serial ID generated by the client - %v", serialObject)
+	}
+
+	generator := NewSnowflakeGenerator(1)
+	previous := int64(0)
+	for i := 0; i < 10000; i++ {
+		id, _ := generator.NextID()
+		if id.(int64) <= previous {
+			t.Fatalf("snowflake IDs not increasing - %d after %d", id, previous)
+		}
+		previous = id.(int64)
+	}
+
+	RegisterIDGenerator("constant", IDGeneratorFunc(func() (any, error) {
+		return 42, nil
+	}))
+	field = reflect.StructField{Name: "ID", Type: reflect.TypeOf(int64(0)), Tag: `liteorm:"id:constant"`}
+	if columnType, err := buildColumnType(field); err != nil || columnType != "bigint" || !isClientGeneratedID(field) {
+		t.Errorf("incorrect column type of a registered ID strategy - %s", columnType)
+	}
+
+	// integer IDs of string fields are formatted rather than converted to a rune
+	stringObject := &struct {
+		ID string `liteorm:"id:constant"`
+	}{}
+	if err := setGeneratedID(stringObject); err != nil || stringObject.ID != "42" {
+		t.Errorf("incorrect generated string ID - %q", stringObject.ID)
+	}
+
+	for argt := range expected {
+		if _, err := buildCreateStatement(argt, DefaultNaming{}, PostgreSQL{}); err != nil {
+			t.Errorf("could not build create statement of type %s - %s", argt.Name(), err.Error())
+		}
+	}
+}
+
+func TestModelCache(t *testing.T) {
+	fields := columnFields(TestItemType)
+	fields[0].Name = "Changed"
+	if columnFields(TestItemType)[0].Name != "ID" {
+		t.Errorf("cached column fields modified by a caller")
+	}
+
+	s := settings{}
+	expected := buildSelectStatement(TestSoftDeleteItemType, "where id = $1", false, 0, DefaultNaming{})
+	for i := 0; i < 2; i++ {
+		if statement := s.cachedSelectStatement(TestSoftDeleteItemType, "where id = $1", 0); statement != expected {
+			t.Errorf("incorrect cached select statement - %s", statement)
+		}
+	}
+
+	s.unscoped = true
+	expected = buildSelectStatement(TestSoftDeleteItemType, "", true, 0, DefaultNaming{})
+	if statement := s.cachedSelectStatement(TestSoftDeleteItemType, "", 0); statement != expected {
+		t.Errorf("incorrect cached unscoped select statement - %s", statement)
+	}
+
+	if statement := s.cachedInsertStatement(TestItemType); statement != buildInsertStatement(TestItemType,
+		DefaultNaming{}, PostgreSQL{}) {
+		t.Errorf("incorrect cached insert statement - %s", statement)
+	}
+
+	// the placeholder of the tenant is not part of the cached prefix
+	expected = buildSelectStatement(TestTenantItemType, "where id = $1", false, 2, DefaultNaming{})
+	if statement := (settings{}).cachedSelectStatement(TestTenantItemType, "where id = $1", 2); statement != expected {
+		t.Errorf("incorrect scoped select statement - %s", statement)
+	}
+
+	key, ok := namingKey(settings{schema: "app"}.namingStrategy())
+	if !ok || key != (schemaNamingKey{naming: defaultNamingKey{}, schema: "app"}) {
+		t.Errorf("incorrect key of schema naming strategy - %v", key)
+	}
+
+	if _, ok := namingKey(DefaultNaming{ColumnNaming: LowerCase}); !ok {
+		t.Errorf("naming strategy with lower case column naming is not cacheable")
+	}
+
+	if _, ok := namingKey(mapNaming{}); ok {
+		t.Errorf("naming strategy holding a map is cacheable")
+	}
+}
+
+type mapNaming struct {
+	DefaultNaming
+	tables map[reflect.Type]string
+}
+
+func TestScanPlan(t *testing.T) {
+	plan := columnScanPlan(reflect.TypeOf(TestNetworkItem{}))
+	if len(plan.converted) != 3 || plan.converted[0] != 3 {
+		t.Errorf("incorrect converted fields - %v", plan.converted)
+	}
+
+	result := &TestNetworkItem{}
+	values := make([]any, len(plan.fields))
+	plan.destinations(reflect.ValueOf(result).Elem(), values)
+	if values[0] != &result.ID || values[1] != &result.IP {
+		t.Errorf("fields stored as is not scanned directly")
+	}
+
+	*values[0].(*int64) = 42
+	addr, prefix := netip.MustParsePrefix("2001:db8::1/128"), netip.MustParsePrefix("10.1.0.0/16")
+	*values[3].(**netip.Prefix) = &addr
+	*values[4].(**netip.Prefix) = &prefix
+	*values[5].(**netip.Prefix) = nil
+	err := plan.setConverted(settings{}, reflect.ValueOf(result).Elem(), values)
+	if err != nil {
+		t.Fatalf("could not set converted fields - %s", err.Error())
+	}
+
+	if result.ID != 42 || result.Addr.String() != "2001:db8::1" || result.Prefix == nil || result.NoAddress != nil {
+		t.Errorf("incorrect fields - %v", result)
+	}
+}
@@ -0,0 +1,93 @@
+package liteorm
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"sort"
+	"sync"
+)
+
+// Registry holds the databases of a service that talks to several of them by name, e.g. "primary" and "analytics".
+// The databases opened by the registry share its options, and are all closed by CloseAll. A registry is safe for
+// concurrent use.
+type Registry struct {
+	mutex     sync.RWMutex
+	options   []Option
+	databases map[string]*Database
+}
+
+// NewRegistry returns an empty registry whose databases are configured with the options received as argument.
+func NewRegistry(options ...Option) *Registry {
+	return &Registry{options: options, databases: make(map[string]*Database)}
+}
+
+// Open connects to the database of the connection string received as argument, configured with the options of the
+// registry followed by those received as argument, and registers it under the name. It fails if the name is already
+// registered.
+func (r *Registry) Open(name string, connString string, options ...Option) (*Database, error) {
+	errmsg := fmt.Sprintf("could not open database %s", name)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.databases[name]; ok {
+		return nil, errors.New(fmt.Sprintf("%s - name already registered", errmsg))
+	}
+
+	allOptions := append(append([]Option{}, r.options...), options...)
+	db, err := NewDatabase(connString, allOptions...)
+	if err != nil {
+		return nil, errors.Wrap(err, errmsg)
+	}
+
+	r.databases[name] = db
+	return db, nil
+}
+
+// Register registers a database created elsewhere under the name received as argument, e.g. one created with
+// NewDatabaseFromExecutor, as is. It fails if the name is already registered.
+func (r *Registry) Register(name string, db *Database) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.databases[name]; ok {
+		return errors.New(fmt.Sprintf("could not register database %s - name already registered", name))
+	}
+
+	r.databases[name] = db
+	return nil
+}
+
+// Get returns the database registered under the name received as argument, and false if there is none.
+func (r *Registry) Get(name string) (*Database, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	db, ok := r.databases[name]
+	return db, ok
+}
+
+// Names returns the names of the registered databases, in alphabetical order.
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0, len(r.databases))
+	for name := range r.databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// CloseAll closes the registered databases and removes them from the registry, which can then be reused.
+func (r *Registry) CloseAll() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, db := range r.databases {
+		db.Close()
+	}
+	r.databases = make(map[string]*Database)
+}
@@ -0,0 +1,251 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/lashbits/liteorm/internal/columns"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Relations are declared with the "liteorm" tag on fields holding the related objects, and are loaded by Preload with
+// one additional query per relation:
+//
+//	type User struct {
+//		ID     int64
+//		Orders []Order `liteorm:"hasmany,fk:userid"`
+//	}
+//
+//	type Order struct {
+//		ID     int64
+//		UserID int64
+//		User   *User `liteorm:"belongsto,fk:userid"`
+//	}
+//
+// For has-many relations, fk is the column of the related table that holds the id of the object. For belongs-to
+// relations, fk is the column of the object's own table that holds the id of the related object. Many-to-many
+// relations are declared with the "m2m" tag instead, see joinTable. Relation fields do not map to columns.
+const (
+	hasMany    = "hasmany"
+	belongsTo  = "belongsto"
+	manyToMany = "m2m"
+)
+
+// getRelationKind returns the kind of relation declared by the tag of a struct field, if any.
+func getRelationKind(field reflect.StructField) (string, bool) {
+	if _, ok := field.Tag.Lookup("m2m"); ok {
+		return manyToMany, true
+	}
+
+	options := parseTag(field)
+	for _, kind := range []string{hasMany, belongsTo} {
+		if _, ok := options[kind]; ok {
+			return kind, true
+		}
+	}
+
+	return "", false
+}
+
+// findColumnField returns the column field of the type received as argument that maps to the column name.
+func findColumnField(argt reflect.Type, name string, naming NamingStrategy) (reflect.StructField, bool) {
+	for _, field := range columnFields(argt) {
+		if columnName(field, naming) == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// init shares findColumnField with liteormtest, which maps columns to fields like liteorm does.
+func init() {
+	columns.FindField = func(t reflect.Type, column string, naming any) (reflect.StructField, bool) {
+		return findColumnField(t, column, naming.(NamingStrategy))
+	}
+}
+
+// Preload loads the named relations of the object received as argument, which is either a pointer to a struct or a
+// slice of structs. For slices, each relation is loaded for all the elements with a single query.
+func (db *Database) Preload(arg any, relations ...string) error {
+	return db.PreloadCtx(context.Background(), arg, relations...)
+}
+
+func (db *Database) PreloadCtx(ctx context.Context, arg any, relations ...string) error {
+	return preload(ctx, db.session(), arg, relations...)
+}
+
+func preload(ctx context.Context, s *session, arg any, relations ...string) error {
+	var objects []any
+	if reflect.Indirect(reflect.ValueOf(arg)).Kind() == reflect.Slice {
+		var err error
+		objects, err = getSliceObjects(arg)
+		if err != nil {
+			return errors.Wrap(err, "could not preload relations")
+		}
+	} else {
+		objects = []any{arg}
+	}
+
+	if len(objects) == 0 {
+		return nil
+	}
+
+	argt, err := getObjectType(objects[0])
+	if err != nil {
+		return errors.Wrap(err, "could not preload relations")
+	}
+
+	for _, relation := range relations {
+		errmsg := fmt.Sprintf("could not preload relation %s of type %s", relation, argt.Name())
+
+		field, ok := argt.FieldByName(relation)
+		if !ok {
+			return errors.New(fmt.Sprintf("%s - no such field", errmsg))
+		}
+
+		kind, ok := getRelationKind(field)
+		if !ok {
+			return errors.New(fmt.Sprintf("%s - field is not a relation", errmsg))
+		}
+
+		fk := parseTag(field)["fk"]
+		if fk == "" && kind != manyToMany {
+			return errors.New(fmt.Sprintf("%s - fk not present in the tag", errmsg))
+		}
+
+		switch kind {
+		case hasMany:
+			err = preloadHasMany(ctx, s, objects, field, fk)
+		case belongsTo:
+			err = preloadBelongsTo(ctx, s, objects, argt, field, fk)
+		case manyToMany:
+			err = preloadManyToMany(ctx, s, objects, argt, field)
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	return nil
+}
+
+// preloadHasMany sets the slice field of each object to the related objects whose fk column holds the id of the object.
+func preloadHasMany(ctx context.Context, s *session, objects []any, field reflect.StructField, fk string) error {
+	if field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() != reflect.Struct {
+		return errors.New("has-many relation field is not a slice of structs")
+	}
+
+	relatedType := field.Type.Elem()
+	fkField, ok := findColumnField(relatedType, fk, s.namingStrategy())
+	if !ok {
+		return errors.New(fmt.Sprintf("type %s does not have an fk column %s", relatedType.Name(), fk))
+	}
+
+	ids := make([]int64, len(objects))
+	for i, object := range objects {
+		id, err := getIntegerIDValue(object)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	clauses := fmt.Sprintf("where %s = any($1)", quoteIdentifier(fk))
+	relatedif, err := selectAll(ctx, s, relatedType, clauses, ids)
+	if err != nil {
+		return err
+	}
+
+	// group the related objects by the id held in their fk column
+	related := reflect.ValueOf(relatedif)
+	groups := make(map[int64]reflect.Value)
+	for i := 0; i < related.Len(); i++ {
+		elem := related.Index(i)
+		key := elem.FieldByIndex(fkField.Index).Int()
+		group, ok := groups[key]
+		if !ok {
+			group = reflect.MakeSlice(field.Type, 0, 0)
+		}
+		groups[key] = reflect.Append(group, elem)
+	}
+
+	for i, object := range objects {
+		group, ok := groups[ids[i]]
+		if !ok {
+			group = reflect.MakeSlice(field.Type, 0, 0)
+		}
+
+		argv, err := getObjectValue(object)
+		if err != nil {
+			return err
+		}
+		argv.FieldByIndex(field.Index).Set(group)
+	}
+
+	return nil
+}
+
+// preloadBelongsTo sets the struct (or pointer to struct) field of each object to the related object whose id is held
+// in the fk column of the object. Objects whose related object does not exist are left untouched.
+func preloadBelongsTo(ctx context.Context, s *session, objects []any, argt reflect.Type, field reflect.StructField,
+	fk string) error {
+	relatedType := field.Type
+	if relatedType.Kind() == reflect.Ptr {
+		relatedType = relatedType.Elem()
+	}
+
+	if relatedType.Kind() != reflect.Struct {
+		return errors.New("belongs-to relation field is not a struct or pointer to struct")
+	}
+
+	fkField, ok := findColumnField(argt, fk, s.namingStrategy())
+	if !ok {
+		return errors.New(fmt.Sprintf("type %s does not have an fk column %s", argt.Name(), fk))
+	}
+
+	fks := make([]int64, len(objects))
+	for i, object := range objects {
+		argv, err := getObjectValue(object)
+		if err != nil {
+			return err
+		}
+		fks[i] = argv.FieldByIndex(fkField.Index).Int()
+	}
+
+	relatedif, err := selectAll(ctx, s, relatedType, buildIDsClause(relatedType, s.namingStrategy()), fks)
+	if err != nil {
+		return err
+	}
+
+	related := reflect.ValueOf(relatedif)
+	byID := make(map[int64]reflect.Value)
+	for i := 0; i < related.Len(); i++ {
+		id, err := getIntegerIDValue(related.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		byID[id] = related.Index(i)
+	}
+
+	for i, object := range objects {
+		elem, ok := byID[fks[i]]
+		if !ok {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Ptr {
+			ptr := reflect.New(relatedType)
+			ptr.Elem().Set(elem)
+			elem = ptr
+		}
+
+		argv, err := getObjectValue(object)
+		if err != nil {
+			return err
+		}
+		argv.FieldByIndex(field.Index).Set(elem)
+	}
+
+	return nil
+}
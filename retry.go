@@ -0,0 +1,112 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures the retries of operations that fail with a transient error, e.g. a serialization failure or a
+// reset connection. Reads are retried, i.e. selects and counts, and idempotent writes if RetryWrites is set, i.e.
+// upserts, updates and deletes, as well as raw queries, which may modify rows. Operations within a transaction are never
+// retried, since a failed statement aborts the whole transaction.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of an operation, including the first one
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the second attempt, which is doubled before each further attempt
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between attempts, unlimited if zero
+	MaxBackoff time.Duration
+
+	// RetryWrites enables the retries of idempotent writes
+	RetryWrites bool
+
+	// Retryable reports whether an error is retried, IsTransientError if not set
+	Retryable func(err error) bool
+}
+
+// WithRetry returns a copy of the database whose operations are retried according to the policy received as argument.
+func (db *Database) WithRetry(policy RetryPolicy) *Database {
+	retried := *db
+	retried.retryPolicy = &policy
+	return &retried
+}
+
+// IsTransientError reports whether an error is likely to go away when the operation is retried, i.e. serialization
+// failures, deadlocks, connection exceptions, network errors and lost connections, see ErrConnClosed.
+func IsTransientError(err error) bool {
+	if errors.Is(err, ErrConnClosed) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01" || strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	var netErr net.Error
+	return pgconn.SafeToRetry(err) || errors.As(err, &netErr)
+}
+
+// backoff returns the wait before the attempt received as argument, starting from 1 for the second attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff == 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+
+	if p.MaxBackoff != 0 && backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return backoff
+}
+
+// retryable reports whether an operation failing with the error received as argument is retried.
+func (p *RetryPolicy) retryable(err error, write bool) bool {
+	if write && !p.RetryWrites {
+		return false
+	}
+
+	if p.Retryable == nil {
+		return IsTransientError(err)
+	}
+
+	return p.Retryable(err)
+}
+
+// retry runs an operation of the database and retries it according to the retry policy of the database, if any.
+func (db *Database) retry(ctx context.Context, write bool, operation func() error) error {
+	_, err := retryResult(ctx, db, write, func() (struct{}, error) {
+		return struct{}{}, operation()
+	})
+	return err
+}
+
+// retryResult runs an operation of the database returning a result, and retries it like Database.retry.
+func retryResult[T any](ctx context.Context, db *Database, write bool, operation func() (T, error)) (T, error) {
+	result, err := operation()
+	policy := db.retryPolicy
+	for attempt := 1; err != nil && policy != nil && attempt < policy.MaxAttempts; attempt++ {
+		if ctx.Err() != nil || !policy.retryable(err, write) {
+			break
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+
+		result, err = operation()
+	}
+
+	return result, err
+}
@@ -0,0 +1,96 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestIsTransientError(t *testing.T) {
+	serializationFailure := errors.Wrap(&pgconn.PgError{Code: "40001"}, "could not select objects")
+	if !IsTransientError(serializationFailure) {
+		t.Errorf("serialization failure is not transient")
+	}
+
+	uniqueViolation := errors.Wrap(&pgconn.PgError{Code: "23505"}, "could not insert object")
+	if IsTransientError(uniqueViolation) {
+		t.Errorf("unique violation is transient")
+	}
+
+	connClosed := errors.Wrap(connClosedError{err: errors.New("connection refused")}, "could not insert object")
+	if !IsTransientError(connClosed) || !errors.Is(connClosed, ErrConnClosed) {
+		t.Errorf("lost connection is not transient")
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond}
+	for i, backoff := range expected {
+		if actual := policy.backoff(i + 1); actual != backoff {
+			t.Errorf("incorrect backoff before attempt %d - %s instead of %s", i+2, actual, backoff)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	db := (&Database{}).WithRetry(RetryPolicy{MaxAttempts: 3})
+	transient := &pgconn.PgError{Code: "40P01"}
+
+	attempts := 0
+	err := db.retry(context.Background(), false, func() error {
+		attempts++
+		return transient
+	})
+	if err != transient || attempts != 3 {
+		t.Errorf("read was attempted %d times instead of 3", attempts)
+	}
+
+	attempts = 0
+	err = db.retry(context.Background(), true, func() error {
+		attempts++
+		return transient
+	})
+	if err != transient || attempts != 1 {
+		t.Errorf("write was attempted %d times instead of 1", attempts)
+	}
+
+	result, err := retryResult(context.Background(), db, false, func() (int, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, transient
+		}
+		return attempts, nil
+	})
+	if err != nil || result != 3 {
+		t.Errorf("read did not succeed on its last attempt")
+	}
+
+	// raw queries may modify rows, so they are retried like writes
+	counting := &countingExecutor{nilRowsExecutor: nilRowsExecutor{err: transient}}
+	db = NewDatabaseFromExecutor(counting).WithRetry(RetryPolicy{MaxAttempts: 3})
+	if _, err := db.QueryMaps("delete from testitems returning *;"); err == nil || counting.queries != 1 {
+		t.Errorf("raw query was attempted %d times instead of 1", counting.queries)
+	}
+
+	counting.queries = 0
+	db = NewDatabaseFromExecutor(counting).WithRetry(RetryPolicy{MaxAttempts: 3, RetryWrites: true})
+	var items []TestItem
+	if err := db.Query(&items, "select * from testitems;"); err == nil || counting.queries != 3 {
+		t.Errorf("raw query was attempted %d times instead of 3", counting.queries)
+	}
+}
+
+// countingExecutor fails every statement like nilRowsExecutor, counting its queries.
+type countingExecutor struct {
+	nilRowsExecutor
+	queries int
+}
+
+func (e *countingExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	e.queries++
+	return e.nilRowsExecutor.Query(ctx, sql, args...)
+}
@@ -0,0 +1,77 @@
+package liteorm
+
+import (
+	"reflect"
+	"sync"
+)
+
+// scanPlan scans the columns of the rows of a select into the fields of new objects. The columns of fields stored as
+// is are scanned directly into the fields, while those converted when they are scanned, i.e. json, netip, microseconds,
+// named time and encrypted fields, are scanned into intermediate values like buildSliceFromFieldList and set
+// afterwards. This avoids allocating and copying a value per column and row for most columns.
+type scanPlan struct {
+	fields []reflect.StructField
+
+	// converted lists the positions of the fields that are not scanned directly
+	converted []int
+}
+
+// scanPlans caches the scan plans of the selects of all the column fields of a type, see columnScanPlan.
+var scanPlans sync.Map
+
+// columnScanPlan returns the scan plan of the column fields of the type received as argument.
+func columnScanPlan(t reflect.Type) *scanPlan {
+	plan, ok := scanPlans.Load(t)
+	if !ok {
+		plan, _ = scanPlans.LoadOrStore(t, newScanPlan(columnFields(t)))
+	}
+
+	return plan.(*scanPlan)
+}
+
+// newScanPlan returns the scan plan of the fields received as argument.
+func newScanPlan(fields []reflect.StructField) *scanPlan {
+	plan := &scanPlan{fields: fields}
+	for i, field := range fields {
+		if isJSONField(field) || isNetIPField(field) || isMicrosecondsField(field) || isNamedTimeField(field) ||
+			isEncryptedField(field) {
+			plan.converted = append(plan.converted, i)
+		}
+	}
+
+	return plan
+}
+
+// destinations sets the destinations of the columns of a row received as argument, whose length is that of the
+// fields, to the addresses of the fields of the new object argv, or to intermediate values for the converted fields.
+func (p *scanPlan) destinations(argv reflect.Value, values []any) {
+	for i, field := range p.fields {
+		values[i] = argv.FieldByIndex(field.Index).Addr().Interface()
+	}
+
+	for _, i := range p.converted {
+		values[i] = buildSliceFromFieldList(p.fields[i : i+1])[0]
+	}
+}
+
+// setConverted sets the converted fields of the object argv from the intermediate values they were scanned into,
+// after decrypting the encrypted ones.
+func (p *scanPlan) setConverted(s settings, argv reflect.Value, values []any) error {
+	if len(p.converted) == 0 {
+		return nil
+	}
+
+	err := s.decryptValues(values)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range p.converted {
+		err = setColumnValue(argv, p.fields[i], values[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
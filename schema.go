@@ -0,0 +1,210 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+// udtColumnTypes maps the udt names reported by information_schema.columns to the column types generated by
+// mapColumnType. Array types are reported with the udt name of their element prefixed with an underscore.
+var udtColumnTypes = map[string]string{
+	"bool":      "boolean",
+	"int2":      "smallint",
+	"int4":      "int",
+	"int8":      "bigint",
+	"float4":    "float4",
+	"float8":    "float8",
+	"varchar":   "varchar",
+	"timestamp": "timestamp",
+	"uuid":      "uuid",
+	"bytea":     "bytea",
+	"jsonb":     "jsonb",
+	"numeric":   "numeric",
+}
+
+// mapUDTColumnType maps a udt name, character maximum length, and numeric precision and scale, as reported by
+// information_schema.columns, to the column type generated by mapColumnType.
+func mapUDTColumnType(udtName string, maxLength int, precision int, scale int) string {
+	if strings.HasPrefix(udtName, "_") {
+		return mapUDTColumnType(udtName[1:], maxLength, precision, scale) + "[]"
+	}
+
+	columnType, ok := udtColumnTypes[udtName]
+	if !ok {
+		return udtName
+	}
+
+	if columnType == "varchar" && maxLength > 0 {
+		return fmt.Sprintf("varchar(%d)", maxLength)
+	}
+
+	if columnType == "numeric" && precision > 0 {
+		return fmt.Sprintf("numeric(%d,%d)", precision, scale)
+	}
+
+	return columnType
+}
+
+// AutoMigrate brings the table of the type received as argument in line with the fields of the type. The table is
+// created if it does not exist, columns are added for new fields, and columns whose type differs from the one of the
+// field are converted. Columns without a matching field are left untouched. Views are replaced with their defining
+// query, and materialized views are created if they do not exist.
+func (db *Database) AutoMigrate(t reflect.Type) error {
+	return db.AutoMigrateCtx(context.Background(), t)
+}
+
+func (db *Database) AutoMigrateCtx(ctx context.Context, t reflect.Type) error {
+	errmsg := fmt.Sprintf("could not migrate table of type %s", t.Name())
+
+	// the columns of views follow from their defining query
+	if checkWritable(t) == ErrReadOnly {
+		return db.CreateTableCtx(ctx, t, false)
+	}
+
+	liveColumns, err := db.liveColumns(ctx, t)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	if len(liveColumns) == 0 {
+		return db.CreateTableCtx(ctx, t, false)
+	}
+
+	// the columns added for new enum fields need their types
+	err = db.createEnums(ctx, t)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	for _, field := range columnFields(t) {
+		columnName := columnName(field, db.namingStrategy())
+
+		var statement string
+		liveType, exists := liveColumns[columnName]
+		if !exists {
+			statement, err = buildAddColumnStatement(t, field, db.namingStrategy(), db.sqlDialect())
+		} else if field.Name != "ID" {
+			var expectedType string
+			expectedType, err = mapColumnType(field)
+			if err == nil && expectedType != liveType {
+				statement, err = buildAlterColumnTypeStatement(t, field, db.namingStrategy())
+			}
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		if statement == "" {
+			continue
+		}
+
+		_, err = db.session().Exec(ctx, statement)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+	}
+
+	return nil
+}
+
+// liveColumns returns the columns of the table of the type received as argument, mapped to their column types. The
+// map is empty if the table does not exist.
+func (db *Database) liveColumns(ctx context.Context, t reflect.Type) (map[string]string, error) {
+	tableName := db.unqualifiedNamingStrategy().TableName(t)
+	inspectedColumns, err := inspectColumns(ctx, db.session(), db.schemaName(), tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]string)
+	for _, column := range inspectedColumns {
+		columns[column.Name] = column.Type
+	}
+
+	return columns, nil
+}
+
+// ValidationError is returned by ValidateModels and lists all mismatches between the types and the live schema.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("models do not match the database schema - %s", strings.Join(e.Problems, "; "))
+}
+
+// ValidateModels checks that the tables of the types received as argument match their fields, i.e. that the tables
+// exist, that every field has a column of the type generated by liteorm, and that the columns without a field can be
+// omitted on insert. All mismatches are returned in a ValidationError, so that a deploy can fail fast rather than on
+// the first query that hits them.
+func (db *Database) ValidateModels(types ...reflect.Type) error {
+	return db.ValidateModelsCtx(context.Background(), types...)
+}
+
+func (db *Database) ValidateModelsCtx(ctx context.Context, types ...reflect.Type) error {
+	problems := make([]string, 0)
+	for _, t := range types {
+		tableName := db.unqualifiedNamingStrategy().TableName(t)
+		columns, err := inspectColumns(ctx, db.session(), db.schemaName(), tableName)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not validate type %s", t.Name()))
+		}
+
+		problems = append(problems, validateModel(t, tableName, columns, db.namingStrategy())...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// validateModel returns the mismatches between the fields of the type and the live columns of its table.
+func validateModel(t reflect.Type, tableName string, columns []ColumnInfo, naming NamingStrategy) []string {
+	if len(columns) == 0 {
+		return []string{fmt.Sprintf("table %s of type %s does not exist", tableName, t.Name())}
+	}
+
+	liveColumns := make(map[string]ColumnInfo)
+	for _, column := range columns {
+		liveColumns[column.Name] = column
+	}
+
+	problems := make([]string, 0)
+	for _, field := range columnFields(t) {
+		columnName := columnName(field, naming)
+		column, ok := liveColumns[columnName]
+		delete(liveColumns, columnName)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("table %s does not have column %s of field %s.%s", tableName,
+				columnName, t.Name(), field.Name))
+			continue
+		}
+
+		// like AutoMigrate, the type of the ID column is not checked, since serial columns are reported as integers
+		if field.Name == "ID" {
+			continue
+		}
+
+		expectedType, err := mapColumnType(field)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if expectedType != column.Type {
+			problems = append(problems, fmt.Sprintf("column %s.%s is of type %s instead of %s", tableName, columnName,
+				column.Type, expectedType))
+		}
+	}
+
+	for _, column := range columns {
+		if _, unmapped := liveColumns[column.Name]; unmapped && !column.Nullable && column.Default == "" {
+			problems = append(problems, fmt.Sprintf("column %s.%s is not nullable and has neither default nor field",
+				tableName, column.Name))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,33 @@
+package liteorm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateModel(t *testing.T) {
+	columns := []ColumnInfo{
+		{Name: "id", Type: "bigint"},
+		{Name: "string_column", Type: "varchar(25)", Nullable: true},
+		{Name: "int_column", Type: "bigint", Nullable: true},
+		{Name: "time_column", Type: "timestamp", Nullable: true},
+		{Name: "blob_column", Type: "bytea", Nullable: true},
+		{Name: "float32_column", Type: "float4", Nullable: true},
+		{Name: "legacy_column", Type: "int"},
+	}
+
+	problems := validateModel(TestItemType, "testitems", columns, DefaultNaming{})
+	expected := []string{
+		"column testitems.int_column is of type bigint instead of int",
+		"table testitems does not have column float64_column of field TestItem.Float64Column",
+		"column testitems.legacy_column is not nullable and has neither default nor field",
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("incorrect problems - %v", problems)
+	}
+
+	problems = validateModel(TestItemType, "testitems", nil, DefaultNaming{})
+	if len(problems) != 1 || problems[0] != "table testitems of type TestItem does not exist" {
+		t.Errorf("incorrect problems for missing table - %v", problems)
+	}
+}
@@ -0,0 +1,78 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// TSVector is the type of a full-text search column, a tsvector generated by PostgreSQL from the text fields of the
+// type tagged with the text search configuration to parse them with, e.g.
+//
+//	type Article struct {
+//		ID     int64
+//		Title  string `pglen:"200" fts:"english"`
+//		Body   string `pglen:"5000" fts:"english"`
+//		Search TSVector
+//	}
+//
+// CreateTable creates the column along with a GIN index on it, and Search matches objects against it. Like other
+// generated fields, it is left out of inserts and updates.
+type TSVector string
+
+var tsvectorType = reflect.TypeOf(TSVector(""))
+
+// searchField returns the full-text search field of the type received as argument, i.e. its first TSVector field.
+func searchField(argt reflect.Type) (reflect.StructField, bool) {
+	for _, field := range columnFields(argt) {
+		if field.Type == tsvectorType {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// searchConfiguration returns the text search configuration of the type received as argument, i.e. the value of the
+// "fts" tag of its first tagged field.
+func searchConfiguration(argt reflect.Type) (string, error) {
+	for _, field := range columnFields(argt) {
+		if configuration := field.Tag.Get("fts"); configuration != "" {
+			return configuration, nil
+		}
+	}
+
+	return "", errors.New(fmt.Sprintf("type %s does not have any fields tagged with fts", argt.Name()))
+}
+
+// Search returns the objects of the type whose full-text search column matches the query, as a slice of the type,
+// ordered by rank with the best matches first. The query is plain text, which is parsed by plainto_tsquery with the
+// text search configuration of the fts tags, so all of its words have to match. Soft deleted objects are left out
+// unless the database is unscoped.
+func (db *Database) Search(t reflect.Type, query string) (any, error) {
+	return db.SearchCtx(context.Background(), t, query)
+}
+
+func (db *Database) SearchCtx(ctx context.Context, t reflect.Type, query string) (any, error) {
+	return retryResult(ctx, db, false, func() (any, error) {
+		return search(ctx, db.readSession(), t, query)
+	})
+}
+
+func (tx *Tx) Search(t reflect.Type, query string) (any, error) {
+	return tx.SearchCtx(context.Background(), t, query)
+}
+
+func (tx *Tx) SearchCtx(ctx context.Context, t reflect.Type, query string) (any, error) {
+	return search(ctx, tx.session(), t, query)
+}
+
+func search(ctx context.Context, s *session, t reflect.Type, query string) (any, error) {
+	clauses, err := buildSearchClauses(t, s.namingStrategy())
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not search objects of type %s", t.Name()))
+	}
+
+	return selectAll(ctx, s, t, clauses, query)
+}
@@ -2,111 +2,742 @@ package liteorm
 
 import (
 	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
 	"reflect"
 	"strings"
 )
 
+// quoteIdentifier quotes a table or column name so that names that are reserved words or contain special characters can
+// be used. Names qualified with a schema, e.g. public.users, are quoted part by part.
+func quoteIdentifier(name string) string {
+	return pgx.Identifier(strings.Split(name, ".")).Sanitize()
+}
+
 // buildCreateStatement uses reflection to build an SQL create statement based on the name and fields of the argument
-// type. The argument type must be a pointer, otherwise an error is returned.
-func buildCreateStatement(argt reflect.Type) (string, error) {
-	tableName := BuildTableName(argt)
+// type, with the column types of the dialect. The argument type must be a pointer, otherwise an error is returned.
+// Types with the "partition" tag are created as partitioned tables, see buildPartitionClause.
+func buildCreateStatement(argt reflect.Type, naming NamingStrategy, dialect Dialect) (string, error) {
+	tableName := quoteIdentifier(naming.TableName(argt))
 	sqlStatement := fmt.Sprintf("create table %s (", tableName)
-	for i := 0; i < argt.NumField(); i++ {
-		var columnType string
-		var err error
-
-		field := argt.Field(i)
-		columnName := strings.ToLower(field.Name)
-		if columnName == "id" {
-			columnType = idColumnType
-		} else {
-			columnType, err = mapColumnType(field)
-			if err != nil {
-				return "", err
-			}
+	fields := columnFields(argt)
+	for i, field := range fields {
+		columnName := quoteIdentifier(columnName(field, naming))
+		columnType, err := buildFieldColumnType(argt, field, naming, dialect)
+		if err != nil {
+			return "", err
 		}
 
 		pgsqlTag := field.Tag.Get("pgsql")
 		sqlStatement += fmt.Sprintf("%s %s %s", columnName, columnType, pgsqlTag)
+		if references := field.Tag.Get("fk"); references != "" {
+			sqlStatement += fmt.Sprintf(" references %s", references)
+		}
 
 		// potentially add a comma, but not for the last column
-		if i+1 < argt.NumField() {
+		if i+1 < len(fields) {
 			sqlStatement += ","
 		}
 	}
 
-	sqlStatement += ");"
+	sqlStatement += ")"
+
+	partitionClause, ok, err := buildPartitionClause(argt, naming)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		sqlStatement += " " + partitionClause
+	}
+
+	sqlStatement += ";"
 
 	return sqlStatement, nil
 }
 
-func buildSelectStatement(argt reflect.Type, clauses string) string {
-	tableName := BuildTableName(argt)
-	columnNames := ""
-	for i := 0; i < argt.NumField(); i++ {
-		field := argt.Field(i)
-		columnNames += strings.ToLower(field.Name)
+// index is an index of a table declared with the "index" or "uniqueIndex" tags of its fields. Fields sharing an index
+// name form a multi-column index, with the columns in field order.
+type index struct {
+	name    string
+	unique  bool
+	columns []string
+}
 
-		// potentially add a comma, but not for the last column
-		if i+1 < argt.NumField() {
-			columnNames += ","
+// buildIndexes collects the indexes declared by the fields of the type received as argument, in the order in which
+// they first appear. The tags hold comma separated index names, so a field can be part of several indexes.
+func buildIndexes(argt reflect.Type, naming NamingStrategy) ([]*index, error) {
+	indexes := make([]*index, 0)
+	indexesByName := make(map[string]*index)
+	for _, field := range columnFields(argt) {
+		for _, tag := range []string{"index", "uniqueIndex"} {
+			value := field.Tag.Get(tag)
+			if value == "" {
+				continue
+			}
+
+			unique := tag == "uniqueIndex"
+			for _, name := range strings.Split(value, ",") {
+				name = strings.TrimSpace(name)
+				idx, ok := indexesByName[name]
+				if !ok {
+					idx = &index{name: name, unique: unique}
+					indexesByName[name] = idx
+					indexes = append(indexes, idx)
+				}
+
+				if idx.unique != unique {
+					return nil, errors.New(fmt.Sprintf("index %s is declared both unique and non-unique", name))
+				}
+
+				idx.columns = append(idx.columns, quoteIdentifier(columnName(field, naming)))
+			}
 		}
 	}
 
-	sqlStatement := fmt.Sprintf("select %s from %s %s;", columnNames, tableName, clauses)
+	return indexes, nil
+}
 
-	return sqlStatement
+// buildDropTableStatement builds a drop table statement for the table of the type, or a drop view statement for views
+// and materialized views. If cascade is set, the objects that depend on the table, such as foreign keys, are dropped
+// along with it.
+func buildDropTableStatement(argt reflect.Type, ifExists bool, cascade bool, naming NamingStrategy) string {
+	statement := "drop table "
+	if _, ok := getViewQuery(argt); ok {
+		statement = "drop view "
+	} else if _, ok := getMaterializedViewQuery(argt); ok {
+		statement = "drop materialized view "
+	}
+	if ifExists {
+		statement += "if exists "
+	}
+
+	statement += quoteIdentifier(naming.TableName(argt))
+	if cascade {
+		statement += " cascade"
+	}
+
+	return statement + ";"
 }
 
-func buildInsertStatement(argt reflect.Type) string {
-	columnNames := ""
-	valueIndices := ""
-	nextIdx := 1
-	for i := 0; i < argt.NumField(); i++ {
-		field := argt.Field(i)
-		if field.Name == "ID" {
+// buildTruncateStatement builds a truncate statement for the table of the type. If restartIdentity is set, the
+// sequences of the identity columns are reset, and if cascade is set, the tables referencing the table are truncated
+// along with it.
+func buildTruncateStatement(argt reflect.Type, restartIdentity bool, cascade bool, naming NamingStrategy) string {
+	statement := "truncate table " + quoteIdentifier(naming.TableName(argt))
+	if restartIdentity {
+		statement += " restart identity"
+	}
+
+	if cascade {
+		statement += " cascade"
+	}
+
+	return statement + ";"
+}
+
+// buildCreateIndexStatements builds a create index statement for each index declared by the fields of the type.
+func buildCreateIndexStatements(argt reflect.Type, naming NamingStrategy) ([]string, error) {
+	indexes, err := buildIndexes(argt, naming)
+	if err != nil {
+		return nil, err
+	}
+
+	tableName := quoteIdentifier(naming.TableName(argt))
+	statements := make([]string, len(indexes))
+	for i, idx := range indexes {
+		createIndex := "create index"
+		if idx.unique {
+			createIndex = "create unique index"
+		}
+
+		statements[i] = fmt.Sprintf("%s if not exists %s on %s (%s);", createIndex, quoteIdentifier(idx.name),
+			tableName, strings.Join(idx.columns, ","))
+	}
+
+	if statement, ok := buildCreateSearchIndexStatement(argt, naming); ok {
+		statements = append(statements, statement)
+	}
+
+	return statements, nil
+}
+
+// buildCreateSearchIndexStatement builds a statement that creates a GIN index on the full-text search column of the
+// type, and reports whether the type has one.
+func buildCreateSearchIndexStatement(argt reflect.Type, naming NamingStrategy) (string, bool) {
+	field, ok := searchField(argt)
+	if !ok {
+		return "", false
+	}
+
+	// the index is named after the unqualified table name, since indexes belong to the schema of their table
+	parts := strings.Split(naming.TableName(argt), ".")
+	columnName := columnName(field, naming)
+	indexName := quoteIdentifier(fmt.Sprintf("%s_%s_idx", parts[len(parts)-1], columnName))
+	return fmt.Sprintf("create index if not exists %s on %s using gin (%s);", indexName,
+		quoteIdentifier(naming.TableName(argt)), quoteIdentifier(columnName)), true
+}
+
+// buildSearchVectorExpression builds the generation expression of the full-text search column of the type, which
+// concatenates the tsvectors of the fields tagged with "fts", each parsed with the configuration of its tag.
+func buildSearchVectorExpression(argt reflect.Type, naming NamingStrategy) (string, error) {
+	vectors := make([]string, 0)
+	for _, field := range columnFields(argt) {
+		configuration := field.Tag.Get("fts")
+		if configuration == "" {
 			continue
 		}
 
-		columnNames += strings.ToLower(field.Name)
-		valueIndices += fmt.Sprintf("$%d", nextIdx)
-		nextIdx++
+		vectors = append(vectors, fmt.Sprintf("to_tsvector('%s', coalesce(%s, ''))", configuration,
+			quoteIdentifier(columnName(field, naming))))
+	}
+
+	if len(vectors) == 0 {
+		return "", errors.New(fmt.Sprintf("type %s does not have any fields tagged with fts", argt.Name()))
+	}
+
+	return fmt.Sprintf("generated always as (%s) stored", strings.Join(vectors, " || ")), nil
+}
+
+// buildSearchClauses builds the clauses that match the full-text search column of the type against the plain text
+// query of the first argument, and order the matches by rank.
+func buildSearchClauses(argt reflect.Type, naming NamingStrategy) (string, error) {
+	field, ok := searchField(argt)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("type %s does not have a full-text search field", argt.Name()))
+	}
+
+	configuration, err := searchConfiguration(argt)
+	if err != nil {
+		return "", err
+	}
+
+	columnName := quoteIdentifier(columnName(field, naming))
+	query := fmt.Sprintf("plainto_tsquery('%s', $1)", configuration)
+	return fmt.Sprintf("where %s @@ %s order by ts_rank(%s, %s) desc", columnName, query, columnName, query), nil
+}
+
+// referencedTables returns the names of the tables referenced by the foreign keys of the type, i.e. the table names in
+// the "fk" tags of its fields, e.g. `fk:"users(id) on delete cascade"`.
+func referencedTables(argt reflect.Type) []string {
+	tableNames := make([]string, 0)
+	for _, field := range columnFields(argt) {
+		references := strings.TrimSpace(field.Tag.Get("fk"))
+		if references == "" {
+			continue
+		}
+
+		tableName, _, _ := strings.Cut(references, "(")
+		tableNames = append(tableNames, strings.TrimSpace(tableName))
+	}
+
+	return tableNames
+}
+
+// sortByReferences orders types so that the tables referenced by the foreign keys of a type come before it. References
+// to tables of types that are not part of the argument are ignored. If the references form a cycle, an error is
+// returned.
+func sortByReferences(types []reflect.Type, naming NamingStrategy) ([]reflect.Type, error) {
+	typesByTable := make(map[string]reflect.Type)
+	for _, t := range types {
+		typesByTable[naming.TableName(t)] = t
+	}
+
+	sorted := make([]reflect.Type, 0, len(types))
+	visited := make(map[reflect.Type]bool)
+	visiting := make(map[reflect.Type]bool)
+	var visit func(t reflect.Type) error
+	visit = func(t reflect.Type) error {
+		if visited[t] {
+			return nil
+		}
+
+		if visiting[t] {
+			return errors.New(fmt.Sprintf("cyclic foreign key references involving table %s", naming.TableName(t)))
+		}
+
+		visiting[t] = true
+		for _, tableName := range referencedTables(t) {
+			referenced, ok := typesByTable[tableName]
+			if !ok || referenced == t {
+				continue
+			}
+
+			if err := visit(referenced); err != nil {
+				return err
+			}
+		}
+
+		visiting[t] = false
+		visited[t] = true
+		sorted = append(sorted, t)
+		return nil
+	}
+
+	for _, t := range types {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// buildColumnType returns the PostgreSQL column type of a field. The column types of ID fields depend on their ID
+// strategy, see buildIDColumnType, all other fields are mapped according to their type.
+func buildColumnType(field reflect.StructField) (string, error) {
+	if field.Name == "ID" {
+		columnType, ok, err := buildIDColumnType(field)
+		if err != nil || ok {
+			return columnType, err
+		}
+	}
+
+	return mapColumnType(field)
+}
+
+// buildFieldColumnType returns the column type of a field for the dialect received as argument, followed by the
+// generation expression of full-text search columns, which depends on the other fields of the type.
+func buildFieldColumnType(argt reflect.Type, field reflect.StructField, naming NamingStrategy,
+	dialect Dialect) (string, error) {
+	columnType, err := dialect.ColumnType(field)
+	if err != nil {
+		return "", err
+	}
+
+	if field.Type != tsvectorType {
+		return columnType, nil
+	}
+
+	expression, err := buildSearchVectorExpression(argt, naming)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s %s", columnType, expression), nil
+}
+
+// buildSelectSource returns the table to select from. For soft deleted types, unless unscoped is set, and for the tenant
+// types of scoped operations, the table is replaced by a subquery of the rows that have not been deleted and that
+// belong to the tenant, aliased with the table name so that the clauses of the statement apply to it unchanged.
+func buildSelectSource(argt reflect.Type, unscoped bool, tenantIdx int, naming NamingStrategy) string {
+	conditions := make([]string, 0)
+	if !unscoped && isSoftDeleted(argt) {
+		deletedAtColumnName := quoteIdentifier(fieldColumnName(argt, "DeletedAt", naming))
+		conditions = append(conditions, fmt.Sprintf("%s is null", deletedAtColumnName))
+	}
+
+	if tenantIdx > 0 {
+		conditions = append(conditions, buildTenantCondition(argt, tenantIdx, naming))
+	}
+
+	tableName := naming.TableName(argt)
+	if len(conditions) == 0 {
+		return quoteIdentifier(tableName)
+	}
+
+	// the subquery is aliased with the unqualified table name, so that clauses referring to it keep working
+	parts := strings.Split(tableName, ".")
+	alias := quoteIdentifier(parts[len(parts)-1])
+	return fmt.Sprintf("(select * from %s where %s) %s", quoteIdentifier(tableName), strings.Join(conditions, " and "),
+		alias)
+}
+
+func buildSelectStatement(argt reflect.Type, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	return buildSelectFieldsStatement(argt, columnFields(argt), clauses, unscoped, tenantIdx, naming)
+}
+
+// buildSelectFields returns the fields of the columns received as argument, given by field or column name, in the order
+// of the fields of the type.
+func buildSelectFields(argt reflect.Type, columns []string, naming NamingStrategy) ([]reflect.StructField, error) {
+	isSelectedColumn := make(map[string]bool)
+	for _, column := range columns {
+		isSelectedColumn[fieldColumnName(argt, column, naming)] = true
+	}
+
+	fields := make([]reflect.StructField, 0, len(columns))
+	for _, field := range columnFields(argt) {
+		columnName := columnName(field, naming)
+		if isSelectedColumn[columnName] {
+			fields = append(fields, field)
+			delete(isSelectedColumn, columnName)
+		}
+	}
+
+	for column := range isSelectedColumn {
+		return nil, errors.New(fmt.Sprintf("type %s does not have a column %s", argt.Name(), column))
+	}
+
+	return fields, nil
+}
+
+// buildSelectFieldsStatement builds a select statement for the columns of the fields received as argument.
+func buildSelectFieldsStatement(argt reflect.Type, fields []reflect.StructField, clauses string, unscoped bool,
+	tenantIdx int, naming NamingStrategy) string {
+	return fmt.Sprintf("%s %s;", buildSelectFieldsPrefix(argt, fields, unscoped, tenantIdx, naming), clauses)
+}
+
+// buildSelectFieldsPrefix builds the part of a select statement that precedes its clauses, i.e. the selected columns
+// and the source of the rows.
+func buildSelectFieldsPrefix(argt reflect.Type, fields []reflect.StructField, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	tableName := buildSelectSource(argt, unscoped, tenantIdx, naming)
+	return fmt.Sprintf("select %s from %s", buildSelectColumnNames(fields, naming), tableName)
+}
+
+// buildSelectColumnNames builds the comma separated list of the quoted column names of the fields received as argument.
+func buildSelectColumnNames(fields []reflect.StructField, naming NamingStrategy) string {
+	columnNames := ""
+	for i, field := range fields {
+		columnNames += quoteIdentifier(columnName(field, naming))
 
 		// potentially add a comma, but not for the last column
-		if i+1 < argt.NumField() {
+		if i+1 < len(fields) {
 			columnNames += ","
-			valueIndices += ","
 		}
 	}
 
-	tableName := BuildTableName(argt)
-	/* the insert statement for postgresql contains a returning clause to recover the new row id
+	return columnNames
+}
+
+// buildPaginateStatement builds a statement selecting the rows matching the clauses from offset on, at most limit of
+// them, along with the total count of the rows matching the clauses as an additional last column.
+func buildPaginateStatement(argt reflect.Type, clauses string, limit int, offset int, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	return fmt.Sprintf("select %s,count(*) over () from %s %s limit %d offset %d;",
+		buildSelectColumnNames(columnFields(argt), naming), buildSelectSource(argt, unscoped, tenantIdx, naming),
+		clauses, limit, offset)
+}
+
+// buildPaginateCountStatement builds a statement counting the rows matching the clauses, which may order the rows
+// unlike the clauses of buildCountStatement.
+func buildPaginateCountStatement(argt reflect.Type, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	return fmt.Sprintf("select count(*) from (select 1 from %s %s) page;",
+		buildSelectSource(argt, unscoped, tenantIdx, naming), clauses)
+}
+
+// buildBatchStatement builds a statement selecting a batch of at most batchSize rows matching the clauses, in the order
+// of their IDs. Unless first is set, the batch starts after the ID given by the argument following the argCount
+// arguments of the clauses. The clauses are applied in a subquery, so that they may not order or limit the rows.
+func buildBatchStatement(argt reflect.Type, clauses string, argCount int, batchSize int, first bool, unscoped bool,
+	tenantIdx int, naming NamingStrategy) string {
+	idColumnName := quoteIdentifier(fieldColumnName(argt, "ID", naming))
+	condition := ""
+	if !first {
+		condition = fmt.Sprintf(" where %s > $%d", idColumnName, argCount+1)
+	}
+
+	return fmt.Sprintf("select * from (%s %s) batch%s order by %s limit %d;",
+		buildSelectFieldsPrefix(argt, columnFields(argt), unscoped, tenantIdx, naming), clauses, condition, idColumnName,
+		batchSize)
+}
+
+// buildCountStatement builds a statement that counts the rows matching the clauses, scoped like buildSelectStatement.
+func buildCountStatement(argt reflect.Type, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	return fmt.Sprintf("select count(*) from %s %s;", buildSelectSource(argt, unscoped, tenantIdx, naming), clauses)
+}
+
+// buildAggregateStatement builds a statement that applies the aggregate function to the column, given by field or
+// column name, over the rows matching the clauses, scoped like buildSelectStatement. The result is cast to a double so
+// that it scans into a float64 regardless of the column type.
+func buildAggregateStatement(argt reflect.Type, fn string, column string, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	columnName := quoteIdentifier(fieldColumnName(argt, column, naming))
+	return fmt.Sprintf("select cast(%s(%s) as double precision) from %s %s;", fn, columnName,
+		buildSelectSource(argt, unscoped, tenantIdx, naming), clauses)
+}
+
+// buildExistsStatement builds a statement that checks whether any row matches the clauses, scoped like
+// buildSelectStatement.
+func buildExistsStatement(argt reflect.Type, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	return fmt.Sprintf("select exists (select 1 from %s %s);", buildSelectSource(argt, unscoped, tenantIdx, naming),
+		clauses)
+}
+
+func buildInsertStatement(argt reflect.Type, naming NamingStrategy, dialect Dialect) string {
+	columnNames := buildInsertColumnNames(argt, naming)
+	valueIndices := buildInsertPlaceholders(argt, naming, dialect)
+	for i := range columnNames {
+		columnNames[i] = quoteIdentifier(columnNames[i])
+	}
+
+	tableName := quoteIdentifier(naming.TableName(argt))
+	/* the insert statement for postgresql contains a returning clause to recover the new row id, along with the other
+	 * values generated by the database, e.g. column defaults and generated columns
 	 * https://stackoverflow.com/a/37771986
 	 */
-	sqlStatement := fmt.Sprintf("insert into %s (%s) values (%s) returning id;", tableName, columnNames, valueIndices)
+	sqlStatement := fmt.Sprintf("insert into %s (%s) values (%s) %s;", tableName,
+		strings.Join(columnNames, ","), strings.Join(valueIndices, ","),
+		dialect.Returning(buildReturningColumnNames(argt, naming)))
 
 	return sqlStatement
 }
 
-func buildUpdateStatement(argt reflect.Type, clauses string, nextIdx int) (string, int) {
-	var set string
-	for i := 0; i < argt.NumField(); i++ {
-		field := argt.Field(i)
-		if field.Name == "ID" {
+// buildUpsertStatement builds an insert statement that, on conflict with an existing row on the conflict columns,
+// updates the remaining columns of that row instead. If all columns are conflict columns, all of them are updated so
+// that the statement still returns the existing row. For the tenant types of scoped operations, the row of another
+// tenant than the one bound to the placeholder of index tenantIdx is not updated.
+func buildUpsertStatement(argt reflect.Type, conflictColumns []string, tenantIdx int, naming NamingStrategy,
+	dialect Dialect) string {
+	conflictColumnNames := make([]string, len(conflictColumns))
+	isConflictColumn := make(map[string]bool)
+	for i, conflictColumn := range conflictColumns {
+		conflictColumnNames[i] = fieldColumnName(argt, conflictColumn, naming)
+		isConflictColumn[conflictColumnNames[i]] = true
+	}
+
+	// the creation time and the ID of the existing row are kept on update, since IDs generated by liteorm are inserted
+	keptColumnNames := make(map[string]bool)
+	if field, ok := argt.FieldByName("CreatedAt"); ok && isTimestampField(field) {
+		keptColumnNames[columnName(field, naming)] = true
+	}
+	if field, ok := argt.FieldByName("ID"); ok {
+		keptColumnNames[columnName(field, naming)] = true
+	}
+
+	columnNames := buildInsertColumnNames(argt, naming)
+	quotedColumnNames := make([]string, len(columnNames))
+	valueIndices := buildInsertPlaceholders(argt, naming, dialect)
+	set := make([]string, 0)
+	for i, columnName := range columnNames {
+		quotedColumnNames[i] = quoteIdentifier(columnName)
+		if !isConflictColumn[columnName] && !keptColumnNames[columnName] {
+			set = append(set, fmt.Sprintf("%s = excluded.%s", quotedColumnNames[i], quotedColumnNames[i]))
+		}
+	}
+
+	if len(set) == 0 {
+		for _, quotedColumnName := range quotedColumnNames {
+			set = append(set, fmt.Sprintf("%s = excluded.%s", quotedColumnName, quotedColumnName))
+		}
+	}
+
+	quotedConflictColumnNames := make([]string, len(conflictColumnNames))
+	for i, conflictColumnName := range conflictColumnNames {
+		quotedConflictColumnNames[i] = quoteIdentifier(conflictColumnName)
+	}
+
+	// the row of another tenant is not updated, so that the statement returns no row and the upsert fails
+	update := strings.Join(set, ",")
+	if tenantIdx > 0 {
+		update += " where " + buildTenantCondition(argt, tenantIdx, naming)
+	}
+
+	tableName := quoteIdentifier(naming.TableName(argt))
+	return fmt.Sprintf("insert into %s (%s) values (%s) on conflict (%s) do update set %s %s;", tableName,
+		strings.Join(quotedColumnNames, ","), strings.Join(valueIndices, ","),
+		strings.Join(quotedConflictColumnNames, ","), update,
+		dialect.Returning(buildReturningColumnNames(argt, naming)))
+}
+
+// buildReturningColumnNames returns the quoted names of all columns of the type, comma separated, for the returning
+// clause of inserts. The columns are in the order of the values built by buildSliceFromFields.
+func buildReturningColumnNames(argt reflect.Type, naming NamingStrategy) string {
+	fields := columnFields(argt)
+	columnNames := make([]string, len(fields))
+	for i, field := range fields {
+		columnNames[i] = quoteIdentifier(columnName(field, naming))
+	}
+
+	return strings.Join(columnNames, ",")
+}
+
+// buildInsertColumnNames returns the names of the columns set when inserting an object, i.e. all columns except the
+// IDs generated by the database and the generated columns.
+func buildInsertColumnNames(argt reflect.Type, naming NamingStrategy) []string {
+	columnNames := make([]string, 0)
+	for _, field := range columnFields(argt) {
+		if !isInsertedField(field) {
 			continue
 		}
 
-		set += fmt.Sprintf("%s = $%d", field.Name, nextIdx)
+		columnNames = append(columnNames, columnName(field, naming))
+	}
+
+	return columnNames
+}
+
+// buildInsertPlaceholders returns the placeholders of the values of the columns returned by buildInsertColumnNames,
+// which are the numbered placeholders except for timestamp fields, see buildTimestampPlaceholder.
+func buildInsertPlaceholders(argt reflect.Type, naming NamingStrategy, dialect Dialect) []string {
+	placeholders := make([]string, 0)
+	for _, field := range columnFields(argt) {
+		if !isInsertedField(field) {
+			continue
+		}
+
+		index := len(placeholders) + 1
+		if isTimestampField(field) {
+			placeholders = append(placeholders, buildTimestampPlaceholder(field, index, false, naming, dialect))
+		} else {
+			placeholders = append(placeholders, fmt.Sprintf("$%d", index))
+		}
+	}
+
+	return placeholders
+}
+
+// buildUpdateFields returns the fields set when updating an object of the type received as argument. These are all
+// column fields except the ID and the generated fields, or only the fields of the columns received as argument if there
+// are any. Columns can be given by field or column name. The Version field of versioned types and the UpdatedAt
+// timestamp field are always part of the update.
+func buildUpdateFields(argt reflect.Type, columns []string, naming NamingStrategy) ([]reflect.StructField, error) {
+	isUpdatedColumn := make(map[string]bool)
+	for _, column := range columns {
+		isUpdatedColumn[fieldColumnName(argt, column, naming)] = true
+	}
+
+	versioned := isVersioned(argt)
+	fields := make([]reflect.StructField, 0)
+	for _, field := range columnFields(argt) {
+		if field.Name == "ID" || isGeneratedField(field) {
+			continue
+		}
+
+		columnName := columnName(field, naming)
+		if len(columns) == 0 || isUpdatedColumn[columnName] || (versioned && field.Name == "Version") ||
+			(isTimestampField(field) && field.Name == "UpdatedAt") {
+			fields = append(fields, field)
+			delete(isUpdatedColumn, columnName)
+		}
+	}
+
+	for column := range isUpdatedColumn {
+		return nil, errors.New(fmt.Sprintf("type %s does not have a column %s", argt.Name(), column))
+	}
+
+	return fields, nil
+}
+
+// buildUpdateStatement builds an update statement setting the columns of the fields received as argument, whose values
+// are numbered from nextIdx. It returns the statement and the next free index.
+func buildUpdateStatement(argt reflect.Type, fields []reflect.StructField, clauses string, nextIdx int,
+	naming NamingStrategy, dialect Dialect) (string, int) {
+	versioned := isVersioned(argt)
+	set := make([]string, 0)
+	for _, field := range fields {
+		// the version of versioned types is incremented by the statement itself
+		if versioned && field.Name == "Version" {
+			versionColumnName := quoteIdentifier(columnName(field, naming))
+			set = append(set, fmt.Sprintf("%s = %s + 1", versionColumnName, versionColumnName))
+			continue
+		}
+
+		placeholder := fmt.Sprintf("$%d", nextIdx)
+		if isTimestampField(field) {
+			placeholder = buildTimestampPlaceholder(field, nextIdx, true, naming, dialect)
+		}
+
+		set = append(set, fmt.Sprintf("%s = %s", quoteIdentifier(columnName(field, naming)), placeholder))
 		nextIdx++
+	}
 
-		// potentially add a comma, but not for the last column
-		if i+1 < argt.NumField() {
-			set += ","
+	tableName := quoteIdentifier(naming.TableName(argt))
+	return fmt.Sprintf("update %s set %s %s;", tableName, strings.Join(set, ","), clauses), nextIdx
+}
+
+// buildUpdateOneStatement builds the statement updating the row of the object received as argument, together with its
+// values. If columns are provided, only these are updated. For versioned types, the statement only matches the row if
+// it still has the version of the object, and for the tenant types of scoped operations if it belongs to the tenant.
+func buildUpdateOneStatement(arg any, columns []string, tenant *tenantScope, naming NamingStrategy,
+	dialect Dialect) (string, []any, error) {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	id, err := getIDValue(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	clauses := buildIDClause(argt, naming)
+	values := []any{id}
+
+	if isVersioned(argt) {
+		version, err := getVersionValue(arg)
+		if err != nil {
+			return "", nil, err
 		}
+
+		clauses += fmt.Sprintf(" and %s = $2", quoteIdentifier(fieldColumnName(argt, "Version", naming)))
+		values = append(values, version)
 	}
 
-	tableName := BuildTableName(argt)
-	return fmt.Sprintf("update %s set %s %s;", tableName, set, clauses), nextIdx
+	tenantIdx, values := tenantArgs(argt, tenant, values)
+	if tenantIdx > 0 {
+		clauses += " and " + buildTenantCondition(argt, tenantIdx, naming)
+	}
+
+	fields, err := buildUpdateFields(argt, columns, naming)
+	if err != nil {
+		return "", nil, err
+	}
+
+	statement, _ := buildUpdateStatement(argt, fields, clauses, len(values)+1, naming, dialect)
+	updateValues, err := buildUpdateValues(arg, fields)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return statement, append(values, updateValues...), nil
+}
+
+// buildUpdateWhereStatement builds an update statement setting the columns received as argument on all rows matching
+// the clauses. The values of the columns are numbered from nextIdx, so that the clauses can use the placeholders from
+// $1. For soft deleted types, unless unscoped is set, only the rows that have not been deleted are updated, for the
+// tenant types of scoped operations only those of the tenant, and the version of versioned types is incremented.
+func buildUpdateWhereStatement(argt reflect.Type, columns []string, clauses string, nextIdx int, unscoped bool,
+	tenantIdx int, naming NamingStrategy) string {
+	set := make([]string, len(columns))
+	for i, column := range columns {
+		set[i] = fmt.Sprintf("%s = $%d", quoteIdentifier(column), nextIdx)
+		nextIdx++
+	}
+
+	if isVersioned(argt) {
+		versionColumnName := quoteIdentifier(fieldColumnName(argt, "Version", naming))
+		set = append(set, fmt.Sprintf("%s = %s + 1", versionColumnName, versionColumnName))
+	}
+
+	tableName := quoteIdentifier(naming.TableName(argt))
+	if (!unscoped && isSoftDeleted(argt)) || tenantIdx > 0 {
+		idColumnName := quoteIdentifier(fieldColumnName(argt, "ID", naming))
+		return fmt.Sprintf("update %s set %s where %s in (select %s from %s %s);", tableName, strings.Join(set, ","),
+			idColumnName, idColumnName, buildSelectSource(argt, unscoped, tenantIdx, naming), clauses)
+	}
+
+	return fmt.Sprintf("update %s set %s %s;", tableName, strings.Join(set, ","), clauses)
+}
+
+// buildUpdateValues returns the values matching the placeholders of buildUpdateStatement, i.e. the values of the fields
+// received as argument except, for versioned types, the version.
+func buildUpdateValues(arg any, fields []reflect.StructField) ([]any, error) {
+	argv, err := getObjectValue(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	versioned := isVersioned(argv.Type())
+	values := make([]any, 0)
+	for _, field := range fields {
+		if versioned && field.Name == "Version" {
+			continue
+		}
+
+		value, err := getColumnValue(argv, field)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
 }
 
 func buildStatementValues(arg any) ([]any, error) {
@@ -116,29 +747,525 @@ func buildStatementValues(arg any) ([]any, error) {
 	}
 
 	values := make([]any, 0)
-	for i := 0; i < argv.Type().NumField(); i++ {
-		field := argv.Type().Field(i)
-		if field.Name == "ID" {
+	for _, field := range columnFields(argv.Type()) {
+		if !isInsertedField(field) {
 			continue
 		}
 
-		values = append(values, argv.Field(i).Interface())
+		value, err := getColumnValue(argv, field)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
 	}
 
 	return values, nil
 }
 
-func buildDeleteStatement(argt reflect.Type, clauses string) string {
-	tableName := BuildTableName(argt)
+// buildIDClause builds the clause matching the row whose ID is the first argument of the statement.
+func buildIDClause(argt reflect.Type, naming NamingStrategy) string {
+	return fmt.Sprintf("where %s = $1", quoteIdentifier(fieldColumnName(argt, "ID", naming)))
+}
+
+// buildIDsClause builds the clause matching the rows whose ID is contained in the first argument of the statement.
+func buildIDsClause(argt reflect.Type, naming NamingStrategy) string {
+	return fmt.Sprintf("where %s = any($1)", quoteIdentifier(fieldColumnName(argt, "ID", naming)))
+}
+
+// buildFirstClauses appends to the clauses the ordering by ID and the limit selecting the row with the lowest ID, or
+// with the highest ID if descending is set.
+func buildFirstClauses(argt reflect.Type, clauses string, descending bool, naming NamingStrategy) string {
+	direction := "asc"
+	if descending {
+		direction = "desc"
+	}
+
+	return fmt.Sprintf("%s order by %s %s limit 1", clauses, quoteIdentifier(fieldColumnName(argt, "ID", naming)),
+		direction)
+}
+
+func buildDeleteStatement(argt reflect.Type, clauses string, naming NamingStrategy) string {
+	tableName := quoteIdentifier(naming.TableName(argt))
 	return fmt.Sprintf("delete from %s %s;", tableName, clauses)
 }
 
-func buildTableExistsStatement(argt reflect.Type, schemaName string) string {
-	tableName := BuildTableName(argt)
-	return fmt.Sprintf(`
-        select exists (
-            select from information_schema.tables
-            where table_schema = '%s'
-            and table_name = '%s'
-        );`, schemaName, tableName)
+// buildScopedDeleteStatement builds the statement deleting the rows matching the clauses. For soft deleted types, unless
+// unscoped is set, the rows are marked as deleted instead of removed. For the tenant types of scoped operations, only
+// the rows of the tenant are deleted.
+func buildScopedDeleteStatement(argt reflect.Type, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy, dialect Dialect) string {
+	if !unscoped && isSoftDeleted(argt) {
+		return buildSoftDeleteStatement(argt, clauses, tenantIdx, naming, dialect)
+	}
+
+	if tenantIdx > 0 {
+		idColumnName := quoteIdentifier(fieldColumnName(argt, "ID", naming))
+		return buildDeleteStatement(argt, fmt.Sprintf("where %s in (select %s from %s %s)", idColumnName, idColumnName,
+			buildSelectSource(argt, true, tenantIdx, naming), clauses), naming)
+	}
+
+	return buildDeleteStatement(argt, clauses, naming)
+}
+
+// buildSoftDeleteStatement builds an update statement that sets the DeletedAt column of the rows matching the clauses
+// that have not been deleted yet to the current timestamp of the dialect.
+func buildSoftDeleteStatement(argt reflect.Type, clauses string, tenantIdx int, naming NamingStrategy,
+	dialect Dialect) string {
+	tableName := quoteIdentifier(naming.TableName(argt))
+	idColumnName := quoteIdentifier(fieldColumnName(argt, "ID", naming))
+	deletedAtColumnName := quoteIdentifier(fieldColumnName(argt, "DeletedAt", naming))
+	return fmt.Sprintf("update %s set %s = %s where %s in (select %s from %s %s);", tableName,
+		deletedAtColumnName, dialect.CurrentTimestamp(), idColumnName, idColumnName, buildSelectSource(argt, false, tenantIdx, naming),
+		clauses)
+}
+
+// buildAuditRowsStatement builds a statement that selects the ID, as text, and the JSON object of the rows matching the
+// clauses, scoped like buildSelectStatement, as recorded in the audit log.
+func buildAuditRowsStatement(argt reflect.Type, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	idColumnName := quoteIdentifier(fieldColumnName(argt, "ID", naming))
+	return fmt.Sprintf("select r.%s::text, to_jsonb(r) from (select * from %s %s) r;", idColumnName,
+		buildSelectSource(argt, unscoped, tenantIdx, naming), clauses)
+}
+
+// buildAuditIDsClause builds the clause matching the rows whose ID, as text like in the audit log, is contained in the
+// first argument of the statement.
+func buildAuditIDsClause(argt reflect.Type, naming NamingStrategy) string {
+	return fmt.Sprintf("where %s::text = any($1)", quoteIdentifier(fieldColumnName(argt, "ID", naming)))
+}
+
+// buildSelectIntoStatement builds a statement that selects the expressions received as argument, e.g. "status, count(*)
+// as total", from the table of the type, scoped like buildSelectStatement.
+func buildSelectIntoStatement(argt reflect.Type, expressions string, clauses string, unscoped bool, tenantIdx int,
+	naming NamingStrategy) string {
+	return fmt.Sprintf("select %s from %s %s;", expressions, buildSelectSource(argt, unscoped, tenantIdx, naming),
+		clauses)
+}
+
+// buildExplainStatement prefixes the statement received as argument with an explain command. If analyze is set, the
+// statement is actually executed and the plan includes the run time and buffer usage statistics.
+func buildExplainStatement(statement string, analyze bool) string {
+	if analyze {
+		return fmt.Sprintf("explain (analyze, buffers, format text) %s", statement)
+	}
+
+	return fmt.Sprintf("explain (format text) %s", statement)
+}
+
+// Query composes the clauses of select and delete statements. Conditions use ? as the placeholder for their arguments,
+// and the placeholders are numbered automatically when the query is built, so that conditions can be added from
+// different places without keeping track of the parameter indices. Queries with a selection and a table, as set by
+// Select and From, can be embedded as subqueries in the conditions of other queries.
+type Query struct {
+	ctes       []cte
+	recursive  bool
+	selection  string
+	from       string
+	unions     []*Query
+	joins      []string
+	joinArgs   []any
+	conditions []string
+	args       []any
+	groupBy    []string
+	having     []string
+	havingArgs []any
+	orderBy    []string
+	limit      int
+	offset     int
+	lock       LockMode
+}
+
+func NewQuery() *Query {
+	return &Query{
+		limit:  -1,
+		offset: -1,
+	}
+}
+
+// cte is a common table expression of a query, i.e. a named subquery of its with clause.
+type cte struct {
+	name     string
+	subquery *Query
+}
+
+// With adds a common table expression to the query, i.e. a subquery whose result can be referred to by name in the
+// conditions of the query, e.g. With("big_orders", NewQuery().From("orders").Where("amount > ?", 100)). The with clause
+// precedes the statement, so it only applies to SelectQuery, SelectInto and SelectJoined.
+func (q *Query) With(name string, subquery *Query) *Query {
+	q.ctes = append(q.ctes, cte{name: name, subquery: subquery})
+	return q
+}
+
+// WithRecursive adds a common table expression to the query, like With, and makes the with clause recursive, so that
+// the subquery can refer to its own name in a union, e.g. to walk a tree of rows by their parent id:
+//
+//	tree := NewQuery().From("categories").Where("id = ?", rootID).
+//		UnionAll(NewQuery().Select("categories.*").From("categories").Join("tree", "categories.parent_id = tree.id"))
+//	db.SelectQuery(t, NewQuery().WithRecursive("tree", tree).Where("id in (select id from tree)"))
+func (q *Query) WithRecursive(name string, subquery *Query) *Query {
+	q.recursive = true
+	return q.With(name, subquery)
+}
+
+// UnionAll appends the rows of another query to those of the query when it is embedded as a subquery, e.g. for the
+// recursive term of WithRecursive.
+func (q *Query) UnionAll(other *Query) *Query {
+	q.unions = append(q.unions, other)
+	return q
+}
+
+// Select sets the expressions selected by the query when it is embedded as a subquery, e.g. Select("user_id").
+func (q *Query) Select(expressions string) *Query {
+	q.selection = expressions
+	return q
+}
+
+// From sets the table selected from by the query when it is embedded as a subquery, e.g. From("orders").
+func (q *Query) From(table string) *Query {
+	q.from = table
+	return q
+}
+
+// WhereExists adds a condition to the query that holds if the subquery received as argument returns any row, e.g.
+// WhereExists(NewQuery().From("orders").Where("orders.user_id = users.id")). The placeholders of the subquery are
+// numbered along with those of the query.
+func (q *Query) WhereExists(subquery *Query) *Query {
+	statement, args := subquery.subquery("1")
+	return q.Where(fmt.Sprintf("exists (%s)", statement), args...)
+}
+
+// WhereIn adds a condition to the query that holds if the column is among the values selected by the subquery received
+// as argument, e.g. WhereIn("id", NewQuery().Select("user_id").From("orders").Where("amount > ?", 100)).
+func (q *Query) WhereIn(column string, subquery *Query) *Query {
+	statement, args := subquery.subquery("*")
+	return q.Where(fmt.Sprintf("%s in (%s)", column, statement), args...)
+}
+
+// Join adds an inner join of the table received as argument to the query, e.g. Join("orders", "orders.user_id =
+// users.id"), for queries whose result is scanned with SelectJoined. The join conditions are placed before the where
+// clause, so their placeholders are numbered first.
+func (q *Query) Join(table string, on string, args ...any) *Query {
+	return q.addJoin("join", table, on, args)
+}
+
+// LeftJoin adds a left outer join of the table received as argument to the query, see Join.
+func (q *Query) LeftJoin(table string, on string, args ...any) *Query {
+	return q.addJoin("left join", table, on, args)
+}
+
+func (q *Query) addJoin(join string, table string, on string, args []any) *Query {
+	q.joins = append(q.joins, fmt.Sprintf("%s %s on %s", join, table, on))
+	q.joinArgs = append(q.joinArgs, args...)
+	return q
+}
+
+// Where adds a condition to the query. Conditions added by multiple calls are combined with "and".
+func (q *Query) Where(condition string, args ...any) *Query {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// GroupBy adds columns to the group by clause of the query, e.g. GroupBy("status"), for queries whose result is scanned
+// with SelectInto.
+func (q *Query) GroupBy(columns ...string) *Query {
+	q.groupBy = append(q.groupBy, columns...)
+	return q
+}
+
+// Having adds a condition on the groups of the query, e.g. Having("count(*) > ?", 10). Conditions added by multiple
+// calls are combined with "and", and their placeholders are numbered after those of the where clause.
+func (q *Query) Having(condition string, args ...any) *Query {
+	q.having = append(q.having, condition)
+	q.havingArgs = append(q.havingArgs, args...)
+	return q
+}
+
+// OrderBy adds columns to the order by clause of the query, e.g. OrderBy("name", "id desc").
+func (q *Query) OrderBy(columns ...string) *Query {
+	q.orderBy = append(q.orderBy, columns...)
+	return q
+}
+
+func (q *Query) Limit(limit int) *Query {
+	q.limit = limit
+	return q
+}
+
+func (q *Query) Offset(offset int) *Query {
+	q.offset = offset
+	return q
+}
+
+// Lock adds a locking clause to the query, e.g. Lock(ForUpdateSkipLocked), which locks the selected rows until the end
+// of the transaction the query is run in. Outside of a transaction, the rows are only locked while they are selected.
+func (q *Query) Lock(mode LockMode) *Query {
+	q.lock = mode
+	return q
+}
+
+// Build returns the clauses of the query, with placeholders numbered from $1, and the arguments matching them.
+func (q *Query) Build() (string, []any) {
+	clauses, args := q.clauses()
+	return Expand(clauses, args...)
+}
+
+// Expand numbers the ? placeholders of the clauses received as argument from $1, like the conditions of a Query, so that
+// clauses with dynamic argument lists can be passed to Select and the other operations taking raw clauses:
+//
+//	clauses, args := liteorm.Expand("where status in (?) and owner_id = ?", []string{"new", "open"}, ownerID)
+//	result, err := db.Select(t, clauses, args...)
+//
+// A slice argument whose placeholder forms an in list, i.e. "in (?)", is expanded into a placeholder per element, here
+// "where status in ($1,$2) and owner_id = $3". An empty slice expands into null, which matches no rows, even with not
+// in. Elsewhere, slices are passed as a single array argument, e.g. to "= any(?)", and so are byte slices. The
+// operations taking raw clauses expand them the same way, so that Expand is only needed to compose clauses beforehand.
+func Expand(clauses string, args ...any) (string, []any) {
+	return expandPlaceholders(clauses, args)
+}
+
+// expandClauses expands the raw clauses of an operation received as argument with Expand if they use ? placeholders.
+// Clauses without arguments, or with numbered placeholders, are returned as they are, so that the ? operators of jsonb
+// can still be used along with numbered placeholders.
+func expandClauses(clauses string, args []any) (string, []any) {
+	if len(args) == 0 {
+		return clauses, args
+	}
+
+	quoted, placeholders := false, false
+	for i := 0; i < len(clauses); i++ {
+		switch c := clauses[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '$' && i+1 < len(clauses) && clauses[i+1] >= '0' && clauses[i+1] <= '9':
+			return clauses, args
+		case c == '?':
+			placeholders = true
+		}
+	}
+
+	if !placeholders {
+		return clauses, args
+	}
+
+	return expandPlaceholders(clauses, args)
+}
+
+// buildStatement returns the statement built from the clauses of the query by the function received as argument,
+// preceded by the with clause of the query, with placeholders numbered from $1, and the arguments matching them. For
+// the tenant types of scoped operations, the tenant is bound after the arguments of the query, and the function
+// receives the index of its placeholder, see tenantArgs.
+func (q *Query) buildStatement(argt reflect.Type, tenant *tenantScope,
+	build func(clauses string, tenantIdx int) string) (string, []any) {
+	with, args := q.with()
+	clauses, clauseArgs := q.clauses()
+	args = append(args, clauseArgs...)
+
+	// the statement built around the clauses adds no ? placeholder, so the clauses alone give the number of arguments
+	_, expanded := expandPlaceholders(with+clauses, args)
+	tenantIdx, _ := tenantArgs(argt, tenant, expanded)
+
+	statement, expanded := expandPlaceholders(with+build(clauses, tenantIdx), args)
+	_, expanded = tenantArgs(argt, tenant, expanded)
+	return statement, expanded
+}
+
+// with returns the with clause of the query, with ? placeholders, and the arguments matching them.
+func (q *Query) with() (string, []any) {
+	if len(q.ctes) == 0 {
+		return "", nil
+	}
+
+	ctes := make([]string, len(q.ctes))
+	args := make([]any, 0)
+	for i, cte := range q.ctes {
+		statement, cteArgs := cte.subquery.subquery("*")
+		ctes[i] = fmt.Sprintf("%s as (%s)", cte.name, statement)
+		args = append(args, cteArgs...)
+	}
+
+	if q.recursive {
+		return fmt.Sprintf("with recursive %s ", strings.Join(ctes, ", ")), args
+	}
+
+	return fmt.Sprintf("with %s ", strings.Join(ctes, ", ")), args
+}
+
+// subquery returns the select statement of the query, with ? placeholders, and the arguments matching them. The
+// selection defaults to the one received as argument.
+func (q *Query) subquery(defaultSelection string) (string, []any) {
+	selection := q.selection
+	if selection == "" {
+		selection = defaultSelection
+	}
+
+	clauses, args := q.clauses()
+	statement := strings.TrimSpace(fmt.Sprintf("select %s from %s %s", selection, q.from, clauses))
+	for _, union := range q.unions {
+		unionStatement, unionArgs := union.subquery(defaultSelection)
+		statement += fmt.Sprintf(" union all %s", unionStatement)
+		args = append(args, unionArgs...)
+	}
+
+	return statement, args
+}
+
+// clauses returns the clauses of the query, with ? placeholders, and the arguments matching them.
+func (q *Query) clauses() (string, []any) {
+	clauses := make([]string, 0)
+	clauses = append(clauses, q.joins...)
+
+	if len(q.conditions) > 0 {
+		clauses = append(clauses, fmt.Sprintf("where %s", joinConditions(q.conditions)))
+	}
+
+	if len(q.groupBy) > 0 {
+		clauses = append(clauses, fmt.Sprintf("group by %s", strings.Join(q.groupBy, ", ")))
+	}
+
+	if len(q.having) > 0 {
+		clauses = append(clauses, fmt.Sprintf("having %s", joinConditions(q.having)))
+	}
+
+	if len(q.orderBy) > 0 {
+		clauses = append(clauses, fmt.Sprintf("order by %s", strings.Join(q.orderBy, ", ")))
+	}
+
+	if q.limit >= 0 {
+		clauses = append(clauses, fmt.Sprintf("limit %d", q.limit))
+	}
+
+	if q.offset >= 0 {
+		clauses = append(clauses, fmt.Sprintf("offset %d", q.offset))
+	}
+
+	if q.lock != "" {
+		clauses = append(clauses, string(q.lock))
+	}
+
+	args := append(append([]any{}, q.joinArgs...), q.args...)
+	return strings.Join(clauses, " "), append(args, q.havingArgs...)
+}
+
+// joinConditions combines conditions with "and", each within parentheses.
+func joinConditions(conditions []string) string {
+	parts := make([]string, len(conditions))
+	for i, condition := range conditions {
+		parts[i] = fmt.Sprintf("(%s)", condition)
+	}
+
+	return strings.Join(parts, " and ")
+}
+
+// expandPlaceholders replaces each ? placeholder in the statement received as argument with a numbered $n placeholder,
+// starting at $1, and returns the resulting statement along with the arguments matching the numbered placeholders. The
+// slice arguments of placeholders that form an in list are expanded into a placeholder per element, see Expand.
+// Question marks within quoted literals are left untouched.
+func expandPlaceholders(statement string, args []any) (string, []any) {
+	var result strings.Builder
+	expanded := make([]any, 0, len(args))
+	quoted := false
+	nextIdx, argIdx := 1, 0
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		switch {
+		case c == '\'':
+			quoted = !quoted
+			result.WriteByte(c)
+		case c == '?' && !quoted:
+			if argIdx >= len(args) {
+				result.WriteString(fmt.Sprintf("$%d", nextIdx))
+				nextIdx++
+				break
+			}
+
+			arg := args[argIdx]
+			argIdx++
+			values, ok := expandedValues(arg)
+			if !ok || !isInList(result.String(), statement[i+1:]) {
+				values = []any{arg}
+			} else if len(values) == 0 {
+				result.WriteString("null")
+				break
+			}
+
+			for j, value := range values {
+				if j > 0 {
+					result.WriteByte(',')
+				}
+				result.WriteString(fmt.Sprintf("$%d", nextIdx))
+				expanded = append(expanded, value)
+				nextIdx++
+			}
+		default:
+			result.WriteByte(c)
+		}
+	}
+
+	// arguments without placeholders are passed on, so that the database reports the mismatch
+	return result.String(), append(expanded, args[argIdx:]...)
+}
+
+// expandedValues returns the elements of the argument received as argument if it is a slice or an array, other than a
+// byte slice.
+func expandedValues(arg any) ([]any, bool) {
+	argv := reflect.ValueOf(arg)
+	if !argv.IsValid() || (argv.Kind() != reflect.Slice && argv.Kind() != reflect.Array) ||
+		argv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	values := make([]any, argv.Len())
+	for i := range values {
+		values[i] = argv.Index(i).Interface()
+	}
+
+	return values, true
+}
+
+// isInList reports whether a placeholder preceded and followed by the parts of a statement received as argument is the
+// only element of an in list, e.g. "status in (?)".
+func isInList(before string, after string) bool {
+	before = strings.TrimRight(before, " \t\n")
+	if !strings.HasSuffix(before, "(") {
+		return false
+	}
+
+	before = strings.ToLower(strings.TrimRight(strings.TrimSuffix(before, "("), " \t\n"))
+	if !strings.HasSuffix(before, " in") && !strings.HasSuffix(before, "\tin") && !strings.HasSuffix(before, "\nin") {
+		return false
+	}
+
+	return strings.HasPrefix(strings.TrimLeft(after, " \t\n"), ")")
+}
+
+// buildAddColumnStatement builds an alter table statement that adds the column of the field received as argument.
+func buildAddColumnStatement(argt reflect.Type, field reflect.StructField, naming NamingStrategy,
+	dialect Dialect) (string, error) {
+	columnType, err := buildFieldColumnType(argt, field, naming, dialect)
+	if err != nil {
+		return "", err
+	}
+
+	tableName := quoteIdentifier(naming.TableName(argt))
+	columnName := quoteIdentifier(columnName(field, naming))
+	pgsqlTag := field.Tag.Get("pgsql")
+	return fmt.Sprintf("alter table %s add column %s %s %s;", tableName, columnName, columnType, pgsqlTag), nil
+}
+
+// buildAlterColumnTypeStatement builds an alter table statement that changes the type of the column of the field
+// received as argument. Existing values are cast to the new type.
+func buildAlterColumnTypeStatement(argt reflect.Type, field reflect.StructField, naming NamingStrategy) (string,
+	error) {
+	columnType, err := mapColumnType(field)
+	if err != nil {
+		return "", err
+	}
+
+	tableName := quoteIdentifier(naming.TableName(argt))
+	columnName := quoteIdentifier(columnName(field, naming))
+	return fmt.Sprintf("alter table %s alter column %s type %s using %s::%s;", tableName, columnName, columnType,
+		columnName, columnType), nil
 }
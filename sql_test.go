@@ -0,0 +1,1033 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryBuild(t *testing.T) {
+	q := NewQuery().
+		Where("intcolumn = ? or intcolumn = ?", 1, 2).
+		Where("stringcolumn <> '?'").
+		Where("id > ?", 10).
+		OrderBy("id desc").
+		Limit(5).
+		Offset(10)
+
+	clauses, args := q.Build()
+
+	expected := "where (intcolumn = $1 or intcolumn = $2) and (stringcolumn <> '?') and (id > $3) order by id desc limit 5 offset 10"
+	if clauses != expected {
+		t.Errorf("incorrect clauses built - %s", clauses)
+	}
+
+	if len(args) != 3 {
+		t.Errorf("incorrect amount of arguments - %d instead of 3", len(args))
+	}
+
+	clauses, args = NewQuery().Build()
+	if clauses != "" || len(args) != 0 {
+		t.Errorf("empty query should build empty clauses - %s", clauses)
+	}
+
+	clauses, args = NewQuery().
+		Having("count(*) > ?", 1).
+		Where("intcolumn > ?", 0).
+		GroupBy("stringcolumn").
+		OrderBy("stringcolumn").
+		Build()
+
+	expected = "where (intcolumn > $1) group by stringcolumn having (count(*) > $2) order by stringcolumn"
+	if clauses != expected {
+		t.Errorf("incorrect grouped clauses built - %s", clauses)
+	}
+
+	if len(args) != 2 || args[0] != 0 || args[1] != 1 {
+		t.Errorf("incorrect arguments of grouped query - %v", args)
+	}
+}
+
+func TestSubqueries(t *testing.T) {
+	orders := NewQuery().
+		Select("test_user_id").
+		From("testorders").
+		Where("amount > ?", 100)
+	recent := NewQuery().
+		From("testorders").
+		Where("testorders.test_user_id = testusers.id and created_at > ?", "2024-01-01")
+
+	clauses, args := NewQuery().
+		Where("name <> ?", "admin").
+		WhereIn("id", orders).
+		WhereExists(recent).
+		Limit(10).
+		Build()
+
+	expected := "where (name <> $1) and (id in (select test_user_id from testorders where (amount > $2))) and (exists (select 1 from testorders where (testorders.test_user_id = testusers.id and created_at > $3))) limit 10"
+	if clauses != expected {
+		t.Errorf("incorrect clauses built - %s", clauses)
+	}
+
+	if len(args) != 3 || args[0] != "admin" || args[1] != 100 || args[2] != "2024-01-01" {
+		t.Errorf("incorrect arguments of query with subqueries - %v", args)
+	}
+}
+
+func TestCommonTableExpressions(t *testing.T) {
+	tree := NewQuery().
+		From("categories").
+		Where("id = ?", 1).
+		UnionAll(NewQuery().
+			Select("categories.*").
+			From("categories").
+			Join("tree", "categories.parent_id = tree.id").
+			Where("categories.depth < ?", 5))
+
+	statement, args := NewQuery().
+		WithRecursive("tree", tree).
+		Where("id in (select id from tree)").
+		Where("name <> ?", "root").
+		buildStatement(TestItemType, nil, func(clauses string, _ int) string {
+			return "select * from categories " + clauses + ";"
+		})
+
+	expected := "with recursive tree as (select * from categories where (id = $1) union all select categories.* from categories join tree on categories.parent_id = tree.id where (categories.depth < $2)) select * from categories where (id in (select id from tree)) and (name <> $3);"
+	if statement != expected {
+		t.Errorf("incorrect statement built - %s", statement)
+	}
+
+	if len(args) != 3 || args[0] != 1 || args[1] != 5 || args[2] != "root" {
+		t.Errorf("incorrect arguments of query with common table expressions - %v", args)
+	}
+}
+
+type TestColumnItem struct {
+	ID        int64      `column:"item_id"`
+	Email     string     `column:"email_address" pglen:"100"`
+	Name      string     `pglen:"100"`
+	DeletedAt *time.Time `column:"deleted_at"`
+}
+
+var TestColumnItemType reflect.Type = reflect.TypeOf((*TestColumnItem)(nil)).Elem()
+
+func TestSelectJoinedStatement(t *testing.T) {
+	components, err := joinComponents(reflect.TypeOf(TestUserOrder{}))
+	if err != nil {
+		t.Fatalf("could not find join components - %s", err.Error())
+	}
+
+	clauses, args := NewQuery().Join("testorders", "testorders.test_user_id = testusers.id and amount > ?", 1).
+		Where("name = ?", "joined").
+		Build()
+	statement := buildSelectJoinedStatement(components, clauses, false, 0, DefaultNaming{})
+	expected := `select "testusers"."id","testusers"."name","testorders"."id","testorders"."test_user_id","testorders"."amount" from "testusers" join testorders on testorders.test_user_id = testusers.id and amount > $1 where (name = $2);`
+	if statement != expected || len(args) != 2 {
+		t.Errorf("incorrect select joined statement - %s", statement)
+	}
+}
+
+func TestColumnTag(t *testing.T) {
+	createStatement, err := buildCreateStatement(TestColumnItemType, DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Errorf("could not build create statement - %s", err.Error())
+	}
+
+	expected := `create table "testcolumnitems" ("item_id" bigserial ,"email_address" varchar(100) ,"name" varchar(100) ,"deleted_at" timestamp );`
+	if createStatement != expected {
+		t.Errorf("incorrect create statement - %s", createStatement)
+	}
+
+	selectStatement := buildSelectStatement(TestColumnItemType, "", false, 0, DefaultNaming{})
+	expected = `select "item_id","email_address","name","deleted_at" from (select * from "testcolumnitems" where "deleted_at" is null) "testcolumnitems" ;`
+	if selectStatement != expected {
+		t.Errorf("incorrect select statement - %s", selectStatement)
+	}
+
+	insertStatement := buildInsertStatement(TestColumnItemType, DefaultNaming{}, PostgreSQL{})
+	expected = `insert into "testcolumnitems" ("email_address","name","deleted_at") values ($1,$2,$3) returning "item_id","email_address","name","deleted_at";`
+	if insertStatement != expected {
+		t.Errorf("incorrect insert statement - %s", insertStatement)
+	}
+
+	fields, err := buildUpdateFields(TestColumnItemType, nil, DefaultNaming{})
+	if err != nil {
+		t.Errorf("could not build update fields - %s", err.Error())
+	}
+
+	updateStatement, _ := buildUpdateStatement(TestColumnItemType, fields, "where item_id = $4", 1, DefaultNaming{},
+		PostgreSQL{})
+	expected = `update "testcolumnitems" set "email_address" = $1,"name" = $2,"deleted_at" = $3 where item_id = $4;`
+	if updateStatement != expected {
+		t.Errorf("incorrect update statement - %s", updateStatement)
+	}
+
+	upsertStatement := buildUpsertStatement(TestColumnItemType, []string{"Email"}, 0, DefaultNaming{}, PostgreSQL{})
+	if !strings.Contains(upsertStatement, `on conflict ("email_address")`) {
+		t.Errorf("incorrect upsert statement - %s", upsertStatement)
+	}
+}
+
+type TestSkipItem struct {
+	ID       int64
+	Name     string `pglen:"100"`
+	Computed string `liteorm:"-"`
+}
+
+var TestSkipItemType reflect.Type = reflect.TypeOf((*TestSkipItem)(nil)).Elem()
+
+func TestSkipTag(t *testing.T) {
+	createStatement, err := buildCreateStatement(TestSkipItemType, DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Errorf("could not build create statement - %s", err.Error())
+	}
+
+	expected := `create table "testskipitems" ("id" bigserial ,"name" varchar(100) );`
+	if createStatement != expected {
+		t.Errorf("incorrect create statement - %s", createStatement)
+	}
+
+	selectStatement := buildSelectStatement(TestSkipItemType, "", false, 0, DefaultNaming{})
+	if selectStatement != `select "id","name" from "testskipitems" ;` {
+		t.Errorf("incorrect select statement - %s", selectStatement)
+	}
+
+	insertStatement := buildInsertStatement(TestSkipItemType, DefaultNaming{}, PostgreSQL{})
+	if insertStatement != `insert into "testskipitems" ("name") values ($1) returning "id","name";` {
+		t.Errorf("incorrect insert statement - %s", insertStatement)
+	}
+
+	values, err := buildStatementValues(&TestSkipItem{Name: "lashbits.tech", Computed: "computed"})
+	if err != nil || len(values) != 1 {
+		t.Errorf("skipped field included in the statement values")
+	}
+}
+
+type TestTimestamps struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TestEmbeddedItem struct {
+	ID   int64
+	Name string `pglen:"100"`
+	TestTimestamps
+}
+
+var TestEmbeddedItemType reflect.Type = reflect.TypeOf((*TestEmbeddedItem)(nil)).Elem()
+
+func TestEmbeddedStruct(t *testing.T) {
+	createStatement, err := buildCreateStatement(TestEmbeddedItemType, DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Errorf("could not build create statement - %s", err.Error())
+	}
+
+	expected := `create table "testembeddeditems" ("id" bigserial ,"name" varchar(100) ,"created_at" timestamp ,"updated_at" timestamp );`
+	if createStatement != expected {
+		t.Errorf("incorrect create statement - %s", createStatement)
+	}
+
+	selectStatement := buildSelectStatement(TestEmbeddedItemType, "", false, 0, DefaultNaming{})
+	if selectStatement != `select "id","name","created_at","updated_at" from "testembeddeditems" ;` {
+		t.Errorf("incorrect select statement - %s", selectStatement)
+	}
+
+	createdAt := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	object := &TestEmbeddedItem{Name: "lashbits.tech", TestTimestamps: TestTimestamps{CreatedAt: createdAt}}
+	values, err := buildStatementValues(object)
+	if err != nil {
+		t.Errorf("could not build statement values - %s", err.Error())
+	}
+
+	if len(values) != 3 || values[1] != createdAt {
+		t.Errorf("incorrect statement values - %v", values)
+	}
+}
+
+func TestTimestampStatements(t *testing.T) {
+	insertStatement := buildInsertStatement(TestEmbeddedItemType, DefaultNaming{}, PostgreSQL{})
+	expected := `insert into "testembeddeditems" ("name","created_at","updated_at") values ($1,coalesce($2, ` +
+		`(now() at time zone 'utc')),coalesce($3, (now() at time zone 'utc'))) returning "id","name","created_at","updated_at";`
+	if insertStatement != expected {
+		t.Errorf("incorrect insert statement - %s", insertStatement)
+	}
+
+	upsertStatement := buildUpsertStatement(TestEmbeddedItemType, []string{"Name"}, 0, DefaultNaming{}, PostgreSQL{})
+	if !strings.Contains(upsertStatement, `do update set "updated_at" = excluded."updated_at" returning`) {
+		t.Errorf("incorrect upsert statement - %s", upsertStatement)
+	}
+
+	object := &TestEmbeddedItem{ID: 1, Name: "lashbits.tech"}
+	updateStatement, values, err := buildUpdateOneStatement(object, []string{"Name"}, nil, DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Fatalf("could not build update statement - %s", err.Error())
+	}
+
+	expected = `update "testembeddeditems" set "name" = $2,"updated_at" = coalesce($3, (now() at time zone 'utc')) ` +
+		`where "id" = $1;`
+	if updateStatement != expected || len(values) != 3 || values[2] != nil {
+		t.Errorf("incorrect update statement - %s %v", updateStatement, values)
+	}
+
+	updateStatement, _, err = buildUpdateOneStatement(object, nil, nil, DefaultNaming{}, PostgreSQL{})
+	if err != nil || !strings.Contains(updateStatement, `"created_at" = coalesce($3, "created_at")`) {
+		t.Errorf("incorrect update statement - %s", updateStatement)
+	}
+
+	err = setClientTimestamps(object, true)
+	if err != nil || object.CreatedAt.IsZero() || !object.UpdatedAt.Equal(object.CreatedAt) {
+		t.Errorf("timestamps not set - %v", object)
+	}
+
+	createdAt := object.CreatedAt
+	err = settings{databaseTimestamps: true}.setTimestamps(object, false)
+	if err != nil || !object.CreatedAt.Equal(createdAt) || !object.UpdatedAt.IsZero() {
+		t.Errorf("timestamps not reset - %v", object)
+	}
+}
+
+type TestIndexedItem struct {
+	ID        int64
+	Email     string `pglen:"100" uniqueIndex:"testindexeditems_email"`
+	FirstName string `pglen:"100" index:"testindexeditems_name"`
+	LastName  string `pglen:"100" index:"testindexeditems_name,testindexeditems_lastname"`
+}
+
+var TestIndexedItemType reflect.Type = reflect.TypeOf((*TestIndexedItem)(nil)).Elem()
+
+func TestCreateIndexStatements(t *testing.T) {
+	statements, err := buildCreateIndexStatements(TestIndexedItemType, DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not build create index statements - %s", err.Error())
+	}
+
+	expected := []string{
+		`create unique index if not exists "testindexeditems_email" on "testindexeditems" ("email");`,
+		`create index if not exists "testindexeditems_name" on "testindexeditems" ("first_name","last_name");`,
+		`create index if not exists "testindexeditems_lastname" on "testindexeditems" ("last_name");`,
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("incorrect create index statements - %v", statements)
+	}
+}
+
+func TestEnumStatements(t *testing.T) {
+	statement, err := buildCreateStatement(TestPostType, DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Fatalf("could not build create statement - %s", err.Error())
+	}
+
+	if !strings.Contains(statement, `"status" test_post_status`) ||
+		!strings.Contains(statement, `"previous_status" test_post_status`) {
+		t.Errorf("incorrect enum columns in create statement - %s", statement)
+	}
+
+	statements := buildCreateEnumStatements(TestPostType)
+	expected := []string{`do $$ begin create type "test_post_status" as enum ('draft','published'); exception when duplicate_object then null; end $$;`}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("incorrect create enum statements - %v", statements)
+	}
+
+	statement, err = buildCreateStatement(TestPostType, DefaultNaming{}, SQLite{})
+	if err != nil || !strings.Contains(statement, `"status" text`) {
+		t.Errorf("incorrect enum column in SQLite create statement - %s", statement)
+	}
+
+	status := TestPostStatus("archived")
+	for _, post := range []*TestPost{{Status: "archived"}, {Status: "draft", PreviousStatus: &status}} {
+		if _, err = buildStatementValues(post); err == nil {
+			t.Errorf("expected error on values that are not part of the enum - %v", post)
+		}
+	}
+
+	if _, err = buildStatementValues(&TestPost{Status: "published"}); err != nil {
+		t.Errorf("could not build values of a valid enum - %s", err.Error())
+	}
+}
+
+func TestSearchStatements(t *testing.T) {
+	statement, err := buildCreateStatement(TestArticleType, DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Fatalf("could not build create statement - %s", err.Error())
+	}
+
+	expected := `"search" tsvector generated always as (to_tsvector('english', coalesce("title", '')) || to_tsvector('english', coalesce("body", ''))) stored`
+	if !strings.Contains(statement, expected) {
+		t.Errorf("incorrect search column in create statement - %s", statement)
+	}
+
+	statements, err := buildCreateIndexStatements(TestArticleType, DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not build create index statements - %s", err.Error())
+	}
+
+	expectedIndex := `create index if not exists "testarticles_search_idx" on "testarticles" using gin ("search");`
+	if len(statements) != 1 || statements[0] != expectedIndex {
+		t.Errorf("incorrect create index statements - %v", statements)
+	}
+
+	clauses, err := buildSearchClauses(TestArticleType, DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not build search clauses - %s", err.Error())
+	}
+
+	expectedClauses := `where "search" @@ plainto_tsquery('english', $1) order by ts_rank("search", plainto_tsquery('english', $1)) desc`
+	if clauses != expectedClauses {
+		t.Errorf("incorrect search clauses - %s", clauses)
+	}
+
+	if _, err = buildSearchClauses(TestItemType, DefaultNaming{}); err == nil {
+		t.Errorf("expected error on search clauses of a type without a search field")
+	}
+
+	if statement := buildInsertStatement(TestArticleType, DefaultNaming{}, PostgreSQL{}); !strings.Contains(statement,
+		`("title","body") values ($1,$2)`) {
+		t.Errorf("search column is not left out of inserts - %s", statement)
+	}
+}
+
+type TestAuthor struct {
+	ID   int64
+	Name string `pglen:"100"`
+}
+
+type TestBook struct {
+	ID       int64
+	AuthorID int64  `fk:"testauthors(id) on delete cascade"`
+	Title    string `pglen:"100"`
+}
+
+var TestAuthorType reflect.Type = reflect.TypeOf((*TestAuthor)(nil)).Elem()
+var TestBookType reflect.Type = reflect.TypeOf((*TestBook)(nil)).Elem()
+
+func TestForeignKeys(t *testing.T) {
+	createStatement, err := buildCreateStatement(TestBookType, DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Errorf("could not build create statement - %s", err.Error())
+	}
+
+	expected := `create table "testbooks" ("id" bigserial ,"author_id" bigint  references testauthors(id) on delete cascade,"title" varchar(100) );`
+	if createStatement != expected {
+		t.Errorf("incorrect create statement - %s", createStatement)
+	}
+
+	sorted, err := sortByReferences([]reflect.Type{TestBookType, TestAuthorType}, DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not sort types - %s", err.Error())
+	}
+
+	if len(sorted) != 2 || sorted[0] != TestAuthorType || sorted[1] != TestBookType {
+		t.Errorf("referenced table not sorted first - %v", sorted)
+	}
+}
+
+func TestCountStatements(t *testing.T) {
+	countStatement := buildCountStatement(TestColumnItemType, "where name = $1", false, 0, DefaultNaming{})
+	expected := `select count(*) from (select * from "testcolumnitems" where "deleted_at" is null) "testcolumnitems" where name = $1;`
+	if countStatement != expected {
+		t.Errorf("incorrect count statement - %s", countStatement)
+	}
+
+	existsStatement := buildExistsStatement(TestColumnItemType, "where name = $1", true, 0, DefaultNaming{})
+	expected = `select exists (select 1 from "testcolumnitems" where name = $1);`
+	if existsStatement != expected {
+		t.Errorf("incorrect exists statement - %s", existsStatement)
+	}
+
+	aggregateStatement := buildAggregateStatement(TestColumnItemType, "max", "ID", "", true, 0, DefaultNaming{})
+	expected = `select cast(max("item_id") as double precision) from "testcolumnitems" ;`
+	if aggregateStatement != expected {
+		t.Errorf("incorrect aggregate statement - %s", aggregateStatement)
+	}
+}
+
+func TestUpdateColumnsStatement(t *testing.T) {
+	fields, err := buildUpdateFields(TestColumnItemType, []string{"Name", "email_address"}, DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not build update fields - %s", err.Error())
+	}
+
+	updateStatement, nextIdx := buildUpdateStatement(TestColumnItemType, fields, "where item_id = $1", 2,
+		DefaultNaming{}, PostgreSQL{})
+	expected := `update "testcolumnitems" set "email_address" = $2,"name" = $3 where item_id = $1;`
+	if updateStatement != expected || nextIdx != 4 {
+		t.Errorf("incorrect update statement - %s", updateStatement)
+	}
+
+	_, err = buildUpdateFields(TestColumnItemType, []string{"missing"}, DefaultNaming{})
+	if err == nil {
+		t.Errorf("update of a nonexistent column did not fail")
+	}
+}
+
+func TestUpdateWhereStatement(t *testing.T) {
+	statement := buildUpdateWhereStatement(TestColumnItemType, []string{"name"}, "where email_address = $1", 2, false,
+		0, DefaultNaming{})
+	expected := `update "testcolumnitems" set "name" = $2 where "item_id" in (select "item_id" from (select * from "testcolumnitems" where "deleted_at" is null) "testcolumnitems" where email_address = $1);`
+	if statement != expected {
+		t.Errorf("incorrect update statement - %s", statement)
+	}
+
+	statement = buildUpdateWhereStatement(TestColumnItemType, []string{"name"}, "where email_address = $1", 2, true,
+		0, DefaultNaming{})
+	if statement != `update "testcolumnitems" set "name" = $2 where email_address = $1;` {
+		t.Errorf("incorrect unscoped update statement - %s", statement)
+	}
+}
+
+func TestSelectColumnsStatement(t *testing.T) {
+	fields, err := buildSelectFields(TestColumnItemType, []string{"name", "ID"}, DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not build select fields - %s", err.Error())
+	}
+
+	statement := buildSelectFieldsStatement(TestColumnItemType, fields, "where name = $1", true, 0, DefaultNaming{})
+	if statement != `select "item_id","name" from "testcolumnitems" where name = $1;` {
+		t.Errorf("incorrect select statement - %s", statement)
+	}
+
+	_, err = buildSelectFields(TestColumnItemType, []string{"missing"}, DefaultNaming{})
+	if err == nil {
+		t.Errorf("selection of a nonexistent column did not fail")
+	}
+}
+
+func TestResultFields(t *testing.T) {
+	fields, err := buildResultFields(TestColumnItemType, []string{"name", "item_id"}, DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not build result fields - %s", err.Error())
+	}
+
+	if len(fields) != 2 || fields[0].Name != "Name" || fields[1].Name != "ID" {
+		t.Errorf("incorrect result fields - %v", fields)
+	}
+
+	_, err = buildResultFields(TestColumnItemType, []string{"missing"}, DefaultNaming{})
+	if err == nil {
+		t.Errorf("result column without a matching field did not fail")
+	}
+}
+
+type TestReservedItem struct {
+	ID    int64
+	Group string `pglen:"100"`
+	Order int
+}
+
+var TestReservedItemType reflect.Type = reflect.TypeOf((*TestReservedItem)(nil)).Elem()
+
+func TestQuoteIdentifier(t *testing.T) {
+	identifiers := map[string]string{
+		"users":        `"users"`,
+		"public.users": `"public"."users"`,
+		`weird"name`:   `"weird""name"`,
+	}
+
+	for identifier, expected := range identifiers {
+		if quoted := quoteIdentifier(identifier); quoted != expected {
+			t.Errorf("incorrect quoted identifier - %s instead of %s", quoted, expected)
+		}
+	}
+
+	insertStatement := buildInsertStatement(TestReservedItemType, DefaultNaming{}, PostgreSQL{})
+	expected := `insert into "testreserveditems" ("group","order") values ($1,$2) returning "id","group","order";`
+	if insertStatement != expected {
+		t.Errorf("incorrect insert statement - %s", insertStatement)
+	}
+}
+
+func TestAuditRowsStatement(t *testing.T) {
+	statement := buildAuditRowsStatement(TestItemType, "where id = $1", false, 0, DefaultNaming{})
+	expected := `select r."id"::text, to_jsonb(r) from (select * from "testitems" where id = $1) r;`
+	if statement != expected {
+		t.Errorf("incorrect audit rows statement - %s", statement)
+	}
+
+	statement = buildAuditRowsStatement(TestSoftDeleteItemType, "", false, 0, DefaultNaming{})
+	if !strings.Contains(statement, `"deleted_at" is null`) {
+		t.Errorf("audit rows statement not scoped - %s", statement)
+	}
+}
+
+func TestAuditLogCreateStatement(t *testing.T) {
+	statement, err := buildCreateStatement(reflect.TypeOf(AuditLog{}), DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Fatalf("could not build create statement - %s", err.Error())
+	}
+
+	expected := `create table "audit_log" ("id" bigserial ,"table" varchar(63) ,"row_id" varchar(64) ,` +
+		`"operation" varchar(10) ,"old_values" jsonb ,"new_values" jsonb ,"actor" varchar(255) ,"created_at" timestamp );`
+	if statement != expected {
+		t.Errorf("incorrect audit log create statement - %s", statement)
+	}
+}
+
+type TestTenantItem struct {
+	ID       int64
+	TenantID int64  `liteorm:"tenant"`
+	Name     string `pglen:"100"`
+}
+
+var TestTenantItemType reflect.Type = reflect.TypeOf((*TestTenantItem)(nil)).Elem()
+
+func TestTenantStatements(t *testing.T) {
+	scope := &tenantScope{tenant: "o'brien", ok: true}
+	tenantIdx, args := tenantArgs(TestTenantItemType, scope, []any{1})
+	if tenantIdx != 2 || len(args) != 2 || args[1] != "o'brien" {
+		t.Errorf("incorrect tenant arguments - %d %v", tenantIdx, args)
+	}
+
+	selectStatement := buildSelectStatement(TestTenantItemType, "where id = $1", false, tenantIdx, DefaultNaming{})
+	expected := `select "id","tenant_id","name" from (select * from "testtenantitems" where "tenant_id" = $2) ` +
+		`"testtenantitems" where id = $1;`
+	if selectStatement != expected {
+		t.Errorf("incorrect select statement - %s", selectStatement)
+	}
+
+	deleteStatement := buildScopedDeleteStatement(TestTenantItemType, "where name = $1", false, tenantIdx,
+		DefaultNaming{}, PostgreSQL{})
+	expected = `delete from "testtenantitems" where "id" in (select "id" from (select * from "testtenantitems" where ` +
+		`"tenant_id" = $2) "testtenantitems" where name = $1);`
+	if deleteStatement != expected {
+		t.Errorf("incorrect delete statement - %s", deleteStatement)
+	}
+
+	updateStatement, values, err := buildUpdateOneStatement(&TestTenantItem{ID: 1, Name: "name"}, []string{"Name"},
+		scope, DefaultNaming{}, PostgreSQL{})
+	expected = `update "testtenantitems" set "name" = $3 where "id" = $1 and "tenant_id" = $2;`
+	if err != nil || updateStatement != expected {
+		t.Errorf("incorrect update statement - %s", updateStatement)
+	}
+	if len(values) != 3 || values[1] != "o'brien" || values[2] != "name" {
+		t.Errorf("incorrect update values - %v", values)
+	}
+
+	queryStatement, args := NewQuery().Where("name in (?)", []string{"a", "b"}).buildStatement(TestTenantItemType, scope,
+		func(clauses string, tenantIdx int) string {
+			return buildCountStatement(TestTenantItemType, clauses, false, tenantIdx, DefaultNaming{})
+		})
+	expected = `select count(*) from (select * from "testtenantitems" where "tenant_id" = $3) "testtenantitems" ` +
+		`where (name in ($1,$2));`
+	if queryStatement != expected || len(args) != 3 || args[2] != "o'brien" {
+		t.Errorf("incorrect query statement - %s %v", queryStatement, args)
+	}
+
+	// without a tenant, null is bound, which matches no row
+	tenantIdx, args = tenantArgs(TestTenantItemType, &tenantScope{}, nil)
+	if tenantIdx != 1 || len(args) != 1 || args[0] != nil {
+		t.Errorf("statement without tenant not restricted - %d %v", tenantIdx, args)
+	}
+
+	if tenantIdx, _ = tenantArgs(TestItemType, scope, nil); tenantIdx != 0 {
+		t.Errorf("statement of a type without tenant scoped")
+	}
+
+	object := &TestTenantItem{}
+	err = settings{tenant: &tenantScope{tenant: 42, ok: true}}.setTenant(object)
+	if err != nil || object.TenantID != 42 {
+		t.Errorf("tenant not set - %v", object)
+	}
+
+	if err = (settings{tenant: &tenantScope{tenant: int32(42), ok: true}}).setTenant(object); err != nil {
+		t.Errorf("object of the same tenant rejected - %s", err.Error())
+	}
+
+	if err = (settings{tenant: &tenantScope{tenant: 7, ok: true}}).setTenant(object); err == nil {
+		t.Errorf("object of another tenant accepted")
+	}
+
+	if err = (settings{tenant: &tenantScope{tenant: "42", ok: true}}).setTenant(object); err == nil {
+		t.Errorf("tenant of another kind accepted")
+	}
+
+	if err = (settings{tenant: &tenantScope{}}).setTenant(&TestTenantItem{}); err != ErrNoTenant {
+		t.Errorf("object inserted without tenant")
+	}
+}
+
+type TestEvent struct {
+	ID        int64     `pgsql:"not null"`
+	Name      string    `pglen:"100"`
+	CreatedAt time.Time `pgsql:"not null" partition:"range"`
+}
+
+var TestEventType reflect.Type = reflect.TypeOf((*TestEvent)(nil)).Elem()
+
+func TestPartitionStatements(t *testing.T) {
+	createStatement, err := buildCreateStatement(TestEventType, DefaultNaming{}, PostgreSQL{})
+	expected := `create table "testevents" ("id" bigserial not null,"name" varchar(100) ,"created_at" timestamp not null) ` +
+		`partition by range ("created_at");`
+	if err != nil || createStatement != expected {
+		t.Errorf("incorrect create statement - %s", createStatement)
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	statement, err := buildCreatePartitionStatement(TestEventType, from, from.AddDate(0, 1, 0), DefaultNaming{})
+	expected = `create table if not exists "testevents_20240101" partition of "testevents" for values from ` +
+		`('2024-01-01 00:00:00') to ('2024-02-01 00:00:00');`
+	if err != nil || statement != expected {
+		t.Errorf("incorrect create partition statement - %s", statement)
+	}
+
+	_, err = buildCreatePartitionStatement(TestItemType, from, from.AddDate(0, 1, 0), DefaultNaming{})
+	if err == nil {
+		t.Errorf("partition of a table that is not partitioned")
+	}
+
+	_, err = buildCreatePartitionStatement(TestEventType, from, from, DefaultNaming{})
+	if err == nil {
+		t.Errorf("empty partition")
+	}
+}
+
+type TestItemSummary struct {
+	IntColumn int `uniqueIndex:"testitemsummaries_int_column"`
+	Total     int64
+}
+
+func (TestItemSummary) TableName() string {
+	return "testitemsummaries"
+}
+
+func (TestItemSummary) MaterializedViewQuery() string {
+	return "select int_column, count(*) as total from testitems group by int_column"
+}
+
+var TestItemSummaryType reflect.Type = reflect.TypeOf((*TestItemSummary)(nil)).Elem()
+
+func TestMaterializedViewStatements(t *testing.T) {
+	query, _ := getMaterializedViewQuery(TestItemSummaryType)
+	createStatement := buildCreateMaterializedViewStatement(TestItemSummaryType, query, DefaultNaming{})
+	expected := `create materialized view if not exists "testitemsummaries" as select int_column, count(*) as total ` +
+		`from testitems group by int_column;`
+	if createStatement != expected {
+		t.Errorf("incorrect create materialized view statement - %s", createStatement)
+	}
+
+	refreshStatement := buildRefreshMaterializedViewStatement(TestItemSummaryType, true, DefaultNaming{})
+	if refreshStatement != `refresh materialized view concurrently "testitemsummaries";` {
+		t.Errorf("incorrect refresh materialized view statement - %s", refreshStatement)
+	}
+
+	dropStatement := buildDropTableStatement(TestItemSummaryType, true, false, DefaultNaming{})
+	if dropStatement != `drop materialized view if exists "testitemsummaries";` {
+		t.Errorf("incorrect drop materialized view statement - %s", dropStatement)
+	}
+
+	if checkWritable(TestItemSummaryType) != ErrReadOnly || checkWritable(TestItemType) != nil {
+		t.Errorf("incorrect writable check")
+	}
+}
+
+type TestItemView struct {
+	ID           int64
+	StringColumn string
+}
+
+func (TestItemView) TableName() string {
+	return "testitemviews"
+}
+
+func (TestItemView) ViewQuery() string {
+	return "select id, string_column from testitems where int_column > 0"
+}
+
+var TestItemViewType reflect.Type = reflect.TypeOf((*TestItemView)(nil)).Elem()
+
+func TestViewStatements(t *testing.T) {
+	query, _ := getViewQuery(TestItemViewType)
+	createStatement := buildCreateViewStatement(TestItemViewType, query, DefaultNaming{})
+	expected := `create or replace view "testitemviews" as select id, string_column from testitems where int_column > 0;`
+	if createStatement != expected {
+		t.Errorf("incorrect create view statement - %s", createStatement)
+	}
+
+	dropStatement := buildDropTableStatement(TestItemViewType, true, true, DefaultNaming{})
+	if dropStatement != `drop view if exists "testitemviews" cascade;` {
+		t.Errorf("incorrect drop view statement - %s", dropStatement)
+	}
+
+	if checkWritable(TestItemViewType) != ErrReadOnly {
+		t.Errorf("view is writable")
+	}
+}
+
+type TestRole struct {
+	ID   int64
+	Name string `pglen:"100"`
+}
+
+var TestRoleType reflect.Type = reflect.TypeOf((*TestRole)(nil)).Elem()
+
+type TestMember struct {
+	ID    int64
+	Name  string     `pglen:"100"`
+	Roles []TestRole `m2m:"test_member_roles"`
+}
+
+var TestMemberType reflect.Type = reflect.TypeOf((*TestMember)(nil)).Elem()
+
+func TestManyToManyStatements(t *testing.T) {
+	if isColumn(TestMemberType.Field(2)) {
+		t.Errorf("many-to-many relation field is a column")
+	}
+
+	table, err := findJoinTable(TestRoleType, TestMemberType, DefaultNaming{})
+	if err != nil {
+		t.Fatalf("could not find join table - %s", err.Error())
+	}
+
+	if table.name != "test_member_roles" || table.column != "test_role_id" || table.relatedColumn != "test_member_id" {
+		t.Errorf("incorrect join table - %v", table)
+	}
+
+	createStatement, err := buildCreateJoinTableStatement(table, "")
+	expected := `create table if not exists "test_member_roles" ("test_role_id" bigint not null, "test_member_id" ` +
+		`bigint not null, primary key ("test_role_id","test_member_id"));`
+	if err != nil || createStatement != expected {
+		t.Errorf("incorrect create join table statement - %s", createStatement)
+	}
+
+	// the join columns of string and UUID IDs have the types of the IDs, without their generation
+	columnType, err := buildJoinColumnType(TestKSUIDItemType)
+	if err != nil || columnType != "char(27)" {
+		t.Errorf("incorrect join column type of a KSUID ID - %s", columnType)
+	}
+
+	columnType, err = buildJoinColumnType(TestUUIDItemType)
+	if err != nil || columnType != "uuid" {
+		t.Errorf("incorrect join column type of a UUID ID - %s", columnType)
+	}
+
+	associateStatement := buildAssociateStatement(table, "app")
+	expected = `insert into "app"."test_member_roles" ("test_role_id","test_member_id") values ($1,$2) ` +
+		`on conflict do nothing;`
+	if associateStatement != expected {
+		t.Errorf("incorrect associate statement - %s", associateStatement)
+	}
+
+	dissociateStatement := buildDissociateStatement(table, "")
+	expected = `delete from "test_member_roles" where "test_role_id" = $1 and "test_member_id" = $2;`
+	if dissociateStatement != expected {
+		t.Errorf("incorrect dissociate statement - %s", dissociateStatement)
+	}
+
+	_, err = findJoinTable(TestRoleType, TestItemType, DefaultNaming{})
+	if err == nil {
+		t.Errorf("found join table of unrelated types")
+	}
+}
+
+func TestStatementTimeoutStatement(t *testing.T) {
+	statement := buildStatementTimeoutStatement(1500 * time.Microsecond)
+	if statement != "set local statement_timeout = 2;" {
+		t.Errorf("incorrect statement timeout statement - %s", statement)
+	}
+}
+
+func TestBatchStatement(t *testing.T) {
+	statement := buildBatchStatement(TestColumnItemType, "where name = $1", 1, 100, true, false, 0, DefaultNaming{})
+	expected := `select * from (select "item_id","email_address","name","deleted_at" from (select * from "testcolumnitems" where "deleted_at" is null) "testcolumnitems" where name = $1) batch order by "item_id" limit 100;`
+	if statement != expected {
+		t.Errorf("incorrect first batch statement - %s", statement)
+	}
+
+	statement = buildBatchStatement(TestColumnItemType, "where name = $1", 1, 100, false, false, 0, DefaultNaming{})
+	expected = `select * from (select "item_id","email_address","name","deleted_at" from (select * from "testcolumnitems" where "deleted_at" is null) "testcolumnitems" where name = $1) batch where "item_id" > $2 order by "item_id" limit 100;`
+	if statement != expected {
+		t.Errorf("incorrect batch statement - %s", statement)
+	}
+}
+
+func TestPaginateStatements(t *testing.T) {
+	statement := buildPaginateStatement(TestColumnItemType, "order by name", 20, 40, true, 0, DefaultNaming{})
+	expected := `select "item_id","email_address","name","deleted_at",count(*) over () from "testcolumnitems" order by name limit 20 offset 40;`
+	if statement != expected {
+		t.Errorf("incorrect paginate statement - %s", statement)
+	}
+
+	statement = buildPaginateCountStatement(TestColumnItemType, "order by name", true, 0, DefaultNaming{})
+	expected = `select count(*) from (select 1 from "testcolumnitems" order by name) page;`
+	if statement != expected {
+		t.Errorf("incorrect paginate count statement - %s", statement)
+	}
+}
+
+func TestFirstClauses(t *testing.T) {
+	clauses := buildFirstClauses(TestColumnItemType, "where name = $1", false, DefaultNaming{})
+	if clauses != `where name = $1 order by "item_id" asc limit 1` {
+		t.Errorf("incorrect first clauses - %s", clauses)
+	}
+
+	clauses = buildFirstClauses(TestColumnItemType, "", true, DefaultNaming{})
+	if clauses != ` order by "item_id" desc limit 1` {
+		t.Errorf("incorrect last clauses - %s", clauses)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	clauses, args := Expand("where status in (?) and owner_id = ? and name <> '?'", []string{"new", "open"}, 7)
+	if clauses != "where status in ($1,$2) and owner_id = $3 and name <> '?'" ||
+		!reflect.DeepEqual(args, []any{"new", "open", 7}) {
+		t.Errorf("incorrect expanded clauses - %s, %v", clauses, args)
+	}
+
+	clauses, args = Expand("where id = any(?) and data = ? and id not in ( ? )", []int64{1, 2}, []byte("x"), []int64{})
+	if clauses != "where id = any($1) and data = $2 and id not in ( null )" ||
+		!reflect.DeepEqual(args, []any{[]int64{1, 2}, []byte("x")}) {
+		t.Errorf("incorrect expanded clauses - %s, %v", clauses, args)
+	}
+
+	clauses, args = NewQuery().Where("status IN (?)", []string{"a", "b"}).Where("owner_id = ?", 7).Build()
+	if clauses != "where (status IN ($1,$2)) and (owner_id = $3)" || !reflect.DeepEqual(args, []any{"a", "b", 7}) {
+		t.Errorf("incorrect expanded query - %s, %v", clauses, args)
+	}
+}
+
+func TestExpandClauses(t *testing.T) {
+	clauses, args := expandClauses("where status in (?) and owner_id = ?", []any{[]string{"new", "open"}, 7})
+	if clauses != "where status in ($1,$2) and owner_id = $3" || !reflect.DeepEqual(args, []any{"new", "open", 7}) {
+		t.Errorf("incorrect expanded clauses - %s, %v", clauses, args)
+	}
+
+	// numbered placeholders and clauses without arguments leave the ? operators of jsonb untouched
+	for _, test := range []struct {
+		clauses string
+		args    []any
+	}{
+		{"where data ? $1 and id = $2", []any{"key", 1}},
+		{"where data ? 'key'", nil},
+		{"where name = '?' and id = $1", []any{1}},
+	} {
+		clauses, args := expandClauses(test.clauses, test.args)
+		if clauses != test.clauses || !reflect.DeepEqual(args, test.args) {
+			t.Errorf("clauses were expanded - %s, %v", clauses, args)
+		}
+	}
+}
+
+func TestLockClauses(t *testing.T) {
+	clauses := buildLockClauses("where status = $1 order by id limit 10", ForUpdateSkipLocked)
+	if clauses != "where status = $1 order by id limit 10 for update skip locked" {
+		t.Errorf("incorrect lock clauses - %s", clauses)
+	}
+
+	clauses, _ = NewQuery().Where("status = ?", "pending").OrderBy("id").Limit(1).Lock(ForShare).Build()
+	if clauses != "where (status = $1) order by id limit 1 for share" {
+		t.Errorf("incorrect locked query - %s", clauses)
+	}
+}
+
+type TestJob struct {
+	ID        int64
+	Recipient string `pglen:"100"`
+	JobState
+}
+
+var TestJobType reflect.Type = reflect.TypeOf((*TestJob)(nil)).Elem()
+
+func TestQueueStatements(t *testing.T) {
+	statement := buildDequeueStatement(TestJobType, 30*time.Second, DefaultNaming{}, PostgreSQL{})
+	expected := `update "testjobs" set "status" = 'running', "attempts" = "attempts" + 1, "visible_at" = (now() at time zone 'utc') + interval '30000 milliseconds' where "id" in (select "id" from "testjobs" where "status" in ('pending','running') and "visible_at" <= (now() at time zone 'utc') order by "visible_at", "id" limit $1 for update skip locked) returning "id","recipient","status","attempts","visible_at","last_error";`
+	if statement != expected {
+		t.Errorf("incorrect dequeue statement - %s", statement)
+	}
+
+	statement = buildFinishJobStatement(TestJobType, DefaultNaming{}, PostgreSQL{})
+	expected = `update "testjobs" set "status" = case when not $3 then 'done' when $4 > 0 and "attempts" >= $4 then 'failed' else 'pending' end, "visible_at" = (now() at time zone 'utc'), "last_error" = $5 where "id" = $1 and "attempts" = $2 and "status" = 'running' returning "status";`
+	if statement != expected {
+		t.Errorf("incorrect finish job statement - %s", statement)
+	}
+
+	statement = buildFinishJobStatement(TestJobType, DefaultNaming{}, SQLite{})
+	if !strings.Contains(statement, `"visible_at" = strftime('%Y-%m-%d %H:%M:%f', 'now')`) {
+		t.Errorf("finish job statement not using the dialect - %s", statement)
+	}
+
+	_, err := buildCreateStatement(TestJobType, DefaultNaming{}, PostgreSQL{})
+	if err != nil {
+		t.Errorf("could not build job create statement - %s", err.Error())
+	}
+
+	err = checkJobType(TestItemType)
+	if err == nil {
+		t.Errorf("accepted job type without JobState")
+	}
+}
+
+func TestDropAndTruncateStatements(t *testing.T) {
+	naming := DefaultNaming{}
+	if statement := buildDropTableStatement(TestItemType, true, false, naming); statement != `drop table if exists "testitems";` {
+		t.Errorf("incorrect drop table statement - %s", statement)
+	}
+
+	if statement := buildDropTableStatement(TestItemType, false, true, naming); statement != `drop table "testitems" cascade;` {
+		t.Errorf("incorrect cascading drop table statement - %s", statement)
+	}
+
+	statement := buildTruncateStatement(TestItemType, true, true, naming)
+	if statement != `truncate table "testitems" restart identity cascade;` {
+		t.Errorf("incorrect truncate statement - %s", statement)
+	}
+}
+
+func TestSQLiteDialect(t *testing.T) {
+	createStatement, err := buildCreateStatement(TestItemType, DefaultNaming{}, SQLite{})
+	if err != nil {
+		t.Fatalf("could not build create statement - %s", err.Error())
+	}
+
+	expected := `create table "testitems" ("id" integer primary key,"string_column" varchar(25) ,"int_column" integer ,"time_column" timestamp ,"blob_column" blob ,"float32_column" real ,"float64_column" real );`
+	if createStatement != expected {
+		t.Errorf("incorrect create statement - %s", createStatement)
+	}
+
+	statement := rebind(`select * from "items" where name = $1 and note <> '$2' and id in ($2, $10);`, SQLite{})
+	expected = `select * from "items" where name = ?1 and note <> '$2' and id in (?2, ?10);`
+	if statement != expected {
+		t.Errorf("incorrect rebound statement - %s", statement)
+	}
+
+	statement = buildScopedDeleteStatement(TestColumnItemType, "where name = $1", false, 0, DefaultNaming{}, SQLite{})
+	expected = `update "testcolumnitems" set "deleted_at" = strftime('%Y-%m-%d %H:%M:%f', 'now') where "item_id" in (select "item_id" from (select * from "testcolumnitems" where "deleted_at" is null) "testcolumnitems" where name = $1);`
+	if statement != expected {
+		t.Errorf("incorrect soft delete statement - %s", statement)
+	}
+
+	statement = rebind(statement, SQLite{})
+	expected = `update "testcolumnitems" set "deleted_at" = strftime('%Y-%m-%d %H:%M:%f', 'now') where "item_id" in (select "item_id" from (select * from "testcolumnitems" where "deleted_at" is null) "testcolumnitems" where name = ?1);`
+	if statement != expected {
+		t.Errorf("incorrect rebound soft delete statement - %s", statement)
+	}
+
+	statement = rebind(buildInsertStatement(TestItemType, DefaultNaming{}, SQLite{}), SQLite{})
+	if strings.Contains(statement, "$") || !strings.Contains(statement, "?1") {
+		t.Errorf("incorrect rebound insert statement - %s", statement)
+	}
+
+	recorder := &batchRecordingExecutor{}
+	batch := &pgx.Batch{}
+	batch.Queue(`insert into "items" ("name") values ($1);`, "name")
+	(&dialectExecutor{executor: recorder, dialect: SQLite{}}).SendBatch(context.Background(), batch)
+	if recorder.batch == nil || recorder.batch.QueuedQueries[0].SQL != `insert into "items" ("name") values (?1);` ||
+		recorder.batch.QueuedQueries[0].Arguments[0] != "name" {
+		t.Errorf("statements of batch not rebound")
+	}
+	if batch.QueuedQueries[0].SQL != `insert into "items" ("name") values ($1);` {
+		t.Errorf("batch of the caller modified - %s", batch.QueuedQueries[0].SQL)
+	}
+}
+
+// batchRecordingExecutor records the batch it is sent.
+type batchRecordingExecutor struct {
+	basicExecutor
+	batch *pgx.Batch
+}
+
+func (e *batchRecordingExecutor) SendBatch(_ context.Context, b *pgx.Batch) pgx.BatchResults {
+	e.batch = b
+	return errorBatchResults{err: errUnsupportedByExecutor}
+}
@@ -0,0 +1,212 @@
+package liteorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// NewDatabaseFromSQL returns a database whose statements run on a *sql.DB, as an alternative to pgx for code that relies
+// on database/sql middleware. The driver must connect to PostgreSQL, e.g. github.com/jackc/pgx/v5/stdlib or lib/pq,
+// and scan the column types of the models, which excludes arrays for most drivers. Batches and copies are not
+// supported, and the *sql.DB is not closed by Close. The database is configured with the options, e.g.
+// UseDialect(SQLite{}).
+func NewDatabaseFromSQL(sqlDB *sql.DB, options ...Option) *Database {
+	return NewDatabaseFromExecutor(&sqlConn{sqlExecutor: sqlExecutor{queryer: sqlDB}, db: sqlDB}, options...)
+}
+
+// sqlQueryer is implemented by both *sql.DB and *sql.Tx.
+type sqlQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sqlExecutor adapts a *sql.DB or *sql.Tx to the Executor interface.
+type sqlExecutor struct {
+	queryer sqlQueryer
+}
+
+func (e sqlExecutor) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	result, err := e.queryer.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	// database/sql does not expose the command tag, so it is rebuilt from the statement and the rows affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		rowsAffected = 0
+	}
+
+	command := ""
+	if words := strings.Fields(sql); len(words) > 0 {
+		command = strings.ToUpper(words[0])
+	}
+
+	return pgconn.NewCommandTag(fmt.Sprintf("%s %d", command, rowsAffected)), nil
+}
+
+func (e sqlExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := e.queryer.QueryContext(ctx, sql, args...)
+	if err != nil {
+		// like pgx, rows carrying the error are returned along with it, so that they can be closed either way
+		return errorRows{err: err}, err
+	}
+
+	return &sqlRows{Rows: rows}, nil
+}
+
+func (e sqlExecutor) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return sqlRow{Row: e.queryer.QueryRowContext(ctx, sql, args...)}
+}
+
+// sqlConn is the executor of a *sql.DB, which starts transactions on it.
+type sqlConn struct {
+	sqlExecutor
+	db *sql.DB
+}
+
+func (c *sqlConn) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlTx{sqlExecutor: sqlExecutor{queryer: tx}, tx: tx}, nil
+}
+
+// sqlTx adapts a *sql.Tx to the pgx.Tx interface. Like pgx, nested transactions are backed by savepoints.
+type sqlTx struct {
+	sqlExecutor
+	tx *sql.Tx
+
+	// savepoint is the name of the savepoint of a nested transaction, empty for the outermost transaction
+	savepoint string
+	depth     int
+}
+
+func (tx *sqlTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	savepoint := fmt.Sprintf("sp_%d", tx.depth+1)
+	_, err := tx.tx.ExecContext(ctx, fmt.Sprintf("savepoint %s;", savepoint))
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlTx{sqlExecutor: tx.sqlExecutor, tx: tx.tx, savepoint: savepoint, depth: tx.depth + 1}, nil
+}
+
+func (tx *sqlTx) Commit(ctx context.Context) error {
+	if tx.savepoint == "" {
+		return tx.tx.Commit()
+	}
+
+	_, err := tx.tx.ExecContext(ctx, fmt.Sprintf("release savepoint %s;", tx.savepoint))
+	return err
+}
+
+func (tx *sqlTx) Rollback(ctx context.Context) error {
+	if tx.savepoint == "" {
+		return tx.tx.Rollback()
+	}
+
+	_, err := tx.tx.ExecContext(ctx, fmt.Sprintf("rollback to savepoint %s;", tx.savepoint))
+	return err
+}
+
+func (tx *sqlTx) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, errUnsupportedByExecutor
+}
+
+func (tx *sqlTx) SendBatch(context.Context, *pgx.Batch) pgx.BatchResults {
+	return errorBatchResults{err: errUnsupportedByExecutor}
+}
+
+// LargeObjects panics with errUnsupportedByExecutor, since large objects are not supported by database/sql and the
+// pgx.Tx interface leaves no way to return an error, while the zero pgx.LargeObjects would panic on its first use.
+func (tx *sqlTx) LargeObjects() pgx.LargeObjects {
+	panic(errors.Wrap(errUnsupportedByExecutor, "large objects are not supported by database/sql"))
+}
+
+func (tx *sqlTx) Prepare(context.Context, string, string) (*pgconn.StatementDescription, error) {
+	return nil, errUnsupportedByExecutor
+}
+
+func (tx *sqlTx) Conn() *pgx.Conn {
+	return nil
+}
+
+// sqlRows adapts *sql.Rows to the pgx.Rows interface.
+type sqlRows struct {
+	*sql.Rows
+}
+
+func (r *sqlRows) Close() {
+	if r.Rows != nil {
+		r.Rows.Close()
+	}
+}
+
+func (r *sqlRows) CommandTag() pgconn.CommandTag {
+	return pgconn.CommandTag{}
+}
+
+func (r *sqlRows) FieldDescriptions() []pgconn.FieldDescription {
+	columns, err := r.Columns()
+	if err != nil {
+		return nil
+	}
+
+	descriptions := make([]pgconn.FieldDescription, len(columns))
+	for i, column := range columns {
+		descriptions[i].Name = column
+	}
+
+	return descriptions
+}
+
+func (r *sqlRows) Values() ([]any, error) {
+	columns, err := r.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	err = r.Rows.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (r *sqlRows) RawValues() [][]byte {
+	return nil
+}
+
+func (r *sqlRows) Conn() *pgx.Conn {
+	return nil
+}
+
+// sqlRow adapts *sql.Row to the pgx.Row interface, reporting a missing row with pgx.ErrNoRows.
+type sqlRow struct {
+	*sql.Row
+}
+
+func (r sqlRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pgx.ErrNoRows
+	}
+
+	return err
+}
@@ -0,0 +1,148 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// SelectEach selects the rows of the type received as argument matching the clauses like Select, but streams them
+// instead of returning a slice: fn is called with a pointer to a new object for each row, as it is read from the
+// database, so that result sets larger than the memory can be processed, e.g. exports. The iteration stops at the first
+// error of fn, which is returned as is. Unlike Select, SelectEach is not retried, since fn may have processed rows.
+//
+// The rows are read from the connection while fn runs, so fn must not run statements on the same connection: on a
+// database holding a single connection, e.g. created with NewDatabase, and within a transaction, they fail with a "conn
+// busy" error. Statements run on the database from fn only work if its executor is a pool, e.g. a *pgxpool.Pool; to
+// update the objects as they are read, use FindInBatches instead.
+func (db *Database) SelectEach(t reflect.Type, clauses string, fn func(object any) error, args ...any) error {
+	return db.SelectEachCtx(context.Background(), t, clauses, fn, args...)
+}
+
+func (db *Database) SelectEachCtx(ctx context.Context, t reflect.Type, clauses string, fn func(object any) error,
+	args ...any) error {
+	return selectEach(ctx, db.readSession(), t, clauses, fn, args...)
+}
+
+func (tx *Tx) SelectEach(t reflect.Type, clauses string, fn func(object any) error, args ...any) error {
+	return tx.SelectEachCtx(context.Background(), t, clauses, fn, args...)
+}
+
+func (tx *Tx) SelectEachCtx(ctx context.Context, t reflect.Type, clauses string, fn func(object any) error,
+	args ...any) error {
+	return selectEach(ctx, tx.session(), t, clauses, fn, args...)
+}
+
+func selectEach(ctx context.Context, s *session, t reflect.Type, clauses string, fn func(object any) error,
+	args ...any) (err error) {
+	errmsg := fmt.Sprintf("could not select objects of type %s", t.Name())
+	clauses, args = expandClauses(clauses, args)
+
+	ctx, span := s.startSpan(ctx, "select_each", s.namingStrategy().TableName(t))
+	defer func() { span.End(err) }()
+
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	rows, err := s.Query(ctx, s.cachedSelectStatement(t, clauses, tenantIdx), args...)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+	defer rows.Close()
+
+	plan := columnScanPlan(t)
+	columnValues := make([]any, len(plan.fields))
+	for rows.Next() {
+		object := reflect.New(t)
+		plan.destinations(object.Elem(), columnValues)
+		err = rows.Scan(columnValues...)
+		if err == nil {
+			err = plan.setConverted(s.settings, object.Elem(), columnValues)
+		}
+		if err == nil {
+			err = afterSelect(ctx, object.Interface())
+		}
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		err = fn(object.Interface())
+		if err != nil {
+			return err
+		}
+	}
+
+	if rows.Err() != nil {
+		return errors.Wrap(rows.Err(), errmsg)
+	}
+
+	return nil
+}
+
+// FindInBatches selects the rows of the type received as argument matching the clauses in batches of at most batchSize
+// objects, paging through them in the order of their IDs, and calls fn with each batch as a slice of objects, like
+// Select. Whole tables can thus be processed, e.g. by migrations and backfills, without holding them in memory. Since
+// each batch starts after the last ID of the previous one, rows inserted or updated by fn do not shift the batches. The
+// clauses may not order or limit the rows. The iteration stops at the first error of fn, which is returned as is.
+func (db *Database) FindInBatches(t reflect.Type, batchSize int, clauses string, fn func(batch any) error,
+	args ...any) error {
+	return db.FindInBatchesCtx(context.Background(), t, batchSize, clauses, fn, args...)
+}
+
+func (db *Database) FindInBatchesCtx(ctx context.Context, t reflect.Type, batchSize int, clauses string,
+	fn func(batch any) error, args ...any) error {
+	return findInBatches(ctx, db.readSession(), t, batchSize, clauses, fn, args...)
+}
+
+func (tx *Tx) FindInBatches(t reflect.Type, batchSize int, clauses string, fn func(batch any) error,
+	args ...any) error {
+	return tx.FindInBatchesCtx(context.Background(), t, batchSize, clauses, fn, args...)
+}
+
+func (tx *Tx) FindInBatchesCtx(ctx context.Context, t reflect.Type, batchSize int, clauses string,
+	fn func(batch any) error, args ...any) error {
+	return findInBatches(ctx, tx.session(), t, batchSize, clauses, fn, args...)
+}
+
+func findInBatches(ctx context.Context, s *session, t reflect.Type, batchSize int, clauses string,
+	fn func(batch any) error, args ...any) error {
+	errmsg := fmt.Sprintf("could not select batches of objects of type %s", t.Name())
+	clauses, args = expandClauses(clauses, args)
+	if batchSize <= 0 {
+		return errors.Wrap(errors.New("the batch size must be positive"), errmsg)
+	}
+
+	if _, ok := t.FieldByName("ID"); !ok {
+		return errors.Wrap(errors.New("the type does not have an ID field"), errmsg)
+	}
+
+	plan := columnScanPlan(t)
+	tenantIdx, args := tenantArgs(t, s.tenant, args)
+	statement := buildBatchStatement(t, clauses, len(args), batchSize, true, s.unscoped, tenantIdx, s.namingStrategy())
+	batchArgs := args
+	for {
+		batch, err := selectStatement(ctx, s, t, plan, statement, batchArgs...)
+		if err != nil {
+			return errors.Wrap(err, errmsg)
+		}
+
+		batchv := reflect.ValueOf(batch)
+		if batchv.Len() == 0 {
+			return nil
+		}
+
+		err = fn(batch)
+		if err != nil {
+			return err
+		}
+
+		if batchv.Len() < batchSize {
+			return nil
+		}
+
+		// the following batches start after the last ID of the batch
+		lastID := batchv.Index(batchv.Len() - 1).FieldByName("ID").Interface()
+		statement = buildBatchStatement(t, clauses, len(args), batchSize, false, s.unscoped, tenantIdx,
+			s.namingStrategy())
+		batchArgs = append(append(make([]any, 0, len(args)+1), args...), lastID)
+	}
+}
@@ -0,0 +1,134 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// ErrNoTenant is returned when an object of a tenant type is inserted with a database scoped by a context without a
+// tenant, see Database.Scoped.
+var ErrNoTenant = errors.New("no tenant in context")
+
+// tenantKey is the context key of the tenant of the scoped operations.
+type tenantKey struct{}
+
+// WithTenant returns a copy of the context holding the tenant received as argument, e.g. the ID of the organization of
+// the user making the request, which scopes the operations of the databases returned by Scoped.
+func WithTenant(ctx context.Context, tenant any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Scoped returns a copy of the database whose operations on tenant types only see and change the rows of the tenant of
+// the context, set with WithTenant. Tenant types have a field with the "tenant" option in its liteorm tag, e.g.
+//
+//	TenantID int64 `liteorm:"tenant"`
+//
+// Selects, counts, aggregates, updates and deletes are restricted to the rows whose tenant column holds the tenant,
+// inserted objects get the tenant unless their field is already set, and inserting an object of another tenant fails.
+// If the context has no tenant, no rows match and inserts fail with ErrNoTenant. Rows joined to the selected ones and
+// raw statements are not scoped.
+func (db *Database) Scoped(ctx context.Context) *Database {
+	scoped := *db
+	scoped.tenant = newTenantScope(ctx)
+	return &scoped
+}
+
+// Scoped returns a copy of the transaction whose operations are scoped by the tenant of the context, see
+// Database.Scoped.
+func (tx *Tx) Scoped(ctx context.Context) *Tx {
+	scoped := *tx
+	scoped.tenant = newTenantScope(ctx)
+	return &scoped
+}
+
+// tenantScope is the tenant of the scoped operations, if the context they were scoped by had one.
+type tenantScope struct {
+	tenant any
+	ok     bool
+}
+
+func newTenantScope(ctx context.Context) *tenantScope {
+	tenant := ctx.Value(tenantKey{})
+	return &tenantScope{tenant: tenant, ok: tenant != nil}
+}
+
+// getTenantField returns the field of the tenant column of a type, i.e. the one with the "tenant" option.
+func getTenantField(argt reflect.Type) (reflect.StructField, bool) {
+	for _, field := range columnFields(argt) {
+		if _, ok := parseTag(field)["tenant"]; ok {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// tenantArgs returns the arguments of a statement on the type received as argument followed, for tenant types if the
+// scope is set, by the tenant of the scope, along with the index of the placeholder of the tenant, which is zero if the
+// statement is not scoped. If the scope has no tenant, null is bound, which matches no row.
+func tenantArgs(argt reflect.Type, scope *tenantScope, args []any) (int, []any) {
+	if scope == nil {
+		return 0, args
+	}
+
+	if _, ok := getTenantField(argt); !ok {
+		return 0, args
+	}
+
+	return len(args) + 1, append(args[:len(args):len(args)], scope.tenant)
+}
+
+// buildTenantCondition builds the condition matching the rows of the tenant of scoped operations, which is bound to the
+// placeholder of index tenantIdx, see tenantArgs.
+func buildTenantCondition(argt reflect.Type, tenantIdx int, naming NamingStrategy) string {
+	field, _ := getTenantField(argt)
+	return fmt.Sprintf("%s = $%d", quoteIdentifier(columnName(field, naming)), tenantIdx)
+}
+
+// setTenant sets the tenant field of an object about to be inserted by a scoped operation to the tenant of the scope,
+// unless it is already set to the same tenant.
+func (s settings) setTenant(arg any) error {
+	argt, err := getObjectType(arg)
+	if err != nil {
+		return err
+	}
+
+	field, ok := getTenantField(argt)
+	if s.tenant == nil || !ok {
+		return nil
+	}
+
+	if !s.tenant.ok {
+		return ErrNoTenant
+	}
+
+	argv, err := getObjectValue(arg)
+	if err != nil {
+		return err
+	}
+
+	// numbers are convertible to strings, but as runes, so tenants can only be converted between the same kinds
+	tenant := reflect.ValueOf(s.tenant.tenant)
+	if !tenant.Type().ConvertibleTo(field.Type) ||
+		(tenant.Kind() == reflect.String) != (field.Type.Kind() == reflect.String) {
+		return errors.New(fmt.Sprintf("could not set tenant %v on field %s", s.tenant.tenant, field.Name))
+	}
+	tenant = tenant.Convert(field.Type)
+
+	fieldv := argv.FieldByIndex(field.Index)
+	if !fieldv.IsZero() {
+		if !reflect.DeepEqual(fieldv.Interface(), tenant.Interface()) {
+			return errors.New(fmt.Sprintf("object belongs to tenant %v, not %v", fieldv.Interface(), s.tenant.tenant))
+		}
+		return nil
+	}
+
+	if !argv.CanSet() {
+		return errors.New(fmt.Sprintf("could not set tenant %v on field %s", s.tenant.tenant, field.Name))
+	}
+
+	fieldv.Set(tenant)
+	return nil
+}
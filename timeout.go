@@ -0,0 +1,152 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"time"
+)
+
+// WithTimeout returns a copy of the database whose statements each run with a deadline of the duration received as
+// argument, after which they are canceled, unless the context of the operation has an earlier deadline. Transactions
+// started from the copy use the same timeout. The timeout of single operations is overridden with the WithTimeout
+// function.
+func (db *Database) WithTimeout(timeout time.Duration) *Database {
+	limited := *db
+	limited.timeout = timeout
+	return &limited
+}
+
+// WithTimeout returns a copy of the transaction whose statements each run with a deadline, see Database.WithTimeout.
+func (tx *Tx) WithTimeout(timeout time.Duration) *Tx {
+	limited := *tx
+	limited.timeout = timeout
+	return &limited
+}
+
+// WithStatementTimeout returns a copy of the database whose transactions set the statement_timeout of PostgreSQL to
+// the duration received as argument, so that the database itself cancels the statements running longer, even if the
+// client is gone. The setting is local to the transactions and does not apply to the statements run outside of them.
+func (db *Database) WithStatementTimeout(timeout time.Duration) *Database {
+	limited := *db
+	limited.statementTimeout = timeout
+	return &limited
+}
+
+// setStatementTimeout sets the statement_timeout of the transaction received as argument, if the timeout is set.
+func setStatementTimeout(ctx context.Context, tx pgx.Tx, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, buildStatementTimeoutStatement(timeout))
+	return err
+}
+
+// buildStatementTimeoutStatement builds the statement setting the statement_timeout of a transaction, in milliseconds
+// rounded up so that short timeouts are not disabled.
+func buildStatementTimeoutStatement(timeout time.Duration) string {
+	milliseconds := (timeout + time.Millisecond - 1) / time.Millisecond
+	return fmt.Sprintf("set local statement_timeout = %d;", milliseconds)
+}
+
+// timeoutKey is the context key of the timeout of the statements of an operation.
+type timeoutKey struct{}
+
+// WithTimeout returns a copy of the context whose operations run each of their statements with a deadline of the
+// duration received as argument, overriding the timeout of the database, see Database.WithTimeout. Unlike with
+// context.WithTimeout, the deadline starts with each statement rather than with the operation.
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// timeoutExecutor wraps the executor of a session to run each statement with a deadline. The deadline of queries is
+// released once their rows are closed or scanned.
+type timeoutExecutor struct {
+	executor
+	timeout time.Duration
+}
+
+// withTimeout returns a context with the deadline of a statement, i.e. the timeout of the context or of the executor,
+// along with the function that releases it.
+func (e *timeoutExecutor) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := e.timeout
+	if ctxTimeout, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		timeout = ctxTimeout
+	}
+
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+func (e *timeoutExecutor) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := e.withTimeout(ctx)
+	defer cancel()
+	return e.executor.Exec(ctx, sql, args...)
+}
+
+func (e *timeoutExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, cancel := e.withTimeout(ctx)
+	rows, err := e.executor.Query(ctx, sql, args...)
+	if rows == nil {
+		cancel()
+		return nil, err
+	}
+
+	// like pgx, rows are returned along with the error, and the deadline is released once they are closed
+	return &timeoutRows{Rows: rows, cancel: cancel}, err
+}
+
+func (e *timeoutExecutor) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, cancel := e.withTimeout(ctx)
+	return &timeoutRow{Row: e.executor.QueryRow(ctx, sql, args...), cancel: cancel}
+}
+
+func (e *timeoutExecutor) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	ctx, cancel := e.withTimeout(ctx)
+	return &timeoutBatchResults{BatchResults: e.executor.SendBatch(ctx, b), cancel: cancel}
+}
+
+func (e *timeoutExecutor) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string,
+	rowSrc pgx.CopyFromSource) (int64, error) {
+	ctx, cancel := e.withTimeout(ctx)
+	defer cancel()
+	return e.executor.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+// timeoutRows releases the deadline of a query once its rows are closed.
+type timeoutRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// timeoutRow releases the deadline of a query once its row is scanned.
+type timeoutRow struct {
+	pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.Row.Scan(dest...)
+}
+
+// timeoutBatchResults releases the deadline of a batch once its results are closed.
+type timeoutBatchResults struct {
+	pgx.BatchResults
+	cancel context.CancelFunc
+}
+
+func (b *timeoutBatchResults) Close() error {
+	defer b.cancel()
+	return b.BatchResults.Close()
+}
@@ -0,0 +1,124 @@
+package liteorm
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WithDatabaseTimestamps returns a copy of the database that sets the CreatedAt and UpdatedAt fields with the clock of
+// the database rather than with the clock of the client. Inserted objects get the timestamps of their new row, but
+// updated objects are not read back, so their UpdatedAt field is left at its zero value after an update.
+func (db *Database) WithDatabaseTimestamps() *Database {
+	timestamped := *db
+	timestamped.databaseTimestamps = true
+	return &timestamped
+}
+
+// isTimestampField reports whether a field is a timestamp managed by liteorm, i.e. a CreatedAt or UpdatedAt field of
+// type time.Time that is not generated by the database. CreatedAt is set when the object is inserted, unless it is
+// already set, and UpdatedAt every time the object is inserted or updated.
+func isTimestampField(field reflect.StructField) bool {
+	return (field.Name == "CreatedAt" || field.Name == "UpdatedAt") && field.Type == timeType &&
+		!isGeneratedField(field)
+}
+
+// buildTimestampPlaceholder returns the expression of the value of a timestamp field in an insert or update statement.
+// Zero timestamps are passed as null, so that UpdatedAt, and CreatedAt on insert, fall back to the current time of the
+// database, while CreatedAt keeps the value of the row on update.
+func buildTimestampPlaceholder(field reflect.StructField, index int, updating bool, naming NamingStrategy,
+	dialect Dialect) string {
+	if updating && field.Name == "CreatedAt" {
+		return fmt.Sprintf("coalesce($%d, %s)", index, quoteIdentifier(columnName(field, naming)))
+	}
+
+	return fmt.Sprintf("coalesce($%d, %s)", index, dialect.CurrentTimestamp())
+}
+
+// setTimestamps sets the timestamp fields of an object before it is inserted or updated. With database timestamps,
+// UpdatedAt is reset instead, so that the statement sets it to the current time of the database.
+func (s settings) setTimestamps(arg any, inserting bool) error {
+	if !s.databaseTimestamps {
+		return setClientTimestamps(arg, inserting)
+	}
+
+	argv, err := getObjectValue(arg)
+	if err != nil || !argv.CanSet() {
+		// objects passed by value cannot be set, and their zero timestamps are set by the database instead
+		return err
+	}
+
+	for _, field := range columnFields(argv.Type()) {
+		if isTimestampField(field) && field.Name == "UpdatedAt" {
+			argv.FieldByIndex(field.Index).Set(reflect.Zero(timeType))
+		}
+	}
+
+	return nil
+}
+
+// setClientTimestamps sets the timestamp fields of an object to the current time of the client, in UTC and with the
+// precision of the timestamp columns.
+func setClientTimestamps(arg any, inserting bool) error {
+	argv, err := getObjectValue(arg)
+	if err != nil || !argv.CanSet() {
+		// objects passed by value cannot be set, and their zero timestamps are set by the database instead
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	for _, field := range columnFields(argv.Type()) {
+		if !isTimestampField(field) {
+			continue
+		}
+
+		fieldv := argv.FieldByIndex(field.Index)
+		if field.Name == "UpdatedAt" || (inserting && fieldv.Interface().(time.Time).IsZero()) {
+			fieldv.Set(reflect.ValueOf(now))
+		}
+	}
+
+	return nil
+}
+
+// isNamedTimeField reports whether a field holds a named type declared over time.Time, e.g. type Timestamp time.Time,
+// or a pointer to one. Such fields are stored as timestamps, but pgx only encodes and scans time.Time itself, so their
+// values are converted from and to time.Time.
+func isNamedTimeField(field reflect.StructField) bool {
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t != timeType && t.Kind() == reflect.Struct && t.ConvertibleTo(timeType)
+}
+
+// getNamedTimeValue returns the time.Time stored for a named time field. Nil pointers are stored as null.
+func getNamedTimeValue(fieldv reflect.Value) any {
+	if fieldv.Kind() == reflect.Ptr {
+		if fieldv.IsNil() {
+			return nil
+		}
+		fieldv = fieldv.Elem()
+	}
+
+	return fieldv.Convert(timeType).Interface()
+}
+
+// setNamedTimeValue sets a named time field from the time scanned from its column. Null columns leave the field at its
+// zero value.
+func setNamedTimeValue(fieldv reflect.Value, field reflect.StructField, value *time.Time) {
+	fieldv.Set(reflect.Zero(field.Type))
+	if value == nil {
+		return
+	}
+
+	if field.Type.Kind() == reflect.Ptr {
+		pointer := reflect.New(field.Type.Elem())
+		pointer.Elem().Set(reflect.ValueOf(*value).Convert(field.Type.Elem()))
+		fieldv.Set(pointer)
+		return
+	}
+
+	fieldv.Set(reflect.ValueOf(*value).Convert(field.Type))
+}
@@ -0,0 +1,99 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"time"
+)
+
+// Tracer starts a span around the operations of liteorm, i.e. Insert, SelectOne, Select, UpdateOne, Delete and
+// CreateTable, along with the variants built on them. It is the integration point for tracing libraries such as
+// OpenTelemetry, whose tracer is adapted by starting a span named after the operation with the table as attribute:
+//
+//	func (t otelTracer) StartSpan(ctx context.Context, operation, tableName string) (context.Context, liteorm.Span) {
+//		ctx, span := t.tracer.Start(ctx, "liteorm."+operation, trace.WithSpanKind(trace.SpanKindClient),
+//			trace.WithAttributes(attribute.String("db.sql.table", tableName)))
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	StartSpan(ctx context.Context, operation string, tableName string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer. It receives the statements run within the operation and the rows they affected,
+// and is ended with the error of the operation, if any.
+type Span interface {
+	SetStatement(sql string)
+	SetRowsAffected(rows int64)
+	End(err error)
+}
+
+// WithTracer returns a copy of the database whose operations are traced with the tracer received as argument.
+// Transactions started from the copy use the same tracer.
+func (db *Database) WithTracer(tracer Tracer) *Database {
+	traced := *db
+	traced.tracer = tracer
+	return &traced
+}
+
+// spanKey is the context key of the span of the operation being run.
+type spanKey struct{}
+
+// startSpan starts the span of an operation on the table received as argument with the tracer of the settings, and
+// passes the operation to the metrics of the settings once the span ends. If neither is set, the span does nothing.
+func (s settings) startSpan(ctx context.Context, operation string, tableName string) (context.Context, Span) {
+	var span Span = noopSpan{}
+	if s.tracer != nil {
+		ctx, span = s.tracer.StartSpan(ctx, operation, tableName)
+		ctx = context.WithValue(ctx, spanKey{}, span)
+	}
+
+	if s.metrics != nil {
+		span = &metricsSpan{Span: span, metrics: s.metrics, operation: operation, tableName: tableName,
+			start: time.Now()}
+	}
+
+	return ctx, span
+}
+
+// spanFromContext returns the span of the operation being run, if any.
+func spanFromContext(ctx context.Context) (Span, bool) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	return span, ok
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetStatement(string)   {}
+func (noopSpan) SetRowsAffected(int64) {}
+func (noopSpan) End(error)             {}
+
+// tracingExecutor wraps the executor of a session to pass the statements it runs to the span of the operation.
+type tracingExecutor struct {
+	executor
+}
+
+func (e *tracingExecutor) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	commandTag, err := e.executor.Exec(ctx, sql, args...)
+	if span, ok := spanFromContext(ctx); ok {
+		span.SetStatement(sql)
+		if err == nil {
+			span.SetRowsAffected(commandTag.RowsAffected())
+		}
+	}
+	return commandTag, err
+}
+
+func (e *tracingExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if span, ok := spanFromContext(ctx); ok {
+		span.SetStatement(sql)
+	}
+	return e.executor.Query(ctx, sql, args...)
+}
+
+func (e *tracingExecutor) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if span, ok := spanFromContext(ctx); ok {
+		span.SetStatement(sql)
+	}
+	return e.executor.QueryRow(ctx, sql, args...)
+}
@@ -0,0 +1,50 @@
+package liteorm
+
+import (
+	"context"
+)
+
+// RunInTransaction runs the function received as argument within a transaction, see RunInTransactionCtx.
+func (db *Database) RunInTransaction(fn func(tx *Tx) error) error {
+	return db.RunInTransactionCtx(context.Background(), fn)
+}
+
+// RunInTransactionCtx runs the function received as argument within a transaction, which is committed if the function
+// returns nil and rolled back if it returns an error or panics. The error of the function is returned as is, and the
+// panic is propagated after the rollback.
+func (db *Database) RunInTransactionCtx(ctx context.Context, fn func(tx *Tx) error) error {
+	return runInTransaction(ctx, db.BeginCtx, fn)
+}
+
+// RunInTransaction runs the function received as argument within a nested transaction, like
+// Database.RunInTransaction.
+func (tx *Tx) RunInTransaction(fn func(tx *Tx) error) error {
+	return tx.RunInTransactionCtx(context.Background(), fn)
+}
+
+func (tx *Tx) RunInTransactionCtx(ctx context.Context, fn func(tx *Tx) error) error {
+	return runInTransaction(ctx, tx.BeginCtx, fn)
+}
+
+func runInTransaction(ctx context.Context, begin func(ctx context.Context) (*Tx, error), fn func(tx *Tx) error) error {
+	tx, err := begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.RollbackCtx(ctx)
+			panic(p)
+		}
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		// the error of the function is more relevant than that of the rollback
+		tx.RollbackCtx(ctx)
+		return err
+	}
+
+	return tx.CommitCtx(ctx)
+}
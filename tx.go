@@ -0,0 +1,328 @@
+package liteorm
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Tx is a database transaction started with Database.Begin. It exposes the same operations as Database, and all of them
+// run within the transaction until either Commit or Rollback is called.
+type Tx struct {
+	Tx pgx.Tx
+	settings
+}
+
+func (tx *Tx) session() *session {
+	return &session{executor: tx.wrapExecutor(tx.Tx), settings: tx.settings}
+}
+
+// Unscoped returns a copy of the transaction whose operations bypass soft delete, see Database.Unscoped.
+func (tx *Tx) Unscoped() *Tx {
+	unscoped := *tx
+	unscoped.unscoped = true
+	return &unscoped
+}
+
+// Begin starts a nested transaction within the transaction, backed by a savepoint. Committing the nested transaction
+// releases the savepoint, and rolling it back only undoes the statements run since it started, which allows code that
+// uses transactions to run within the transaction of its caller.
+func (tx *Tx) Begin() (*Tx, error) {
+	return tx.BeginCtx(context.Background())
+}
+
+func (tx *Tx) BeginCtx(ctx context.Context) (*Tx, error) {
+	nested, err := tx.Tx.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not begin nested transaction")
+	}
+
+	return &Tx{Tx: nested, settings: tx.settings}, nil
+}
+
+func (tx *Tx) Commit() error {
+	return tx.CommitCtx(context.Background())
+}
+
+func (tx *Tx) CommitCtx(ctx context.Context) error {
+	err := tx.Tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (tx *Tx) Rollback() error {
+	return tx.RollbackCtx(context.Background())
+}
+
+func (tx *Tx) RollbackCtx(ctx context.Context) error {
+	err := tx.Tx.Rollback(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not rollback transaction")
+	}
+
+	return nil
+}
+
+func (tx *Tx) DropTable(t reflect.Type, ifExists bool) error {
+	return tx.DropTableCtx(context.Background(), t, ifExists)
+}
+
+func (tx *Tx) DropTableCtx(ctx context.Context, t reflect.Type, ifExists bool) error {
+	return dropTable(ctx, tx.session(), t, ifExists)
+}
+
+func (tx *Tx) Truncate(t reflect.Type, restartIdentity bool, cascade bool) error {
+	return tx.TruncateCtx(context.Background(), t, restartIdentity, cascade)
+}
+
+func (tx *Tx) TruncateCtx(ctx context.Context, t reflect.Type, restartIdentity bool, cascade bool) error {
+	return truncate(ctx, tx.session(), t, restartIdentity, cascade)
+}
+
+func (tx *Tx) Insert(arg any) error {
+	return tx.InsertCtx(context.Background(), arg)
+}
+
+func (tx *Tx) InsertCtx(ctx context.Context, arg any) error {
+	return insert(ctx, tx.session(), arg)
+}
+
+func (tx *Tx) Upsert(arg any, conflictColumns ...string) error {
+	return tx.UpsertCtx(context.Background(), arg, conflictColumns...)
+}
+
+func (tx *Tx) UpsertCtx(ctx context.Context, arg any, conflictColumns ...string) error {
+	return upsert(ctx, tx.session(), arg, conflictColumns...)
+}
+
+func (tx *Tx) InsertMany(args any) error {
+	return tx.InsertManyCtx(context.Background(), args)
+}
+
+func (tx *Tx) InsertManyCtx(ctx context.Context, args any) error {
+	return insertMany(ctx, tx.session(), args)
+}
+
+func (tx *Tx) CopyFrom(args any) (int64, error) {
+	return tx.CopyFromCtx(context.Background(), args)
+}
+
+func (tx *Tx) CopyFromCtx(ctx context.Context, args any) (int64, error) {
+	return copyFrom(ctx, tx.session(), args)
+}
+
+func (tx *Tx) SelectOne(arg any, clauses string, args ...any) error {
+	return tx.SelectOneCtx(context.Background(), arg, clauses, args...)
+}
+
+func (tx *Tx) SelectOneCtx(ctx context.Context, arg any, clauses string, args ...any) error {
+	return selectOne(ctx, tx.session(), arg, clauses, args...)
+}
+
+func (tx *Tx) FindByID(arg any, id any) error {
+	return tx.FindByIDCtx(context.Background(), arg, id)
+}
+
+func (tx *Tx) FindByIDCtx(ctx context.Context, arg any, id any) error {
+	return findByID(ctx, tx.session(), arg, id)
+}
+
+func (tx *Tx) First(arg any, clauses string, args ...any) error {
+	return tx.FirstCtx(context.Background(), arg, clauses, args...)
+}
+
+func (tx *Tx) FirstCtx(ctx context.Context, arg any, clauses string, args ...any) error {
+	return selectFirst(ctx, tx.session(), arg, clauses, false, args...)
+}
+
+func (tx *Tx) Last(arg any, clauses string, args ...any) error {
+	return tx.LastCtx(context.Background(), arg, clauses, args...)
+}
+
+func (tx *Tx) LastCtx(ctx context.Context, arg any, clauses string, args ...any) error {
+	return selectFirst(ctx, tx.session(), arg, clauses, true, args...)
+}
+
+func (tx *Tx) Select(t reflect.Type, clauses string, args ...any) (any, error) {
+	return tx.SelectCtx(context.Background(), t, clauses, args...)
+}
+
+func (tx *Tx) SelectCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (any, error) {
+	return selectAll(ctx, tx.session(), t, clauses, args...)
+}
+
+func (tx *Tx) SelectQuery(t reflect.Type, q *Query) (any, error) {
+	return tx.SelectQueryCtx(context.Background(), t, q)
+}
+
+func (tx *Tx) SelectQueryCtx(ctx context.Context, t reflect.Type, q *Query) (any, error) {
+	return selectQuery(ctx, tx.session(), t, q)
+}
+
+func (tx *Tx) SelectColumns(t reflect.Type, columns []string, clauses string, args ...any) (any, error) {
+	return tx.SelectColumnsCtx(context.Background(), t, columns, clauses, args...)
+}
+
+func (tx *Tx) SelectColumnsCtx(ctx context.Context, t reflect.Type, columns []string, clauses string,
+	args ...any) (any, error) {
+	return selectColumns(ctx, tx.session(), t, columns, clauses, args...)
+}
+
+func (tx *Tx) Query(dest any, sql string, args ...any) error {
+	return tx.QueryCtx(context.Background(), dest, sql, args...)
+}
+
+func (tx *Tx) QueryCtx(ctx context.Context, dest any, sql string, args ...any) error {
+	return queryInto(ctx, tx.session(), dest, sql, args...)
+}
+
+func (tx *Tx) QueryMaps(sql string, args ...any) ([]map[string]any, error) {
+	return tx.QueryMapsCtx(context.Background(), sql, args...)
+}
+
+func (tx *Tx) QueryMapsCtx(ctx context.Context, sql string, args ...any) ([]map[string]any, error) {
+	return queryMaps(ctx, tx.session(), sql, args...)
+}
+
+func (tx *Tx) SelectWhere(t reflect.Type, condition Condition) (any, error) {
+	return tx.SelectWhereCtx(context.Background(), t, condition)
+}
+
+func (tx *Tx) SelectWhereCtx(ctx context.Context, t reflect.Type, condition Condition) (any, error) {
+	return tx.SelectQueryCtx(ctx, t, NewQuery().Filter(condition))
+}
+
+func (tx *Tx) SelectInto(dest any, t reflect.Type, expressions string, q *Query) error {
+	return tx.SelectIntoCtx(context.Background(), dest, t, expressions, q)
+}
+
+func (tx *Tx) SelectIntoCtx(ctx context.Context, dest any, t reflect.Type, expressions string, q *Query) error {
+	return selectInto(ctx, tx.session(), dest, t, expressions, q)
+}
+
+func (tx *Tx) Count(t reflect.Type, clauses string, args ...any) (int64, error) {
+	return tx.CountCtx(context.Background(), t, clauses, args...)
+}
+
+func (tx *Tx) CountCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error) {
+	return count(ctx, tx.session(), t, clauses, args...)
+}
+
+func (tx *Tx) Exists(t reflect.Type, clauses string, args ...any) (bool, error) {
+	return tx.ExistsCtx(context.Background(), t, clauses, args...)
+}
+
+func (tx *Tx) ExistsCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (bool, error) {
+	return exists(ctx, tx.session(), t, clauses, args...)
+}
+
+func (tx *Tx) Save(arg any) error {
+	return tx.SaveCtx(context.Background(), arg)
+}
+
+func (tx *Tx) SaveCtx(ctx context.Context, arg any) error {
+	return save(ctx, tx.session(), arg)
+}
+
+func (tx *Tx) UpdateOne(arg any) error {
+	return tx.UpdateOneCtx(context.Background(), arg)
+}
+
+func (tx *Tx) UpdateOneCtx(ctx context.Context, arg any) error {
+	return updateOne(ctx, tx.session(), arg)
+}
+
+func (tx *Tx) UpdateColumns(arg any, columns ...string) error {
+	return tx.UpdateColumnsCtx(context.Background(), arg, columns...)
+}
+
+func (tx *Tx) UpdateColumnsCtx(ctx context.Context, arg any, columns ...string) error {
+	return updateColumns(ctx, tx.session(), arg, columns...)
+}
+
+func (tx *Tx) UpdateMany(args any) error {
+	return tx.UpdateManyCtx(context.Background(), args)
+}
+
+func (tx *Tx) UpdateManyCtx(ctx context.Context, args any) error {
+	return updateMany(ctx, tx.session(), args)
+}
+
+func (tx *Tx) UpdateWhere(t reflect.Type, set map[string]any, clauses string, args ...any) (int64, error) {
+	return tx.UpdateWhereCtx(context.Background(), t, set, clauses, args...)
+}
+
+func (tx *Tx) UpdateWhereCtx(ctx context.Context, t reflect.Type, set map[string]any, clauses string,
+	args ...any) (int64, error) {
+	return updateWhere(ctx, tx.session(), t, set, clauses, args...)
+}
+
+func (tx *Tx) Delete(t reflect.Type, clauses string, args ...any) (int64, error) {
+	return tx.DeleteCtx(context.Background(), t, clauses, args...)
+}
+
+func (tx *Tx) DeleteCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error) {
+	return deleteAll(ctx, tx.session(), t, clauses, args...)
+}
+
+func (tx *Tx) HardDelete(t reflect.Type, clauses string, args ...any) (int64, error) {
+	return tx.Unscoped().Delete(t, clauses, args...)
+}
+
+func (tx *Tx) HardDeleteCtx(ctx context.Context, t reflect.Type, clauses string, args ...any) (int64, error) {
+	return tx.Unscoped().DeleteCtx(ctx, t, clauses, args...)
+}
+
+func (tx *Tx) DeleteQuery(t reflect.Type, q *Query) (int64, error) {
+	return tx.DeleteQueryCtx(context.Background(), t, q)
+}
+
+func (tx *Tx) DeleteQueryCtx(ctx context.Context, t reflect.Type, q *Query) (int64, error) {
+	clauses, args := q.Build()
+	return deleteAll(ctx, tx.session(), t, clauses, args...)
+}
+
+func (tx *Tx) DeleteWhere(t reflect.Type, condition Condition) (int64, error) {
+	return tx.DeleteWhereCtx(context.Background(), t, condition)
+}
+
+func (tx *Tx) DeleteWhereCtx(ctx context.Context, t reflect.Type, condition Condition) (int64, error) {
+	return tx.DeleteQueryCtx(ctx, t, NewQuery().Filter(condition))
+}
+
+func (tx *Tx) DeleteByIDs(t reflect.Type, ids []int64) (int64, error) {
+	return tx.DeleteByIDsCtx(context.Background(), t, ids)
+}
+
+func (tx *Tx) DeleteByIDsCtx(ctx context.Context, t reflect.Type, ids []int64) (int64, error) {
+	return deleteAll(ctx, tx.session(), t, buildIDsClause(t, tx.namingStrategy()), ids)
+}
+
+func (tx *Tx) DeleteOne(arg any) error {
+	return tx.DeleteOneCtx(context.Background(), arg)
+}
+
+func (tx *Tx) DeleteOneCtx(ctx context.Context, arg any) error {
+	return deleteOne(ctx, tx.session(), arg)
+}
+
+func (tx *Tx) Preload(arg any, relations ...string) error {
+	return tx.PreloadCtx(context.Background(), arg, relations...)
+}
+
+func (tx *Tx) PreloadCtx(ctx context.Context, arg any, relations ...string) error {
+	return preload(ctx, tx.session(), arg, relations...)
+}
+
+// Notify sends a notification on a channel, which is delivered when the transaction is committed.
+func (tx *Tx) Notify(channel string, payload string) error {
+	return tx.NotifyCtx(context.Background(), channel, payload)
+}
+
+func (tx *Tx) NotifyCtx(ctx context.Context, channel string, payload string) error {
+	return notify(ctx, tx.session(), channel, payload)
+}
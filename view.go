@@ -0,0 +1,133 @@
+package liteorm
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// ErrReadOnly is returned by the operations that write rows of a read-only type, i.e. one mapped to a view or a
+// materialized view.
+var ErrReadOnly = errors.New("type is read-only")
+
+// View is implemented by types mapped to a view rather than a table, e.g. denormalized read models. The view is named
+// like a table, e.g. with TableNamer, and CreateTable creates it, or replaces it, with the defining query returned by
+// ViewQuery. Selects work as for tables, while inserts, updates and deletes fail with ErrReadOnly.
+type View interface {
+	ViewQuery() string
+}
+
+var viewType = reflect.TypeOf((*View)(nil)).Elem()
+
+// getViewQuery returns the defining query of types implementing View, with either a value or a pointer receiver.
+func getViewQuery(t reflect.Type) (string, bool) {
+	if t.Implements(viewType) {
+		return reflect.Zero(t).Interface().(View).ViewQuery(), true
+	}
+
+	if reflect.PtrTo(t).Implements(viewType) {
+		return reflect.New(t).Interface().(View).ViewQuery(), true
+	}
+
+	return "", false
+}
+
+// MaterializedView is implemented by types mapped to a materialized view rather than a table, e.g. the read models of
+// dashboards. The view is named like a table, e.g. with TableNamer, CreateTable creates it with the defining query
+// returned by MaterializedViewQuery, and its rows are updated with RefreshMaterializedView. Selects work as for tables,
+// while inserts, updates and deletes fail with ErrReadOnly.
+type MaterializedView interface {
+	MaterializedViewQuery() string
+}
+
+var materializedViewType = reflect.TypeOf((*MaterializedView)(nil)).Elem()
+
+// getMaterializedViewQuery returns the defining query of types implementing MaterializedView, with either a value or a
+// pointer receiver.
+func getMaterializedViewQuery(t reflect.Type) (string, bool) {
+	if t.Implements(materializedViewType) {
+		return reflect.Zero(t).Interface().(MaterializedView).MaterializedViewQuery(), true
+	}
+
+	if reflect.PtrTo(t).Implements(materializedViewType) {
+		return reflect.New(t).Interface().(MaterializedView).MaterializedViewQuery(), true
+	}
+
+	return "", false
+}
+
+// checkWritable returns ErrReadOnly for the types whose rows cannot be written, i.e. views and materialized views.
+func checkWritable(t reflect.Type) error {
+	if _, ok := getViewQuery(t); ok {
+		return ErrReadOnly
+	}
+
+	if _, ok := getMaterializedViewQuery(t); ok {
+		return ErrReadOnly
+	}
+
+	return nil
+}
+
+// buildCreateViewStatement builds the statement creating the view of the type received as argument, or replacing it
+// if it already exists.
+func buildCreateViewStatement(t reflect.Type, query string, naming NamingStrategy) string {
+	return fmt.Sprintf("create or replace view %s as %s;", quoteIdentifier(naming.TableName(t)), query)
+}
+
+// buildCreateMaterializedViewStatement builds the statement creating the materialized view of the type received as
+// argument, unless it already exists.
+func buildCreateMaterializedViewStatement(t reflect.Type, query string, naming NamingStrategy) string {
+	return fmt.Sprintf("create materialized view if not exists %s as %s;", quoteIdentifier(naming.TableName(t)),
+		query)
+}
+
+// buildRefreshMaterializedViewStatement builds the statement refreshing the materialized view of the type received as
+// argument.
+func buildRefreshMaterializedViewStatement(t reflect.Type, concurrently bool, naming NamingStrategy) string {
+	statement := "refresh materialized view "
+	if concurrently {
+		statement += "concurrently "
+	}
+
+	return statement + quoteIdentifier(naming.TableName(t)) + ";"
+}
+
+// RefreshMaterializedView replaces the rows of the materialized view of the type received as argument with the current
+// result of its defining query. If concurrently is set, selects are not blocked while the view is refreshed, which
+// requires a unique index on the view, e.g. declared with the uniqueIndex tag and created with CreateIndexes.
+func (db *Database) RefreshMaterializedView(t reflect.Type, concurrently bool) error {
+	return db.RefreshMaterializedViewCtx(context.Background(), t, concurrently)
+}
+
+func (db *Database) RefreshMaterializedViewCtx(ctx context.Context, t reflect.Type, concurrently bool) error {
+	return refreshMaterializedView(ctx, db.session(), t, concurrently)
+}
+
+func (tx *Tx) RefreshMaterializedView(t reflect.Type, concurrently bool) error {
+	return tx.RefreshMaterializedViewCtx(context.Background(), t, concurrently)
+}
+
+func (tx *Tx) RefreshMaterializedViewCtx(ctx context.Context, t reflect.Type, concurrently bool) error {
+	return refreshMaterializedView(ctx, tx.session(), t, concurrently)
+}
+
+func refreshMaterializedView(ctx context.Context, s *session, t reflect.Type, concurrently bool) (err error) {
+	errmsg := fmt.Sprintf("could not refresh materialized view %s", s.namingStrategy().TableName(t))
+
+	ctx, span := s.startSpan(ctx, "refresh_materialized_view", s.namingStrategy().TableName(t))
+	defer func() { span.End(err) }()
+
+	if _, ok := getMaterializedViewQuery(t); !ok {
+		return errors.New(fmt.Sprintf("%s - type %s is not a materialized view", errmsg, t.Name()))
+	}
+
+	statement := buildRefreshMaterializedViewStatement(t, concurrently, s.namingStrategy())
+	_, err = s.Exec(ctx, statement)
+	if err != nil {
+		return errors.Wrap(err, errmsg)
+	}
+
+	return nil
+}